@@ -0,0 +1,107 @@
+// Command import-beverages fills the beverage catalog from a JSON file holding an array of
+// items with the fields of a GET /beverages result, without the id:
+//
+//	[{"brand": "Guinness", "name": "Draught", "style": "Irish Stout", "category": "beer",
+//	  "default_abv": 4.2, "serving_sizes_ml": [568, 440], "barcode": "5000213003009"}]
+//
+// Items already in the catalog, with the same barcode or, without a barcode, the same brand
+// and name, are updated, so a file can be imported again after editing it. Every item is
+// validated before any is saved, and they are saved in one transaction.
+//
+// Usage:
+//
+//	go run ./cmd/import-beverages [-dry-run] beverages.json
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"gorm.io/gorm"
+
+	"github.com/starks97/alcohol-tracker-api/config"
+	"github.com/starks97/alcohol-tracker-api/internal/database"
+	"github.com/starks97/alcohol-tracker-api/internal/dtos"
+	"github.com/starks97/alcohol-tracker-api/internal/entities"
+	"github.com/starks97/alcohol-tracker-api/internal/exceptions"
+	"github.com/starks97/alcohol-tracker-api/internal/repositories"
+	"github.com/starks97/alcohol-tracker-api/internal/utils"
+)
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "only validate the file")
+	flag.Parse()
+	if flag.NArg() != 1 {
+		log.Fatal("Usage: import-beverages [-dry-run] beverages.json")
+	}
+
+	file, err := os.Open(flag.Arg(0))
+	if err != nil {
+		log.Fatalf("Failed to open the catalog file: %v", err)
+	}
+	defer file.Close()
+
+	var items []dtos.ImportBeverageDto
+	decoder := json.NewDecoder(file)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&items); err != nil {
+		log.Fatalf("Failed to read the catalog file: %v", err)
+	}
+
+	validator := exceptions.Init()
+	var invalid int
+	for i := range items {
+		if err := utils.ParseValidatorMessage(&items[i], validator); err != nil {
+			log.Printf("Item %d (%s %s) is invalid: %v", i, items[i].Brand, items[i].Name, err)
+			invalid++
+		}
+	}
+	if invalid > 0 {
+		log.Fatalf("%d of %d items are invalid; nothing was imported", invalid, len(items))
+	}
+	if *dryRun {
+		log.Printf("%d items are valid", len(items))
+		return
+	}
+
+	// Only DATABASE_URL is needed.
+	cfg, err := config.LoadDatabaseConfig()
+	if err != nil {
+		log.Fatalf("Error loading env: %v", err)
+	}
+
+	db := database.ConnectDB(cfg)
+
+	var created, updated int
+	err = db.Transaction(func(tx *gorm.DB) error {
+		beverageRepo := repositories.NewBeverageRepository(tx)
+		for _, item := range items {
+			isNew, err := beverageRepo.SaveBeverage(&entities.Beverage{
+				Brand:          item.Brand,
+				Name:           item.Name,
+				Style:          item.Style,
+				Category:       item.Category,
+				DefaultABV:     item.DefaultABV,
+				ServingSizesMl: item.ServingSizesMl,
+				Barcode:        item.Barcode,
+			})
+			if err != nil {
+				log.Printf("Failed to save %s %s: %v", item.Brand, item.Name, err)
+				return err
+			}
+			if isNew {
+				created++
+			} else {
+				updated++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Fatalf("Import failed; nothing was imported: %v", err)
+	}
+
+	log.Printf("Imported %d beverages: %d added, %d updated", len(items), created, updated)
+}
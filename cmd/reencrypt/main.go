@@ -0,0 +1,129 @@
+// Command reencrypt re-encrypts the provider tokens and TOTP secrets stored under a previous
+// encryption key with the active one, TOKEN_ENCRYPTION_KEY_ID. Run it after rotating keys: add the new key
+// to TOKEN_ENCRYPTION_KEYS and make it active, deploy, run reencrypt, then remove the old
+// key once it reports nothing left to re-encrypt.
+//
+// Usage:
+//
+//	go run ./cmd/reencrypt [-batch 500] [-dry-run]
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/starks97/alcohol-tracker-api/config"
+	"github.com/starks97/alcohol-tracker-api/internal/database"
+	"github.com/starks97/alcohol-tracker-api/internal/utils"
+)
+
+// encryptedRow is read without the serializer, to see which key each value is under.
+type encryptedRow struct {
+	ID    uuid.UUID
+	Value string
+}
+
+// encryptedColumn is a column written by the "encrypted" serializer.
+type encryptedColumn struct {
+	Table  string
+	Column string
+	Name   string // What the values are, for the log.
+}
+
+var encryptedColumns = []encryptedColumn{
+	{Table: "user_identities", Column: "refresh_token", Name: "provider tokens"},
+	{Table: "users", Column: "totp_secret", Name: "TOTP secrets"},
+}
+
+func main() {
+	batchSize := flag.Int("batch", 500, "number of rows re-encrypted per query")
+	dryRun := flag.Bool("dry-run", false, "only count the values to re-encrypt")
+	flag.Parse()
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Error loading env: %v", err)
+	}
+
+	keyRing, err := utils.NewKeyRing(cfg.TokenEncryptionKeys, cfg.TokenEncryptionKeyID)
+	if err != nil {
+		log.Fatalf("Error initializing encryption keys: %v", err)
+	}
+	utils.SetEncryptionKeyRing(keyRing)
+
+	db := database.ConnectDB(cfg)
+	activeID := keyRing.ActiveKeyID()
+
+	var failedAny bool
+	for _, column := range encryptedColumns {
+		reencrypted, failed := reencryptColumn(db, keyRing, column, *batchSize, *dryRun)
+		if *dryRun {
+			log.Printf("%d %s to re-encrypt with key %q, %d undecryptable", reencrypted, column.Name, activeID, failed)
+		} else {
+			log.Printf("Re-encrypted %d %s with key %q, %d undecryptable", reencrypted, column.Name, activeID, failed)
+		}
+		failedAny = failedAny || failed > 0
+	}
+	if failedAny {
+		log.Fatal("Some values could not be decrypted; keep their keys in TOKEN_ENCRYPTION_KEYS")
+	}
+}
+
+// reencryptColumn re-encrypts the values of the column that are not under the active key.
+// It returns how many were re-encrypted, or would be on a dry run, and how many could not
+// be decrypted.
+func reencryptColumn(db *gorm.DB, keyRing *utils.KeyRing, column encryptedColumn, batchSize int, dryRun bool) (int, int) {
+	activeID := keyRing.ActiveKeyID()
+
+	var reencrypted, failed int
+	lastID := uuid.Nil
+	for {
+		var rows []encryptedRow
+		err := db.Raw(fmt.Sprintf(`SELECT id, %[2]s AS value FROM %[1]s
+			WHERE %[2]s IS NOT NULL AND id > ? ORDER BY id LIMIT ?`, column.Table, column.Column), lastID, batchSize).
+			Scan(&rows).Error
+		if err != nil {
+			log.Fatalf("Failed to read %s: %v", column.Name, err)
+		}
+		if len(rows) == 0 {
+			break
+		}
+		lastID = rows[len(rows)-1].ID
+
+		for _, row := range rows {
+			keyID, err := keyRing.KeyID(row.Value)
+			if err == nil && keyID == activeID {
+				continue
+			}
+
+			plaintext, err := keyRing.Decrypt(row.Value)
+			if err != nil {
+				log.Printf("Failed to decrypt %s row %s: %v", column.Table, row.ID, err)
+				failed++
+				continue
+			}
+			if dryRun {
+				reencrypted++
+				continue
+			}
+
+			value, err := keyRing.Encrypt(plaintext)
+			if err != nil {
+				log.Fatalf("Failed to encrypt: %v", err)
+			}
+			// The old value is matched too, so a value replaced meanwhile, such as by a login
+			// or a 2FA enrollment, is kept.
+			err = db.Exec(fmt.Sprintf(`UPDATE %[1]s SET %[2]s = ? WHERE id = ? AND %[2]s = ?`, column.Table, column.Column),
+				value, row.ID, row.Value).Error
+			if err != nil {
+				log.Fatalf("Failed to store %s row %s: %v", column.Table, row.ID, err)
+			}
+			reencrypted++
+		}
+	}
+	return reencrypted, failed
+}
@@ -0,0 +1,111 @@
+// Command rotate-keys rotates the keys signing access or refresh tokens.
+//
+// The new key is published in the JWKS at once and starts signing tokens after the publish
+// delay, when every instance and JWKS client knows it. The previous key then stops signing,
+// but keeps verifying tokens for the overlap window, which must outlast the tokens it
+// signed. Expired keys are deleted with -prune.
+//
+// Usage:
+//
+//	go run ./cmd/rotate-keys [-type access|refresh] [-publish-delay 10m] [-overlap 20m] [-prune]
+package main
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"flag"
+	"log"
+	"time"
+
+	"github.com/starks97/alcohol-tracker-api/config"
+	"github.com/starks97/alcohol-tracker-api/internal/database"
+	"github.com/starks97/alcohol-tracker-api/internal/entities"
+	"github.com/starks97/alcohol-tracker-api/internal/repositories"
+	"github.com/starks97/alcohol-tracker-api/internal/services"
+	"github.com/starks97/alcohol-tracker-api/internal/state"
+	"github.com/starks97/alcohol-tracker-api/internal/utils"
+)
+
+const keyBits = 2048
+
+func main() {
+	tokenType := flag.String("type", services.TokenTypeAccess, `type of token whose key is rotated, "access" or "refresh"`)
+	publishDelay := flag.Duration("publish-delay", 10*time.Minute, "how long the new key is published before it signs tokens")
+	overlap := flag.Duration("overlap", 0, "how long the previous key verifies tokens after it stops signing; defaults to the token lifetime plus a minute")
+	prune := flag.Bool("prune", false, "only delete the expired keys")
+	flag.Parse()
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Error loading env: %v", err)
+	}
+
+	keyRing, err := utils.NewKeyRing(cfg.TokenEncryptionKeys, cfg.TokenEncryptionKeyID)
+	if err != nil {
+		log.Fatalf("Error initializing encryption keys: %v", err)
+	}
+	utils.SetEncryptionKeyRing(keyRing)
+
+	appState := &state.AppState{DB: database.ConnectDB(cfg), Config: cfg}
+	repo := repositories.NewSigningKeyRepository(appState.DB)
+
+	if *prune {
+		deleted, err := repo.DeleteExpiredSigningKeys(time.Now())
+		if err != nil {
+			log.Fatalf("Failed to delete expired keys: %v", err)
+		}
+		log.Printf("Deleted %d expired signing keys", deleted)
+		return
+	}
+
+	var tokenLifetime time.Duration
+	switch *tokenType {
+	case services.TokenTypeAccess:
+		tokenLifetime = time.Duration(cfg.AccessTokenMaxAge) * time.Minute
+	case services.TokenTypeRefresh:
+		tokenLifetime = time.Duration(cfg.RefreshTokenMaxAge) * time.Minute
+	default:
+		log.Fatalf(`Invalid -type %q: must be "access" or "refresh"`, *tokenType)
+	}
+	if *overlap == 0 {
+		*overlap = tokenLifetime + time.Minute
+	}
+	if *overlap < tokenLifetime {
+		log.Fatalf("Invalid -overlap %s: tokens of the previous key live %s", *overlap, tokenLifetime)
+	}
+	if *publishDelay < utils.MinKeyPublishDelay {
+		log.Fatalf("Invalid -publish-delay %s: must be at least %s for every instance and JWKS client to see the key", *publishDelay, utils.MinKeyPublishDelay)
+	}
+
+	// The keys are read once first, which stores the configured key if none is yet, so that
+	// it is retired like any other.
+	current, err := utils.CurrentSigningKey(appState, *tokenType, time.Now())
+	if err != nil {
+		log.Fatalf("Failed to read the current signing key: %v", err)
+	}
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, keyBits)
+	if err != nil {
+		log.Fatalf("Failed to generate key: %v", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		log.Fatalf("Failed to encode key: %v", err)
+	}
+
+	next := &entities.SigningKey{
+		ID:          services.KeyThumbprint(&privateKey.PublicKey),
+		Purpose:     *tokenType,
+		PrivateKey:  base64.StdEncoding.EncodeToString(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		ActivatesAt: time.Now().Add(*publishDelay),
+	}
+	if err := repo.RotateSigningKey(next, *overlap); err != nil {
+		log.Fatalf("Failed to store key: %v", err)
+	}
+
+	log.Printf("Key %s signs %s tokens from %s", next.ID, *tokenType, next.ActivatesAt.Format(time.RFC3339))
+	log.Printf("Key %s stops signing then and expires at %s", current.ID, next.ActivatesAt.Add(*overlap).Format(time.RFC3339))
+}
@@ -5,12 +5,15 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"time"
 	"unicode"
 
 	"github.com/joho/godotenv"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/endpoints"
 	"golang.org/x/oauth2/google"
+
+	"github.com/starks97/alcohol-tracker-api/internal/units"
 )
 
 type Config struct {
@@ -23,15 +26,39 @@ type Config struct {
 	GithubLoginConfig      oauth2.Config
 	GithubClientID         string
 	GithubClientSecret     string
+	AppleLoginConfig       oauth2.Config // Sign in with Apple; disabled when its ClientID is empty.
+	AppleTeamID            string
+	AppleKeyID             string
+	ApplePrivateKey        string        // Base64-encoded .p8 key signing the Apple client secrets.
+	MicrosoftLoginConfig   oauth2.Config // Disabled when its ClientID is empty.
+	DiscordLoginConfig     oauth2.Config // Disabled when its ClientID is empty.
 	RedisURL               string
-	AccessTokenPrivateKey  string
-	AccessTokenPublicKey   string
+	AccessTokenPrivateKey  string // Signs access tokens until the first key rotation; public keys are derived.
 	AccessTokenExpiredIn   string
 	AccessTokenMaxAge      int64
 	RefreshTokenPrivateKey string
-	RefreshTokenPublicKey  string
 	RefreshTokenMaxAge     int64
 	RefreshTokenExpiredIn  string
+	BacEliminationRate     float64
+	DefaultDrinkLocale     string
+	SessionGap             time.Duration // Drinks further apart than this start a new drinking session.
+	TotpIssuer             string        // Service name shown in authenticator apps.
+	TokenSigningSecret     string        // HMAC key signing the tokens sent by email.
+	MailDriver             string        // "smtp", or "log" to print emails instead of sending them.
+	MailFrom               string
+	MailLogDir             string // Directory the log driver also writes .eml files to, if set.
+	SmtpHost               string
+	SmtpPort               int
+	SmtpUsername           string
+	SmtpPassword           string
+	RateLimits             map[string]RateLimitPolicy    // Policies of the rate limited route groups, by name.
+	RateLimitFailOpen      bool                          // Whether requests go through when the limiter's Redis is down.
+	OIDCProviders          map[string]OIDCProviderConfig // OpenID Connect providers, by name.
+	TokenEncryptionKeys    map[string][]byte             // Keys encrypting provider tokens at rest, by key ID.
+	TokenEncryptionKeyID   string                        // The key new values are encrypted with.
+	TokenIssuer            string                        // The iss claim of the tokens issued, required on verification.
+	TokenAudience          string                        // The aud claim of the tokens issued, required on verification.
+	TokenScopes            []string                      // Scopes granted to new tokens; tokens with others are rejected.
 }
 
 func LoadConfig() (*Config, error) {
@@ -49,6 +76,62 @@ func LoadConfig() (*Config, error) {
 		return nil, fmt.Errorf("invalid REFRESH_TOKEN_MAXAGE: %v", err)
 	}
 
+	bacEliminationRate, err := strconv.ParseFloat(getEnvOrDefault("BAC_ELIMINATION_RATE", "0.015"), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid BAC_ELIMINATION_RATE: %v", err)
+	}
+	if bacEliminationRate <= 0 {
+		return nil, fmt.Errorf("invalid BAC_ELIMINATION_RATE: must be greater than zero")
+	}
+
+	defaultDrinkLocale := getEnvOrDefault("DEFAULT_DRINK_LOCALE", units.DefaultDrinkLocale)
+	if !units.IsSupportedLocale(defaultDrinkLocale) {
+		return nil, fmt.Errorf("invalid DEFAULT_DRINK_LOCALE: %q is not one of %v", defaultDrinkLocale, units.SupportedLocales())
+	}
+
+	sessionGapMinutes, err := strconv.Atoi(getEnvOrDefault("SESSION_GAP_MINUTES", "180"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SESSION_GAP_MINUTES: %v", err)
+	}
+	if sessionGapMinutes <= 0 {
+		return nil, fmt.Errorf("invalid SESSION_GAP_MINUTES: must be greater than zero")
+	}
+
+	smtpPort, err := strconv.Atoi(getEnvOrDefault("SMTP_PORT", "587"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SMTP_PORT: %v", err)
+	}
+
+	mailDriver := getEnvOrDefault("MAIL_DRIVER", "log")
+	if mailDriver == "smtp" && os.Getenv("SMTP_HOST") == "" {
+		return nil, fmt.Errorf("invalid MAIL_DRIVER: SMTP_HOST must be set to use smtp")
+	}
+
+	rateLimits, err := loadRateLimits()
+	if err != nil {
+		return nil, err
+	}
+
+	rateLimitFailOpen, err := loadRateLimitFailOpen()
+	if err != nil {
+		return nil, err
+	}
+
+	oidcProviders, err := loadOIDCProviders()
+	if err != nil {
+		return nil, err
+	}
+
+	tokenEncryptionKeys, tokenEncryptionKeyID, err := loadTokenEncryptionKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	tokenIssuer, tokenAudience, tokenScopes, err := loadTokenClaims()
+	if err != nil {
+		return nil, err
+	}
+
 	config := &Config{
 		DatabaseUrl:            getEnv("DATABASE_URL"),
 		ClientOrigin:           getEnv("CLIENT_ORIGIN"),
@@ -59,13 +142,31 @@ func LoadConfig() (*Config, error) {
 		GithubClientSecret:     getEnv("GITHUB_CLIENT_SECRET"),
 		RedisURL:               getEnv("REDIS_URL"),
 		AccessTokenPrivateKey:  getEnv("ACCESS_TOKEN_PRIVATE_KEY"),
-		AccessTokenPublicKey:   getEnv("ACCESS_TOKEN_PUBLIC_KEY"),
 		AccessTokenExpiredIn:   getEnv("ACCESS_TOKEN_EXPIRED_IN"),
 		AccessTokenMaxAge:      accessTokenMaxAge,
 		RefreshTokenPrivateKey: getEnv("REFRESH_TOKEN_PRIVATE_KEY"),
-		RefreshTokenPublicKey:  getEnv("REFRESH_TOKEN_PUBLIC_KEY"),
 		RefreshTokenMaxAge:     refreshTokenMaxAge,
 		RefreshTokenExpiredIn:  getEnv("REFRESH_TOKEN_EXPIRED_IN"),
+		BacEliminationRate:     bacEliminationRate,
+		DefaultDrinkLocale:     defaultDrinkLocale,
+		SessionGap:             time.Duration(sessionGapMinutes) * time.Minute,
+		TotpIssuer:             getEnvOrDefault("TOTP_ISSUER", "Alcohol Tracker"),
+		TokenSigningSecret:     getEnv("TOKEN_SIGNING_SECRET"),
+		MailDriver:             mailDriver,
+		MailFrom:               getEnvOrDefault("MAIL_FROM", "no-reply@localhost"),
+		MailLogDir:             os.Getenv("MAIL_LOG_DIR"),
+		SmtpHost:               os.Getenv("SMTP_HOST"),
+		SmtpPort:               smtpPort,
+		SmtpUsername:           os.Getenv("SMTP_USERNAME"),
+		SmtpPassword:           os.Getenv("SMTP_PASSWORD"),
+		RateLimits:             rateLimits,
+		RateLimitFailOpen:      rateLimitFailOpen,
+		OIDCProviders:          oidcProviders,
+		TokenEncryptionKeys:    tokenEncryptionKeys,
+		TokenEncryptionKeyID:   tokenEncryptionKeyID,
+		TokenIssuer:            tokenIssuer,
+		TokenAudience:          tokenAudience,
+		TokenScopes:            tokenScopes,
 	}
 
 	// Initialize OAuth2 configuration
@@ -87,14 +188,28 @@ func LoadConfig() (*Config, error) {
 		Scopes: []string{
 			"read:user",
 			"user:email",
-			"repo",
 		},
 		Endpoint: endpoints.GitHub,
 	}
 
+	for _, load := range []func(*Config) error{loadAppleLoginConfig, loadMicrosoftLoginConfig, loadDiscordLoginConfig} {
+		if err := load(config); err != nil {
+			return nil, err
+		}
+	}
+
 	return config, nil
 }
 
+// LoadDatabaseConfig loads only what database.ConnectDB needs, for tools that do not run
+// the API and should not require its secrets.
+func LoadDatabaseConfig() (*Config, error) {
+	if err := godotenv.Load(); err != nil {
+		log.Println("Warning: No .env file found, using system environment variables")
+	}
+	return &Config{DatabaseUrl: getEnv("DATABASE_URL")}, nil
+}
+
 func getEnv(key string) string {
 	value := os.Getenv(key)
 	if value == "" {
@@ -104,6 +219,15 @@ func getEnv(key string) string {
 	return value
 }
 
+// getEnvOrDefault returns the value of an optional environment variable, or fallback when it is unset.
+func getEnvOrDefault(key string, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+
+	return fallback
+}
+
 func parseDuration(durationStr string) (int64, error) {
 	var numericPart string
 
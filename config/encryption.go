@@ -0,0 +1,42 @@
+package config
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// loadTokenEncryptionKeys reads the keys encrypting provider tokens at rest from
+// TOKEN_ENCRYPTION_KEYS, a comma-separated list of <key ID>:<base64-encoded 32-byte key>.
+// New values are encrypted with TOKEN_ENCRYPTION_KEY_ID, by default the first key listed;
+// the other keys only decrypt values encrypted before a rotation.
+func loadTokenEncryptionKeys() (map[string][]byte, string, error) {
+	keys := make(map[string][]byte)
+	var firstID string
+
+	for _, entry := range strings.Split(getEnv("TOKEN_ENCRYPTION_KEYS"), ",") {
+		id, encoded, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if !ok || id == "" {
+			return nil, "", fmt.Errorf("invalid TOKEN_ENCRYPTION_KEYS: entries must be <key ID>:<base64 key>")
+		}
+		if _, duplicate := keys[id]; duplicate {
+			return nil, "", fmt.Errorf("invalid TOKEN_ENCRYPTION_KEYS: key ID %q is listed twice", id)
+		}
+
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(key) != 32 {
+			return nil, "", fmt.Errorf("invalid TOKEN_ENCRYPTION_KEYS: key %q must be 32 bytes, base64-encoded", id)
+		}
+
+		keys[id] = key
+		if firstID == "" {
+			firstID = id
+		}
+	}
+
+	activeID := getEnvOrDefault("TOKEN_ENCRYPTION_KEY_ID", firstID)
+	if _, ok := keys[activeID]; !ok {
+		return nil, "", fmt.Errorf("invalid TOKEN_ENCRYPTION_KEY_ID: %q is not in TOKEN_ENCRYPTION_KEYS", activeID)
+	}
+	return keys, activeID, nil
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/endpoints"
+)
+
+// AppleEndpoint is the endpoint of Sign in with Apple. The client secret is a JWT, sent in
+// the request body.
+var AppleEndpoint = oauth2.Endpoint{
+	AuthURL:   "https://appleid.apple.com/auth/authorize",
+	TokenURL:  "https://appleid.apple.com/auth/token",
+	AuthStyle: oauth2.AuthStyleInParams,
+}
+
+// The optional OAuth providers below are enabled by setting their client ID. A provider
+// whose client ID is empty is not offered.
+
+// loadAppleLoginConfig reads Sign in with Apple from APPLE_CLIENT_ID (the Services ID),
+// APPLE_TEAM_ID, APPLE_KEY_ID and APPLE_PRIVATE_KEY, the base64-encoded .p8 key the client
+// secrets are signed with. The client secret is left empty because it is generated for
+// each code exchange.
+func loadAppleLoginConfig(config *Config) error {
+	config.AppleLoginConfig = oauth2.Config{
+		ClientID:    os.Getenv("APPLE_CLIENT_ID"),
+		RedirectURL: getEnvOrDefault("APPLE_REDIRECT_URL", "http://localhost:8080/auth/apple/callback"),
+		Scopes:      []string{"name", "email"},
+		Endpoint:    AppleEndpoint,
+	}
+	if config.AppleLoginConfig.ClientID == "" {
+		return nil
+	}
+
+	config.AppleTeamID = getEnv("APPLE_TEAM_ID")
+	config.AppleKeyID = getEnv("APPLE_KEY_ID")
+	config.ApplePrivateKey = getEnv("APPLE_PRIVATE_KEY")
+	if !strings.HasPrefix(config.AppleLoginConfig.RedirectURL, "https://") && !strings.HasPrefix(config.AppleLoginConfig.RedirectURL, "http://localhost") {
+		return fmt.Errorf("invalid APPLE_REDIRECT_URL: Apple requires an https URL")
+	}
+	return nil
+}
+
+// loadMicrosoftLoginConfig reads Microsoft (Entra ID) login from MICROSOFT_CLIENT_ID and
+// MICROSOFT_CLIENT_SECRET. MICROSOFT_TENANT restricts logins to one directory; the default
+// "common" accepts work, school and personal accounts.
+func loadMicrosoftLoginConfig(config *Config) error {
+	config.MicrosoftLoginConfig = oauth2.Config{
+		ClientID:    os.Getenv("MICROSOFT_CLIENT_ID"),
+		RedirectURL: getEnvOrDefault("MICROSOFT_REDIRECT_URL", "http://localhost:8080/auth/microsoft/callback"),
+		Scopes:      []string{"openid", "email", "profile", "User.Read"},
+		Endpoint:    endpoints.AzureAD(getEnvOrDefault("MICROSOFT_TENANT", "common")),
+	}
+	if config.MicrosoftLoginConfig.ClientID == "" {
+		return nil
+	}
+
+	config.MicrosoftLoginConfig.ClientSecret = getEnv("MICROSOFT_CLIENT_SECRET")
+	return nil
+}
+
+// loadDiscordLoginConfig reads Discord login from DISCORD_CLIENT_ID and
+// DISCORD_CLIENT_SECRET.
+func loadDiscordLoginConfig(config *Config) error {
+	config.DiscordLoginConfig = oauth2.Config{
+		ClientID:    os.Getenv("DISCORD_CLIENT_ID"),
+		RedirectURL: getEnvOrDefault("DISCORD_REDIRECT_URL", "http://localhost:8080/auth/discord/callback"),
+		Scopes:      []string{"identify", "email"},
+		Endpoint:    endpoints.Discord,
+	}
+	if config.DiscordLoginConfig.ClientID == "" {
+		return nil
+	}
+
+	config.DiscordLoginConfig.ClientSecret = getEnv("DISCORD_CLIENT_SECRET")
+	return nil
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// OIDCProviderConfig declares an OpenID Connect provider, such as a self-hosted Keycloak or
+// Authentik instance. Its endpoints are discovered from the issuer.
+type OIDCProviderConfig struct {
+	Name         string // Used in the /auth/<name> routes.
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+var oidcProviderNamePattern = regexp.MustCompile(`^[a-z][a-z0-9-]{0,31}$`)
+
+// reservedProviderNames are taken by built-in strategies or by other /auth routes.
+var reservedProviderNames = map[string]bool{
+	"github": true, "google": true, "apple": true, "microsoft": true, "discord": true, "refresh": true, "sessions": true, "logout": true, "logout-all": true,
+	"login": true, "register": true, "password": true, "verify-email": true, "identities": true, "2fa": true,
+}
+
+// loadOIDCProviders reads the providers named in OIDC_PROVIDERS, a comma-separated list.
+// Each provider <name> is configured by OIDC_<NAME>_ISSUER, OIDC_<NAME>_CLIENT_ID and
+// OIDC_<NAME>_CLIENT_SECRET, and optionally OIDC_<NAME>_SCOPES (space-separated) and
+// OIDC_<NAME>_REDIRECT_URL. Dashes in names become underscores in the variable names.
+func loadOIDCProviders() (map[string]OIDCProviderConfig, error) {
+	providers := make(map[string]OIDCProviderConfig)
+
+	for _, name := range strings.Split(os.Getenv("OIDC_PROVIDERS"), ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !oidcProviderNamePattern.MatchString(name) || reservedProviderNames[name] {
+			return nil, fmt.Errorf("invalid OIDC_PROVIDERS: %q is not an allowed provider name", name)
+		}
+
+		prefix := "OIDC_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+		provider := OIDCProviderConfig{
+			Name:         name,
+			Issuer:       strings.TrimRight(getEnv(prefix+"ISSUER"), "/"),
+			ClientID:     getEnv(prefix + "CLIENT_ID"),
+			ClientSecret: getEnv(prefix + "CLIENT_SECRET"),
+			RedirectURL:  getEnvOrDefault(prefix+"REDIRECT_URL", "http://localhost:8080/auth/"+name+"/callback"),
+			Scopes:       strings.Fields(getEnvOrDefault(prefix+"SCOPES", "openid email profile")),
+		}
+		if !strings.HasPrefix(provider.Issuer, "https://") && !strings.HasPrefix(provider.Issuer, "http://") {
+			return nil, fmt.Errorf("invalid %sISSUER: %q must be an http(s) URL", prefix, provider.Issuer)
+		}
+
+		providers[name] = provider
+	}
+	return providers, nil
+}
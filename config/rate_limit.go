@@ -0,0 +1,89 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Parts a rate limit key can be made of.
+const (
+	RateLimitByIP    = "ip"    // The client IP address.
+	RateLimitByUser  = "user"  // The authenticated user, or the IP address for anonymous requests.
+	RateLimitByRoute = "route" // The request method and path.
+)
+
+// RateLimitPolicy allows Limit requests per Window for each distinct key.
+type RateLimitPolicy struct {
+	Limit  int
+	Window time.Duration
+	KeyBy  []string // Combined into one key, such as ip and route for "per IP, per endpoint".
+}
+
+// defaultRateLimits are the policies of the route groups, overridable with
+// RATE_LIMIT_<NAME> environment variables in the ParseRateLimitPolicy format.
+var defaultRateLimits = map[string]string{
+	"auth":   "20/1m:ip+route",
+	"upload": "10/1m:user",
+	"api":    "300/1m:user",
+}
+
+// ParseRateLimitPolicy parses a policy written as <limit>/<window>[:<key>[+<key>...]],
+// such as "20/1m:ip+route". The window is a Go duration and the key defaults to ip.
+func ParseRateLimitPolicy(spec string) (RateLimitPolicy, error) {
+	rate, keys, _ := strings.Cut(strings.TrimSpace(spec), ":")
+
+	rawLimit, rawWindow, found := strings.Cut(rate, "/")
+	if !found {
+		return RateLimitPolicy{}, fmt.Errorf("%q is not in the format <limit>/<window>[:<key>]", spec)
+	}
+
+	limit, err := strconv.Atoi(rawLimit)
+	if err != nil || limit <= 0 {
+		return RateLimitPolicy{}, fmt.Errorf("limit %q must be a positive integer", rawLimit)
+	}
+
+	window, err := time.ParseDuration(rawWindow)
+	if err != nil || window < time.Second {
+		return RateLimitPolicy{}, fmt.Errorf("window %q must be a duration of at least 1s", rawWindow)
+	}
+
+	policy := RateLimitPolicy{Limit: limit, Window: window, KeyBy: []string{RateLimitByIP}}
+	if keys != "" {
+		policy.KeyBy = strings.Split(keys, "+")
+		for _, key := range policy.KeyBy {
+			if key != RateLimitByIP && key != RateLimitByUser && key != RateLimitByRoute {
+				return RateLimitPolicy{}, fmt.Errorf("key %q must be one of %s, %s or %s", key, RateLimitByIP, RateLimitByUser, RateLimitByRoute)
+			}
+		}
+	}
+	return policy, nil
+}
+
+// loadRateLimits reads the policy of every route group.
+func loadRateLimits() (map[string]RateLimitPolicy, error) {
+	policies := make(map[string]RateLimitPolicy, len(defaultRateLimits))
+	for name, fallback := range defaultRateLimits {
+		key := "RATE_LIMIT_" + strings.ToUpper(name)
+
+		policy, err := ParseRateLimitPolicy(getEnvOrDefault(key, fallback))
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %v", key, err)
+		}
+		policies[name] = policy
+	}
+	return policies, nil
+}
+
+// loadRateLimitFailOpen reads whether requests are let through when Redis is unavailable.
+func loadRateLimitFailOpen() (bool, error) {
+	switch mode := getEnvOrDefault("RATE_LIMIT_FAIL_MODE", "open"); mode {
+	case "open":
+		return true, nil
+	case "closed":
+		return false, nil
+	default:
+		return false, fmt.Errorf("invalid RATE_LIMIT_FAIL_MODE: %q must be open or closed", mode)
+	}
+}
@@ -0,0 +1,30 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultTokenScopes are the scopes access tokens grant unless TOKEN_SCOPES says
+// otherwise, one for each route group that requires a scope.
+var DefaultTokenScopes = []string{"drinks", "sessions", "beverages", "profile", "stats", "upload"}
+
+// loadTokenClaims reads the issuer and audience set in the tokens issued and checked on
+// verification, and the space-separated scopes new tokens grant.
+func loadTokenClaims() (string, string, []string, error) {
+	issuer := strings.TrimSpace(getEnvOrDefault("TOKEN_ISSUER", "alcohol-tracker-api"))
+	if issuer == "" {
+		return "", "", nil, fmt.Errorf("invalid TOKEN_ISSUER: must not be empty")
+	}
+
+	audience := strings.TrimSpace(getEnvOrDefault("TOKEN_AUDIENCE", "alcohol-tracker-app"))
+	if audience == "" {
+		return "", "", nil, fmt.Errorf("invalid TOKEN_AUDIENCE: must not be empty")
+	}
+
+	scopes := strings.Fields(getEnvOrDefault("TOKEN_SCOPES", strings.Join(DefaultTokenScopes, " ")))
+	if len(scopes) == 0 {
+		return "", "", nil, fmt.Errorf("invalid TOKEN_SCOPES: at least one scope must be granted")
+	}
+	return issuer, audience, scopes, nil
+}
@@ -0,0 +1,83 @@
+package database
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/google/uuid"
+	"github.com/starks97/alcohol-tracker-api/internal/entities"
+	"gorm.io/gorm"
+)
+
+// MigrateData moves data stored by earlier versions to its current shape. It is run by the
+// API on start, after ConnectDB, and needs the encryption key ring to be set; tools that
+// only read or write other tables can skip it.
+//
+// Parameters:
+//   - db: *gorm.DB - A connection whose schema ConnectDB has migrated.
+func MigrateData(db *gorm.DB) {
+	// Accounts created through OAuth before identities existed kept their only provider on
+	// the user row; give each of them the matching identity. The legacy columns are cleared
+	// as they are copied, so that an identity unlinked later is not restored by the next
+	// start.
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`INSERT INTO user_identities (user_id, provider, provider_user_id, email, linked_at)
+			SELECT id, provider, provider_id, email, created_at FROM users
+			WHERE provider IS NOT NULL AND provider_id IS NOT NULL
+			ON CONFLICT DO NOTHING`).Error
+		if err != nil {
+			return err
+		}
+		return tx.Exec(`UPDATE users SET provider = NULL, provider_id = NULL
+			WHERE provider IS NOT NULL OR provider_id IS NOT NULL`).Error
+	})
+	if err != nil {
+		log.Fatalf("Migration failed: %v", err)
+	}
+
+	// Provider refresh tokens used to be kept on the user, for whichever provider they last
+	// logged in with. Move each to the user's identity when they have only one, so there is
+	// no doubt which provider issued it, and drop the column. Values not starting with
+	// "enc:" are plaintext access tokens from before encryption and are of no use.
+	if db.Migrator().HasColumn(&entities.User{}, "provider_refresh_token") {
+		err = db.Transaction(func(tx *gorm.DB) error {
+			err := tx.Exec(`UPDATE user_identities SET refresh_token = users.provider_refresh_token
+				FROM users
+				WHERE users.id = user_identities.user_id
+					AND user_identities.refresh_token IS NULL
+					AND users.provider_refresh_token LIKE 'enc:%'
+					AND (SELECT count(*) FROM user_identities other WHERE other.user_id = users.id) = 1`).Error
+			if err != nil {
+				return err
+			}
+			return tx.Migrator().DropColumn(&entities.User{}, "provider_refresh_token")
+		})
+		if err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+	}
+
+	// TOTP secrets used to be stored in plaintext. Encrypt those left, matching the old value
+	// so that a secret replaced meanwhile by an enrollment is kept.
+	var plaintextSecrets []struct {
+		ID         uuid.UUID
+		TotpSecret string
+	}
+	err = db.Raw(`SELECT id, totp_secret FROM users WHERE totp_secret IS NOT NULL AND totp_secret NOT LIKE 'enc:%'`).
+		Scan(&plaintextSecrets).Error
+	if err != nil {
+		log.Fatalf("Migration failed: %v", err)
+	}
+	for _, row := range plaintextSecrets {
+		secret := row.TotpSecret
+		err = db.Model(&entities.User{}).
+			Where("id = ? AND totp_secret = ?", row.ID, row.TotpSecret).
+			Select("totp_secret").
+			Updates(&entities.User{TotpSecret: &secret}).Error
+		if err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+	}
+
+	fmt.Println("✅ Data migrated successfully")
+}
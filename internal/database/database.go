@@ -15,7 +15,8 @@ import (
 var DB *gorm.DB
 
 // ConnectDB establishes a connection to the PostgreSQL database using the provided configuration.
-// It also performs automatic database migrations using GORM.
+// It also performs automatic database migrations using GORM. Data left in older shapes is
+// migrated separately, by MigrateData.
 //
 // Parameters:
 //   - cfg: *config.Config - The application configuration containing database connection details.
@@ -33,8 +34,46 @@ func ConnectDB(cfg *config.Config) *gorm.DB {
 	// Log a successful database connection message.
 	fmt.Println("✅ Database connected successfully")
 
-	// Perform automatic database migrations for the User model.
-	err = db.AutoMigrate(&entities.User{})
+	// Perform automatic database migrations for the application models.
+	err = db.AutoMigrate(&entities.User{}, &entities.Beverage{}, &entities.DrinkingSession{}, &entities.DrinkEntry{}, &entities.DrinkingLimit{}, &entities.RecoveryCode{}, &entities.UserIdentity{}, &entities.SigningKey{})
+	if err != nil {
+		log.Fatalf("Migration failed: %v", err)
+	}
+
+	// Signing keys used to be identified by their ID alone, so a key configured for both
+	// access and refresh tokens was only stored for the first purpose loaded. AutoMigrate
+	// does not change primary keys.
+	var signingKeyPrimaryColumns int64
+	err = db.Raw(`SELECT count(*) FROM information_schema.key_column_usage
+		WHERE table_schema = current_schema() AND table_name = 'signing_keys' AND constraint_name = 'signing_keys_pkey'`).
+		Scan(&signingKeyPrimaryColumns).Error
+	if err == nil && signingKeyPrimaryColumns == 1 {
+		err = db.Exec("ALTER TABLE signing_keys DROP CONSTRAINT signing_keys_pkey, ADD PRIMARY KEY (id, purpose)").Error
+	}
+	if err != nil {
+		log.Fatalf("Migration failed: %v", err)
+	}
+
+	// Full-text search index over the beverage catalog, used by BeverageRepository.SearchBeverages.
+	err = db.Exec("CREATE INDEX IF NOT EXISTS idx_beverages_search ON beverages USING GIN (to_tsvector('simple', brand || ' ' || name || ' ' || coalesce(style, '')))").Error
+	if err != nil {
+		log.Fatalf("Migration failed: %v", err)
+	}
+
+	// At most one manual session per user may be running. End all but the latest of any
+	// that were started concurrently before the index existed, when the next one started.
+	err = db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`UPDATE drinking_sessions SET ended_at = running.next_started_at
+			FROM (
+				SELECT id, lead(started_at) OVER (PARTITION BY user_id ORDER BY started_at, id) AS next_started_at
+				FROM drinking_sessions WHERE manual AND ended_at IS NULL
+			) running
+			WHERE drinking_sessions.id = running.id AND running.next_started_at IS NOT NULL`).Error
+		if err != nil {
+			return err
+		}
+		return tx.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_drinking_sessions_running ON drinking_sessions (user_id) WHERE manual AND ended_at IS NULL").Error
+	})
 	if err != nil {
 		log.Fatalf("Migration failed: %v", err)
 	}
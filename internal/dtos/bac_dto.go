@@ -0,0 +1,17 @@
+package dtos
+
+import (
+	"github.com/go-playground/validator/v10"
+)
+
+// BacQueryDto holds the query parameters accepted by GET /me/bac.
+// WeightKg and Sex override the values stored on the user's profile.
+type BacQueryDto struct {
+	Hours    int     `query:"hours" validate:"gte=1,lte=24"`
+	WeightKg float64 `query:"weight_kg" validate:"omitempty,gt=0,lte=500"`
+	Sex      string  `query:"sex" validate:"omitempty,oneof=male female"`
+}
+
+func (b *BacQueryDto) Validate(v *validator.Validate) error {
+	return v.Struct(b)
+}
@@ -0,0 +1,21 @@
+package dtos
+
+import (
+	"github.com/go-playground/validator/v10"
+)
+
+// ImportBeverageDto is a catalog item read by cmd/import-beverages. Its fields are those of
+// responses.BeverageResponse, so search results can be imported back.
+type ImportBeverageDto struct {
+	Brand          string    `json:"brand" validate:"required,min=1,max=255"`
+	Name           string    `json:"name" validate:"required,min=1,max=255"`
+	Style          *string   `json:"style,omitempty" validate:"omitempty,max=100"`
+	Category       string    `json:"category" validate:"required,oneof=beer wine spirits cider cocktail other"`
+	DefaultABV     float64   `json:"default_abv" validate:"gte=0,lte=100"`
+	ServingSizesMl []float64 `json:"serving_sizes_ml,omitempty" validate:"omitempty,max=10,dive,gt=0,lte=5000"`
+	Barcode        *string   `json:"barcode,omitempty" validate:"omitempty,numeric,min=8,max=14"`
+}
+
+func (b *ImportBeverageDto) Validate(v *validator.Validate) error {
+	return v.Struct(b)
+}
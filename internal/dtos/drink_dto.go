@@ -0,0 +1,46 @@
+package dtos
+
+import (
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+// CreateDrinkDto describes a new drink entry. When BeverageID references a catalog item,
+// BeverageName, Category, VolumeMl and ABV become optional and default to the catalog values.
+type CreateDrinkDto struct {
+	BeverageID   *uuid.UUID `json:"beverage_id,omitempty"`
+	BeverageName string     `json:"beverage_name" validate:"required_without=BeverageID,omitempty,min=1,max=255"`
+	Category     string     `json:"category" validate:"required_without=BeverageID,omitempty,oneof=beer wine spirits cider cocktail other"`
+	VolumeMl     float64    `json:"volume_ml" validate:"required_without=BeverageID,omitempty,gt=0,lte=5000"`
+	ABV          *float64   `json:"abv" validate:"required_without=BeverageID,omitempty,gte=0,lte=100"`
+	ConsumedAt   *time.Time `json:"consumed_at,omitempty"` // Defaults to the time of the request.
+	Price        *float64   `json:"price,omitempty" validate:"omitempty,gte=0,lte=1000000"`
+	Currency     *string    `json:"currency,omitempty" validate:"required_with=Price,omitempty,iso4217"`
+	Calories     *float64   `json:"calories,omitempty" validate:"omitempty,gte=0,lte=10000"` // Estimated when omitted.
+	Notes        *string    `json:"notes,omitempty" validate:"omitempty,max=1000"`
+	ImageRef     *string    `json:"image_ref,omitempty" validate:"omitempty,max=255"`
+}
+
+type UpdateDrinkDto struct {
+	BeverageID   *uuid.UUID `json:"beverage_id,omitempty"`
+	BeverageName *string    `json:"beverage_name,omitempty" validate:"omitempty,min=1,max=255"`
+	Category     *string    `json:"category,omitempty" validate:"omitempty,oneof=beer wine spirits cider cocktail other"`
+	VolumeMl     *float64   `json:"volume_ml,omitempty" validate:"omitempty,gt=0,lte=5000"`
+	ABV          *float64   `json:"abv,omitempty" validate:"omitempty,gte=0,lte=100"`
+	ConsumedAt   *time.Time `json:"consumed_at,omitempty"`
+	Price        *float64   `json:"price,omitempty" validate:"omitempty,gte=0,lte=1000000"`
+	Currency     *string    `json:"currency,omitempty" validate:"omitempty,iso4217"`
+	Calories     *float64   `json:"calories,omitempty" validate:"omitempty,gte=0,lte=10000"`
+	Notes        *string    `json:"notes,omitempty" validate:"omitempty,max=1000"`
+	ImageRef     *string    `json:"image_ref,omitempty" validate:"omitempty,max=255"`
+}
+
+func (d *CreateDrinkDto) Validate(v *validator.Validate) error {
+	return v.Struct(d)
+}
+
+func (d *UpdateDrinkDto) Validate(v *validator.Validate) error {
+	return v.Struct(d)
+}
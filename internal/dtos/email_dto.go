@@ -0,0 +1,14 @@
+package dtos
+
+import (
+	"github.com/go-playground/validator/v10"
+)
+
+// VerifyEmailDto carries the token of the link sent to the user's email address.
+type VerifyEmailDto struct {
+	Token string `json:"token" validate:"required,max=128"`
+}
+
+func (d *VerifyEmailDto) Validate(v *validator.Validate) error {
+	return v.Struct(d)
+}
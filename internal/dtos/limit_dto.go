@@ -0,0 +1,16 @@
+package dtos
+
+import (
+	"github.com/go-playground/validator/v10"
+)
+
+// SetLimitsDto replaces the user's consumption limits; omitted fields clear that limit.
+type SetLimitsDto struct {
+	DailyStandardDrinks    *float64 `json:"daily_standard_drinks" validate:"omitempty,gte=0,lte=100"`
+	WeeklyStandardDrinks   *float64 `json:"weekly_standard_drinks" validate:"omitempty,gte=0,lte=500"`
+	AlcoholFreeDaysPerWeek *int     `json:"alcohol_free_days_per_week" validate:"omitempty,gte=0,lte=7"`
+}
+
+func (l *SetLimitsDto) Validate(v *validator.Validate) error {
+	return v.Struct(l)
+}
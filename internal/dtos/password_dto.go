@@ -0,0 +1,23 @@
+package dtos
+
+import (
+	"github.com/go-playground/validator/v10"
+)
+
+type ForgotPasswordDto struct {
+	Email string `json:"email" validate:"required,email,max=255"`
+}
+
+// ResetPasswordDto sets a new password with the token of a password reset email.
+type ResetPasswordDto struct {
+	Token    string `json:"token" validate:"required,max=128"`
+	Password string `json:"password" validate:"password"`
+}
+
+func (d *ForgotPasswordDto) Validate(v *validator.Validate) error {
+	return v.Struct(d)
+}
+
+func (d *ResetPasswordDto) Validate(v *validator.Validate) error {
+	return v.Struct(d)
+}
@@ -0,0 +1,22 @@
+package dtos
+
+import (
+	"github.com/go-playground/validator/v10"
+)
+
+type StartSessionDto struct {
+	Location *string `json:"location,omitempty" validate:"omitempty,min=1,max=255"`
+}
+
+func (s *StartSessionDto) Validate(v *validator.Validate) error {
+	return v.Struct(s)
+}
+
+// UpdateSessionDto labels a session, manual or automatic. An empty Location clears it.
+type UpdateSessionDto struct {
+	Location string `json:"location" validate:"max=255"`
+}
+
+func (s *UpdateSessionDto) Validate(v *validator.Validate) error {
+	return v.Struct(s)
+}
@@ -0,0 +1,17 @@
+package dtos
+
+import (
+	"github.com/go-playground/validator/v10"
+)
+
+// ConsumptionStatsQueryDto holds the query parameters accepted by GET /stats/consumption.
+// From and To are RFC 3339 timestamps or YYYY-MM-DD dates in the user's time zone.
+type ConsumptionStatsQueryDto struct {
+	From   string `query:"from"`
+	To     string `query:"to"`
+	Bucket string `query:"bucket" validate:"oneof=day week month"`
+}
+
+func (s *ConsumptionStatsQueryDto) Validate(v *validator.Validate) error {
+	return v.Struct(s)
+}
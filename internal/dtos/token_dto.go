@@ -20,6 +20,9 @@ type TokenDetailsDto struct {
 
 	// ExpiresIn is the Unix timestamp representing the token's expiration time. It's a pointer to allow for optional values.
 	ExpiresIn *int64 `json:"expires_in,omitempty" db:"expires_in"`
+
+	// Scopes are the route groups the token grants access to.
+	Scopes []string `json:"scopes,omitempty" db:"scopes"`
 }
 
 // TokenClaimsDto represents the claims within a JWT token.
@@ -38,6 +41,18 @@ type TokenClaimsDto struct {
 
 	// Nbf is the not-before time of the token as a Unix timestamp.
 	Nbf int64 `json:"nbf" db:"nbf"`
+
+	// Iss is the issuer of the token.
+	Iss string `json:"iss" db:"iss"`
+
+	// Aud is the audience the token is intended for.
+	Aud jwt.ClaimStrings `json:"aud" db:"aud"`
+
+	// Typ is the type of the token, access or refresh.
+	Typ string `json:"typ" db:"typ"`
+
+	// Scope is the space-separated list of scopes the token grants.
+	Scope string `json:"scope" db:"scope"`
 }
 
 // GetExpirationTime returns the expiration time claim (exp) as a jwt.NumericDate.
@@ -73,10 +88,8 @@ func (tc TokenClaimsDto) GetNotBefore() (*jwt.NumericDate, error) {
 }
 
 // GetIssuer returns the issuer claim (iss).
-// In this implementation, the issuer is not part of the claims, so it returns an empty string and nil error.
 func (tc TokenClaimsDto) GetIssuer() (string, error) {
-	// The issuer might not be part of your claims, so we return an empty string or default value
-	return "", nil
+	return tc.Iss, nil
 }
 
 // GetSubject returns the subject claim (sub).
@@ -85,8 +98,6 @@ func (tc TokenClaimsDto) GetSubject() (string, error) {
 }
 
 // GetAudience returns the audience claim (aud) as jwt.ClaimStrings.
-// If the audience is not present, it returns an empty slice and nil error.
 func (tc TokenClaimsDto) GetAudience() (jwt.ClaimStrings, error) {
-	// If you don't have audience in your token, just return an empty slice
-	return jwt.ClaimStrings{}, nil
+	return tc.Aud, nil
 }
@@ -0,0 +1,35 @@
+package dtos
+
+import (
+	"github.com/go-playground/validator/v10"
+)
+
+// EnableTwoFactorDto confirms a TOTP enrollment with a code from the authenticator app.
+type EnableTwoFactorDto struct {
+	Code string `json:"code" validate:"required,numeric,len=6"`
+}
+
+// DisableTwoFactorDto turns 2FA off. Code is a TOTP code or an unused recovery code.
+type DisableTwoFactorDto struct {
+	Password string `json:"password" validate:"required,max=255"`
+	Code     string `json:"code" validate:"required,max=32"`
+}
+
+// VerifyTwoFactorDto completes a login with the challenge returned by the login endpoint.
+// Code is a TOTP code or an unused recovery code.
+type VerifyTwoFactorDto struct {
+	ChallengeToken string `json:"challenge_token" validate:"required,max=64"`
+	Code           string `json:"code" validate:"required,max=32"`
+}
+
+func (d *EnableTwoFactorDto) Validate(v *validator.Validate) error {
+	return v.Struct(d)
+}
+
+func (d *DisableTwoFactorDto) Validate(v *validator.Validate) error {
+	return v.Struct(d)
+}
+
+func (d *VerifyTwoFactorDto) Validate(v *validator.Validate) error {
+	return v.Struct(d)
+}
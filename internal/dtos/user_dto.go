@@ -15,13 +15,24 @@ type LoginUserDto struct {
 	Password string `json:"password" db:"password" validate:"password"`
 }
 
+// UpdateUserDto holds the profile fields a user may change about themselves.
+// Changing the password of an account that already has one requires CurrentPassword.
+//
+// Weight and Height are expressed in the unit system sent in the same request, or in the
+// user's stored preference when UnitSystem is omitted: kg/cm for metric, lb/in for imperial.
+// Their upper bounds depend on that unit system, so they are checked after conversion.
 type UpdateUserDto struct {
-	Name                 *string `json:"name,omitempty" db:"name" validate:"omitempty,min=2,max=50"`
-	ProfilePicture       *string `json:"profile_picture,omitempty" db:"profile_picture"`
-	Password             *string `json:"password,omitempty" db:"password" validate:"password"`
-	Provider             *string `json:"provider,omitempty" db:"provider"`
-	ProviderID           *string `json:"provider_id,omitempty" db:"provider_id"`
-	ProviderRefreshToken *string `json:"provider_refresh_token,omitempty" db:"provider_refresh_token"`
+	Name            *string  `json:"name,omitempty" db:"name" validate:"omitempty,min=2,max=50"`
+	ProfilePicture  *string  `json:"profile_picture,omitempty" db:"profile_picture" validate:"omitempty,url,max=255"`
+	Password        *string  `json:"password,omitempty" db:"password" validate:"omitempty,password"`
+	CurrentPassword *string  `json:"current_password,omitempty" db:"-"`
+	Weight          *float64 `json:"weight,omitempty" db:"weight_kg" validate:"omitempty,gt=0"`
+	Height          *float64 `json:"height,omitempty" db:"height_cm" validate:"omitempty,gt=0"`
+	Sex             *string  `json:"sex,omitempty" db:"sex" validate:"omitempty,oneof=male female"`
+	DateOfBirth     *string  `json:"date_of_birth,omitempty" db:"date_of_birth" validate:"omitempty,datetime=2006-01-02"`
+	UnitSystem      *string  `json:"unit_system,omitempty" db:"unit_system" validate:"omitempty,oneof=metric imperial"`
+	DrinkLocale     *string  `json:"drink_locale,omitempty" db:"drink_locale" validate:"omitempty,drink_locale"`
+	TimeZone        *string  `json:"time_zone,omitempty" db:"time_zone" validate:"omitempty,timezone"`
 }
 
 func (u *RegisterUserDto) Validate(v *validator.Validate) error {
@@ -0,0 +1,24 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Beverage represents a catalog item that drink entries can reference, so users don't
+// have to retype the ABV and serving size of the drinks they log most often. The catalog is
+// shared by every user, so it is maintained outside the API, with cmd/import-beverages;
+// users can only search it.
+type Beverage struct {
+	ID             uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	Brand          string    `gorm:"size:255;not null"`
+	Name           string    `gorm:"size:255;not null"`
+	Style          *string   `gorm:"size:100"` // e.g. "IPA", "Pinot Noir", "London Dry".
+	Category       string    `gorm:"size:50;not null"`
+	DefaultABV     float64   `gorm:"not null"`
+	ServingSizesMl []float64 `gorm:"type:jsonb;serializer:json"` // Common serving sizes, the first one being the default.
+	Barcode        *string   `gorm:"size:14;uniqueIndex"`        // EAN-8, UPC-A, EAN-13 or GTIN-14.
+	CreatedAt      time.Time `gorm:"autoCreateTime"`
+	UpdatedAt      time.Time `gorm:"autoUpdateTime"`
+}
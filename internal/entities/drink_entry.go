@@ -0,0 +1,41 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Drink categories accepted for a DrinkEntry.
+const (
+	DrinkCategoryBeer     = "beer"
+	DrinkCategoryWine     = "wine"
+	DrinkCategorySpirits  = "spirits"
+	DrinkCategoryCider    = "cider"
+	DrinkCategoryCocktail = "cocktail"
+	DrinkCategoryOther    = "other"
+)
+
+// DrinkEntry represents a single drink logged by a user.
+type DrinkEntry struct {
+	ID               uuid.UUID        `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	UserID           uuid.UUID        `gorm:"type:uuid;not null;index:idx_drink_entries_user_consumed,priority:1"`
+	User             *User            `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	BeverageID       *uuid.UUID       `gorm:"type:uuid;index"` // Optional catalog item the drink was logged from.
+	Beverage         *Beverage        `gorm:"constraint:OnDelete:SET NULL" json:"-"`
+	SessionID        *uuid.UUID       `gorm:"type:uuid;index"` // Drinking session the drink belongs to.
+	Session          *DrinkingSession `gorm:"constraint:OnDelete:SET NULL" json:"-"`
+	BeverageName     string           `gorm:"size:255;not null"`
+	Category         string           `gorm:"size:50;not null"`
+	VolumeMl         float64          `gorm:"not null"` // Volume of the drink in millilitres.
+	ABV              float64          `gorm:"not null"` // Alcohol by volume, as a percentage (0-100).
+	ConsumedAt       time.Time        `gorm:"not null;index:idx_drink_entries_user_consumed,priority:2"`
+	Price            *float64         `gorm:"type:numeric(12,2)"` // Amount paid, in Currency.
+	Currency         *string          `gorm:"size:3"`             // ISO 4217 code of Price.
+	Calories         *float64         // Energy in kcal; nil only for drinks logged before calories were tracked.
+	CaloriesProvided bool             `gorm:"not null;default:false"` // False when Calories is an estimate, kept in sync with the drink.
+	Notes            *string          `gorm:"size:1000"`
+	ImageRef         *string          `gorm:"size:255"` // Optional reference to an uploaded image of the drink.
+	CreatedAt        time.Time        `gorm:"autoCreateTime"`
+	UpdatedAt        time.Time        `gorm:"autoUpdateTime"`
+}
@@ -0,0 +1,19 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DrinkingLimit holds the consumption goals a user has set for themselves.
+// Every limit is optional; a nil value means the user has not set that goal.
+type DrinkingLimit struct {
+	UserID                 uuid.UUID `gorm:"type:uuid;primaryKey"`
+	User                   *User     `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	DailyStandardDrinks    *float64
+	WeeklyStandardDrinks   *float64
+	AlcoholFreeDaysPerWeek *int
+	CreatedAt              time.Time `gorm:"autoCreateTime"`
+	UpdatedAt              time.Time `gorm:"autoUpdateTime"`
+}
@@ -0,0 +1,27 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DrinkingSession groups the drinks of a single drinking occasion, such as a night out.
+// Automatic sessions cluster drinks logged close together and span from the first to the
+// last of them. Manual sessions are started and ended by the user and hold every drink
+// consumed in between.
+type DrinkingSession struct {
+	ID           uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	UserID       uuid.UUID  `gorm:"type:uuid;not null;index:idx_drinking_sessions_user_started,priority:1"`
+	User         *User      `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	StartedAt    time.Time  `gorm:"not null;index:idx_drinking_sessions_user_started,priority:2"`
+	EndedAt      *time.Time // Nil while a manual session is running.
+	Manual       bool       `gorm:"not null;default:false"`
+	Location     *string    `gorm:"size:255"` // Free-form label such as "Joe's birthday" or a venue name.
+	DrinkCount   int        `gorm:"not null;default:0"`
+	EthanolGrams float64    `gorm:"not null;default:0"`
+	PeakBAC      *float64   // Estimated peak BAC in g/100ml; nil when the user's weight or sex is unknown.
+	PeakBACAt    *time.Time
+	CreatedAt    time.Time `gorm:"autoCreateTime"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime"`
+}
@@ -0,0 +1,18 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RecoveryCode is a one-time code that replaces a TOTP code when the user has lost their
+// authenticator. Only the SHA-256 hash of the code is stored.
+type RecoveryCode struct {
+	ID        uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index"`
+	User      *User      `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	CodeHash  string     `gorm:"size:64;not null"`
+	UsedAt    *time.Time // Nil until the code is used.
+	CreatedAt time.Time  `gorm:"autoCreateTime"`
+}
@@ -0,0 +1,17 @@
+package entities
+
+import "time"
+
+// SigningKey is an RSA key signing the access or refresh tokens. A key signs tokens from
+// ActivatesAt until RetiresAt, and verifies them until ExpiresAt, which leaves the tokens it
+// signed time to expire after it is rotated. It is published in the JWKS from its creation,
+// so verifiers know it before it signs anything.
+type SigningKey struct {
+	ID          string     `gorm:"size:64;primaryKey"`                      // The kid of the tokens it signs: the RFC 7638 thumbprint of the key.
+	Purpose     string     `gorm:"size:16;primaryKey;index"`                // The type of token signed, "access" or "refresh". The same key may sign both.
+	PrivateKey  string     `gorm:"type:text;not null;serializer:encrypted"` // Base64-encoded PEM, encrypted at rest.
+	ActivatesAt time.Time  `gorm:"not null"`
+	RetiresAt   *time.Time // Nil while it is the newest key.
+	ExpiresAt   *time.Time // Nil while it is the newest key.
+	CreatedAt   time.Time  `gorm:"autoCreateTime"`
+}
@@ -8,14 +8,23 @@ import (
 
 // User represents a user in the application.
 type User struct {
-	ID                   uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
-	Email                string    `gorm:"uniqueIndex;size:255;not null" validate:"required,email"` // Nullable
-	Password             *string   `gorm:"size:255" validate:"password"`
-	Name                 string    `gorm:"size:255;not null" validate:"required,min=2,max=50"`
-	Provider             *string   `gorm:"size:255"`
-	ProviderID           *string   `gorm:"size:255;uniqueIndex"`
-	ProfilePicture       *string   `gorm:"size:255"`
-	ProviderRefreshToken *string   `gorm:"size:255"`
-	CreatedAt            time.Time `gorm:"autoCreateTime"`
-	UpdatedAt            time.Time `gorm:"autoUpdateTime"`
+	ID               uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	Email            string     `gorm:"uniqueIndex;size:255;not null" validate:"required,email"` // Nullable
+	EmailVerified    bool       `gorm:"not null;default:false"`
+	Password         *string    `gorm:"size:255" validate:"password"`
+	Name             string     `gorm:"size:255;not null" validate:"required,min=2,max=50"`
+	Provider         *string    `gorm:"size:255"` // Legacy, with ProviderID: moved to a UserIdentity and cleared on migration.
+	ProviderID       *string    `gorm:"size:255;uniqueIndex"`
+	ProfilePicture   *string    `gorm:"size:255"`
+	WeightKg         *float64   // Body weight, always stored in kilograms.
+	HeightCm         *float64   // Height, always stored in centimetres.
+	Sex              *string    `gorm:"size:10"` // Biological sex, "male" or "female".
+	DateOfBirth      *time.Time `gorm:"type:date"`
+	UnitSystem       string     `gorm:"size:10;not null;default:metric"`         // Preferred unit system, "metric" or "imperial".
+	DrinkLocale      *string    `gorm:"size:8"`                                  // Standard drink definition to use; nil means the configured default.
+	TimeZone         *string    `gorm:"size:64"`                                 // IANA time zone defining the user's days and weeks; nil means UTC.
+	TotpSecret       *string    `gorm:"type:text;serializer:encrypted" json:"-"` // Base32 TOTP secret; set at enrollment, before 2FA is enabled. Encrypted at rest, never serialized.
+	TwoFactorEnabled bool       `gorm:"not null;default:false"`
+	CreatedAt        time.Time  `gorm:"autoCreateTime"`
+	UpdatedAt        time.Time  `gorm:"autoUpdateTime"`
 }
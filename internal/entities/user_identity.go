@@ -0,0 +1,21 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserIdentity links a user to an account at an OAuth provider they can log in with.
+// A user has at most one identity per provider, and a provider account belongs to at most
+// one user.
+type UserIdentity struct {
+	ID             uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	UserID         uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_user_identities_user_provider"`
+	User           *User     `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	Provider       string    `gorm:"size:32;not null;uniqueIndex:idx_user_identities_user_provider;uniqueIndex:idx_user_identities_provider_account"`
+	ProviderUserID string    `gorm:"size:255;not null;uniqueIndex:idx_user_identities_provider_account"`
+	Email          string    `gorm:"size:255"`                                // Email of the provider account when it was linked.
+	RefreshToken   *string   `gorm:"type:text;serializer:encrypted" json:"-"` // Latest refresh token the provider issued; encrypted at rest, never serialized.
+	LinkedAt       time.Time `gorm:"autoCreateTime"`
+}
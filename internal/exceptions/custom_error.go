@@ -19,6 +19,23 @@ var (
 	ErrRedisNotFound       = fmt.Errorf("We couldn’t find your token. Please log in again to obtain a new one.")
 	ErrRedisDel            = fmt.Errorf("We couldn't delete the given keys, please check the Redis server.")
 	ErrTokenMismatch       = fmt.Errorf("The token does not match our records. Please log in again.")
+	ErrAuthSessionNotFound = fmt.Errorf("No active session found with the provided ID. It may have already been signed out.")
+	ErrAuthSessionIDParse  = fmt.Errorf("The session ID you entered is not valid. Please check your input and try again.")
+	ErrRefreshTokenReused  = fmt.Errorf("This session was signed out because its refresh token was used more than once. Please log in again.")
+	ErrTwoFactorChallenge  = fmt.Errorf("Your login attempt has expired. Please log in again with your email and password.")
+	ErrTwoFactorCode       = fmt.Errorf("The verification code is not valid. Please enter the current code from your authenticator app or one of your recovery codes.")
+	ErrTwoFactorEnabled    = fmt.Errorf("Two-factor authentication is already enabled for your account.")
+	ErrTwoFactorNotEnabled = fmt.Errorf("Two-factor authentication is not enabled for your account.")
+	ErrTwoFactorNotSetUp   = fmt.Errorf("Two-factor authentication has not been set up yet. Please start the enrollment first.")
+	ErrTwoFactorNoPassword = fmt.Errorf("Two-factor authentication is only available for accounts that log in with a password.")
+	ErrTwoFactorNotUpdated = fmt.Errorf("We couldn't update your two-factor authentication settings. Please try again later or contact support.")
+	ErrInsufficientScope   = fmt.Errorf("Your session does not grant access to this feature. Please log in again.")
+	ErrEmailNotVerified    = fmt.Errorf("Please verify your email address to use this feature. Check your inbox for the verification link.")
+	ErrEmailVerified       = fmt.Errorf("Your email address is already verified.")
+	ErrEmailToken          = fmt.Errorf("This verification link is invalid or has expired. Please request a new one.")
+	ErrEmailNotSent        = fmt.Errorf("We couldn't send you an email. Please try again later or contact support.")
+	ErrEmailTooSoon        = fmt.Errorf("We just sent you an email. Please wait a minute before requesting another one.")
+	ErrPasswordResetToken  = fmt.Errorf("This password reset link is invalid or has expired. Please request a new one.")
 	ErrUserNotFound        = fmt.Errorf("No user found with the provided information. Please check your input and try again.")
 	ErrUserIDMismatch      = fmt.Errorf("You are not authorized to perform this action. Please check if you're logged in with the correct account.")
 	ErrUserIDParse         = fmt.Errorf("The user ID you entered is not valid. Please check your input and try again.")
@@ -26,16 +43,43 @@ var (
 	ErrUserNotExists       = fmt.Errorf("No user found with the provided details. Please check your input and try again.")
 	ErrUserNotCreated      = fmt.Errorf("We couldn't create your account. Please try again later or contact support.")
 	ErrUserNotUpdated      = fmt.Errorf("We couldn't update your account. Please try again later or contact support.")
+	ErrUserNotDeleted      = fmt.Errorf("We couldn't delete your account. Please try again later or contact support.")
 	ErrTokenNotGenerated   = fmt.Errorf("We couldn't generate a token. Please try logging in again.")
 	ErrRedisSet            = fmt.Errorf("We couldn't save your session. Please log in again.")
+	ErrOAuthState          = fmt.Errorf("Your login attempt has expired or was already completed. Please start again.")
+	ErrUnknownProvider     = fmt.Errorf("This login provider is not supported.")
+	ErrIdentityNotFound    = fmt.Errorf("This login provider is not linked to your account.")
+	ErrIdentityLinked      = fmt.Errorf("This provider account is already linked to another user.")
+	ErrProviderLinked      = fmt.Errorf("Your account is already linked to another account at this provider. Please unlink it first.")
+	ErrIdentityEmailInUse  = fmt.Errorf("An account with this email address already exists. Please log in to it and link this provider from your account settings.")
+	ErrLastLoginMethod     = fmt.Errorf("This is the only way left to log in to your account. Add a password or link another provider before removing it.")
 	ErrExchangeToken       = fmt.Errorf("We couldn’t exchange your token. Please try again later or contact support.")
 	ErrToReadUserInfo      = fmt.Errorf("We couldn't retrieve your user information. Please try again later or contact support.")
-	ErrToUnmarshalUserInfo = fmt.Errorf("We couldn't process your user information. Please try again later or contact support.")
+	ErrAccountLocked       = fmt.Errorf("Too many failed login attempts. Please wait before trying again.")
+	ErrRateLimited         = fmt.Errorf("Too many requests. Please slow down and try again later.")
+	ErrRateLimitFailed     = fmt.Errorf("The service is temporarily unavailable. Please try again later.")
 	ErrInvalidCredentials  = fmt.Errorf("Invalid email or password. Please verify your credentials and try again.")
 	ErrRequestBody         = fmt.Errorf("The request body is invalid. Please check the request and try again.")
 	ErrDatabase            = fmt.Errorf("A database error occurred. Please try again or contact support.")
 	ErrPasswordRequired    = fmt.Errorf("Password is required. Please provide a valid password.")
 	ErrValidationFailed    = fmt.Errorf("Validation failed. Please check your input and try again.")
+	ErrInvalidQuery        = fmt.Errorf("The query parameters are invalid. Please check the request and try again.")
+	ErrDrinkNotFound       = fmt.Errorf("No drink found with the provided information. Please check your input and try again.")
+	ErrDrinkIDParse        = fmt.Errorf("The drink ID you entered is not valid. Please check your input and try again.")
+	ErrDrinkNotCreated     = fmt.Errorf("We couldn't save your drink. Please try again later or contact support.")
+	ErrDrinkNotUpdated     = fmt.Errorf("We couldn't update your drink. Please try again later or contact support.")
+	ErrDrinkNotDeleted     = fmt.Errorf("We couldn't delete your drink. Please try again later or contact support.")
+	ErrBacEstimation       = fmt.Errorf("We couldn't estimate your blood alcohol concentration. Please check your profile and try again.")
+	ErrProfileIncomplete   = fmt.Errorf("Your weight and sex are needed for this feature. Please complete your profile and try again.")
+	ErrBeverageNotFound    = fmt.Errorf("No beverage found with the provided information. Please check your input and try again.")
+	ErrBarcodeInvalid      = fmt.Errorf("The barcode you entered is not valid. Please scan it again or check your input.")
+	ErrLimitsNotUpdated    = fmt.Errorf("We couldn't save your limits. Please try again later or contact support.")
+	ErrSessionNotFound     = fmt.Errorf("No drinking session found with the provided information. Please check your input and try again.")
+	ErrSessionIDParse      = fmt.Errorf("The session ID you entered is not valid. Please check your input and try again.")
+	ErrSessionNotCreated   = fmt.Errorf("We couldn't start your drinking session. Please try again later or contact support.")
+	ErrSessionNotUpdated   = fmt.Errorf("We couldn't update your drinking session. Please try again later or contact support.")
+	ErrSessionRunning      = fmt.Errorf("You already have a drinking session running. End it before starting a new one.")
+	ErrSessionNotRunning   = fmt.Errorf("This drinking session is not running, so it cannot be ended.")
 )
 
 // ErrorMapping maps error types to HTTP status codes.
@@ -48,23 +92,67 @@ var ErrorMapping = map[error]struct {
 	ErrRedisNotFound:       {http.StatusUnauthorized},
 	ErrRedisDel:            {http.StatusInternalServerError},
 	ErrTokenMismatch:       {http.StatusUnauthorized},
+	ErrRefreshTokenReused:  {http.StatusUnauthorized},
+	ErrAuthSessionNotFound: {http.StatusNotFound},
+	ErrAuthSessionIDParse:  {http.StatusBadRequest},
+	ErrTwoFactorChallenge:  {http.StatusUnauthorized},
+	ErrTwoFactorCode:       {http.StatusUnauthorized},
+	ErrTwoFactorEnabled:    {http.StatusConflict},
+	ErrTwoFactorNotEnabled: {http.StatusConflict},
+	ErrTwoFactorNotSetUp:   {http.StatusConflict},
+	ErrTwoFactorNoPassword: {http.StatusForbidden},
+	ErrTwoFactorNotUpdated: {http.StatusInternalServerError},
+	ErrInsufficientScope:   {http.StatusForbidden},
+	ErrEmailNotVerified:    {http.StatusForbidden},
+	ErrEmailVerified:       {http.StatusConflict},
+	ErrEmailToken:          {http.StatusBadRequest},
+	ErrEmailNotSent:        {http.StatusInternalServerError},
+	ErrEmailTooSoon:        {http.StatusTooManyRequests},
+	ErrPasswordResetToken:  {http.StatusBadRequest},
 	ErrUserIDParse:         {http.StatusUnauthorized},
 	ErrUserNotFound:        {http.StatusNotFound},
 	ErrUserIDMismatch:      {http.StatusConflict},
 	ErrUserNotExists:       {http.StatusNotFound},
 	ErrUserNotCreated:      {http.StatusInternalServerError},
 	ErrUserNotUpdated:      {http.StatusInternalServerError},
+	ErrUserNotDeleted:      {http.StatusInternalServerError},
 	ErrUserAlreadyExists:   {http.StatusConflict},
 	ErrTokenNotGenerated:   {http.StatusInternalServerError},
 	ErrRedisSet:            {http.StatusInternalServerError},
+	ErrOAuthState:          {http.StatusBadRequest},
+	ErrUnknownProvider:     {http.StatusNotFound},
+	ErrIdentityNotFound:    {http.StatusNotFound},
+	ErrIdentityLinked:      {http.StatusConflict},
+	ErrProviderLinked:      {http.StatusConflict},
+	ErrIdentityEmailInUse:  {http.StatusConflict},
+	ErrLastLoginMethod:     {http.StatusConflict},
 	ErrExchangeToken:       {http.StatusInternalServerError},
 	ErrToReadUserInfo:      {http.StatusInternalServerError},
-	ErrToUnmarshalUserInfo: {http.StatusInternalServerError},
 	ErrInvalidCredentials:  {http.StatusUnauthorized},
+	ErrAccountLocked:       {http.StatusTooManyRequests},
+	ErrRateLimited:         {http.StatusTooManyRequests},
+	ErrRateLimitFailed:     {http.StatusServiceUnavailable},
 	ErrRequestBody:         {http.StatusBadRequest},
 	ErrDatabase:            {http.StatusInternalServerError},
 	ErrPasswordRequired:    {http.StatusBadRequest},
 	ErrValidationFailed:    {http.StatusBadRequest},
+	ErrInvalidQuery:        {http.StatusBadRequest},
+	ErrDrinkNotFound:       {http.StatusNotFound},
+	ErrDrinkIDParse:        {http.StatusBadRequest},
+	ErrDrinkNotCreated:     {http.StatusInternalServerError},
+	ErrDrinkNotUpdated:     {http.StatusInternalServerError},
+	ErrDrinkNotDeleted:     {http.StatusInternalServerError},
+	ErrBacEstimation:       {http.StatusUnprocessableEntity},
+	ErrProfileIncomplete:   {http.StatusUnprocessableEntity},
+	ErrBeverageNotFound:    {http.StatusNotFound},
+	ErrBarcodeInvalid:      {http.StatusBadRequest},
+	ErrLimitsNotUpdated:    {http.StatusInternalServerError},
+	ErrSessionNotFound:     {http.StatusNotFound},
+	ErrSessionIDParse:      {http.StatusBadRequest},
+	ErrSessionNotCreated:   {http.StatusInternalServerError},
+	ErrSessionNotUpdated:   {http.StatusInternalServerError},
+	ErrSessionRunning:      {http.StatusConflict},
+	ErrSessionNotRunning:   {http.StatusConflict},
 }
 
 // ErrorResponse represents a JSON error response.
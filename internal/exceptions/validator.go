@@ -5,6 +5,8 @@ import (
 	"strings"
 
 	"github.com/go-playground/validator/v10"
+
+	"github.com/starks97/alcohol-tracker-api/internal/units"
 )
 
 // ValidatePassword checks if a password meets certain complexity requirements.
@@ -49,9 +51,10 @@ func ValidatePassword(password string) []string {
 	return passwordErrors
 }
 
-// Init initializes a new validator instance and registers a custom "password" validation rule.
+// Init initializes a new validator instance and registers the custom validation rules.
 //
 // The "password" rule uses the `ValidatePassword` function to check password validity.
+// The "drink_locale" rule accepts the locales known to the standard drink conversion engine.
 // It returns the initialized validator instance.
 // If the validator initialization fails, it logs a fatal error and exits.
 func Init() *validator.Validate {
@@ -68,5 +71,9 @@ func Init() *validator.Validate {
 		return len(ValidatePassword(password)) == 0
 	})
 
+	validate.RegisterValidation("drink_locale", func(fl validator.FieldLevel) bool {
+		return units.IsSupportedLocale(fl.Field().String())
+	})
+
 	return validate
 }
@@ -0,0 +1,90 @@
+package authen
+
+import (
+	"errors"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+
+	"github.com/starks97/alcohol-tracker-api/internal/exceptions"
+	"github.com/starks97/alcohol-tracker-api/internal/repositories"
+	"github.com/starks97/alcohol-tracker-api/internal/responses"
+	"github.com/starks97/alcohol-tracker-api/internal/state"
+	"github.com/starks97/alcohol-tracker-api/internal/strategies"
+	"github.com/starks97/alcohol-tracker-api/internal/utils"
+)
+
+// ListIdentitiesHandler returns the ways the authenticated user can log in: their
+// password, if they have one, and the linked provider accounts.
+func ListIdentitiesHandler(c *fiber.Ctx) error {
+	appState := c.Locals("appState").(*state.AppState)
+	userData := c.Locals("mdlData").(*responses.JwtMiddlewareResponse)
+	identityRepo := repositories.NewIdentityRepository(appState.DB)
+
+	identities, err := identityRepo.ListIdentities(userData.User.ID)
+	if err != nil {
+		log.Println("Failed to list identities:", err)
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrDatabase)
+	}
+
+	return c.JSON(responses.SuccessResponse{
+		Status: "success",
+		Data:   responses.NewIdentitiesResponse(&userData.User, identities),
+	})
+}
+
+// LinkIdentityHandler starts linking a provider account to the authenticated user. The
+// client sends the user to the returned authorization URL, and the provider's callback
+// completes the link.
+func LinkIdentityHandler(c *fiber.Ctx) error {
+	appState := c.Locals("appState").(*state.AppState)
+	userData := c.Locals("mdlData").(*responses.JwtMiddlewareResponse)
+	provider := c.Params("provider")
+
+	authStrategy, err := strategies.NewAuthStrategy(appState, provider)
+	if err != nil {
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrUnknownProvider)
+	}
+
+	url, err := startOAuthFlow(c, appState, authStrategy, utils.OAuthFlow{
+		Mode:     utils.OAuthFlowLink,
+		Provider: provider,
+		UserID:   userData.User.ID,
+	})
+	if err != nil {
+		return exceptions.HandlerErrorResponse(c, err)
+	}
+
+	return c.JSON(responses.SuccessResponse{
+		Status: "success",
+		Data:   responses.AuthorizationURLResponse{AuthorizationURL: url},
+	})
+}
+
+// UnlinkIdentityHandler removes a provider from the authenticated user's login methods,
+// unless it is the last one left.
+func UnlinkIdentityHandler(c *fiber.Ctx) error {
+	appState := c.Locals("appState").(*state.AppState)
+	userData := c.Locals("mdlData").(*responses.JwtMiddlewareResponse)
+	identityRepo := repositories.NewIdentityRepository(appState.DB)
+
+	err := identityRepo.UnlinkIdentity(userData.User.ID, c.Params("provider"))
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return exceptions.HandlerErrorResponse(c, exceptions.ErrIdentityNotFound)
+		case errors.Is(err, repositories.ErrLastLoginMethod):
+			return exceptions.HandlerErrorResponse(c, exceptions.ErrLastLoginMethod)
+		default:
+			log.Println("Failed to unlink identity:", err)
+			return exceptions.HandlerErrorResponse(c, exceptions.ErrUserNotUpdated)
+		}
+	}
+
+	message := "The provider has been unlinked from your account"
+	return c.JSON(responses.SuccessResponse{
+		Status:  "success",
+		Message: &message,
+	})
+}
@@ -0,0 +1,49 @@
+package authen
+
+import (
+	"log"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/starks97/alcohol-tracker-api/internal/exceptions"
+	"github.com/starks97/alcohol-tracker-api/internal/responses"
+	"github.com/starks97/alcohol-tracker-api/internal/services"
+	"github.com/starks97/alcohol-tracker-api/internal/state"
+	"github.com/starks97/alcohol-tracker-api/internal/utils"
+)
+
+// JWKSHandler publishes the public keys verifying access tokens, so that other services can
+// verify them without sharing the key pair. Keys are listed from before they sign tokens
+// until the tokens they signed have expired.
+//
+// Parameters:
+//   - c: *fiber.Ctx - The Fiber context.
+//
+// Returns:
+//   - error: An error if the keys cannot be loaded, or nil if successful.
+func JWKSHandler(c *fiber.Ctx) error {
+	appState := c.Locals("appState").(*state.AppState)
+
+	keys, err := utils.VerificationKeys(appState, services.TokenTypeAccess)
+	if err != nil {
+		log.Println("Failed to load signing keys:", err)
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrDatabase)
+	}
+
+	response := responses.JSONWebKeySetResponse{Keys: []responses.JSONWebKey{}}
+	for _, kid := range utils.SortedKeyIDs(keys) {
+		n, e := services.JSONWebKeyParams(keys[kid])
+		response.Keys = append(response.Keys, responses.JSONWebKey{
+			Kty: "RSA",
+			Use: "sig",
+			Alg: "RS256",
+			Kid: kid,
+			N:   n,
+			E:   e,
+		})
+	}
+
+	c.Set(fiber.HeaderCacheControl, "public, max-age="+strconv.Itoa(int(utils.JWKSCacheMaxAge.Seconds())))
+	return c.JSON(response)
+}
@@ -3,12 +3,14 @@ package authen
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 
 	"github.com/starks97/alcohol-tracker-api/internal/dtos"
+	"github.com/starks97/alcohol-tracker-api/internal/entities"
 	"github.com/starks97/alcohol-tracker-api/internal/exceptions"
 	"github.com/starks97/alcohol-tracker-api/internal/repositories"
 	"github.com/starks97/alcohol-tracker-api/internal/responses"
@@ -21,8 +23,6 @@ func LoginHandler(c *fiber.Ctx) error {
 
 	userRepo := repositories.NewUserRepository(appState.DB)
 
-	tokenService := utils.NewTokenService(appState)
-
 	var userDataFromReq dtos.LoginUserDto
 
 	ctx := c.Locals("ctx").(context.Context)
@@ -38,32 +38,66 @@ func LoginHandler(c *fiber.Ctx) error {
 		return exceptions.HandlerErrorResponse(c, err)
 	}
 
-	userInDB, err := userRepo.GetUserByEmail(userDataFromReq.Email)
-	if err != nil {
-		if !errors.Is(err, gorm.ErrRecordNotFound) {
-			return exceptions.HandlerErrorResponse(c, exceptions.ErrDatabase)
+	loginGuard := utils.NewLoginGuard(appState)
 
-		} else {
-			return exceptions.HandlerErrorResponse(c, exceptions.ErrUserNotFound)
-		}
+	if retryAfter := loginGuard.Locked(ctx, userDataFromReq.Email, c.IP()); retryAfter > 0 {
+		c.Set(fiber.HeaderRetryAfter, utils.RetryAfterSeconds(retryAfter))
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrAccountLocked)
+	}
 
+	userInDB, err := userRepo.GetUserByEmail(userDataFromReq.Email)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrDatabase)
+	}
+
+	// Unknown emails and accounts without a password fail exactly like a wrong password,
+	// after as long a bcrypt comparison, so that the response tells nothing about which
+	// emails are registered.
+	if userInDB == nil || userInDB.Password == nil {
+		utils.CompareDummyPassword(userDataFromReq.Password)
+		loginGuard.RecordFailure(ctx, userDataFromReq.Email, c.IP())
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrInvalidCredentials)
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(*userInDB.Password), []byte(userDataFromReq.Password)); err != nil {
+		loginGuard.RecordFailure(ctx, userDataFromReq.Email, c.IP())
 		return exceptions.HandlerErrorResponse(c, exceptions.ErrInvalidCredentials)
 	}
 
-	tokenResult, err := tokenService.StoreToken(c, ctx, userInDB.ID, "both")
-	if err != nil {
-		return err
+	loginGuard.RecordSuccess(ctx, userDataFromReq.Email)
+
+	return completeLogin(c, ctx, appState, userInDB)
+}
+
+// completeLogin issues the tokens of an authenticated user. With 2FA enabled, the first
+// factor alone is not enough: a challenge to redeem with a code at POST /auth/2fa/verify
+// is returned instead.
+func completeLogin(c *fiber.Ctx, ctx context.Context, appState *state.AppState, user *entities.User) error {
+	if user.TwoFactorEnabled {
+		challenge, err := utils.NewTwoFactorChallengeStore(appState).Issue(ctx, user.ID.String())
+		if err != nil {
+			return exceptions.HandlerErrorResponse(c, err)
+		}
+
+		return c.JSON(responses.SuccessResponse{
+			Status: "success",
+			Data: responses.TwoFactorChallengeResponse{
+				TwoFactorRequired: true,
+				ChallengeToken:    challenge,
+				ExpiresIn:         int(utils.TwoFactorChallengeTTL / time.Second),
+			},
+		})
 	}
 
-	accessToken := responses.LoginResponse{
-		AccessToken: *tokenResult.Token,
+	tokenResult, err := utils.NewTokenService(appState).StoreToken(c, ctx, user.ID, "both")
+	if err != nil {
+		return err
 	}
 
 	return c.JSON(responses.SuccessResponse{
 		Status: "success",
-		Data:   accessToken,
+		Data: responses.LoginResponse{
+			AccessToken: *tokenResult.Token,
+		},
 	})
 }
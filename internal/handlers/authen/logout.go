@@ -26,12 +26,16 @@ func LogOutHandler(c *fiber.Ctx) error {
 		return exceptions.HandlerErrorResponse(c, exceptions.ErrTokenMissing)
 	}
 
-	tokenDetail, err := services.VerifyJwtToken(appState.Config.RefreshTokenPublicKey, reCookie)
+	tokenDetail, err := utils.VerifyJwtToken(appState, services.TokenTypeRefresh, reCookie)
 	if err != nil {
 		return exceptions.HandlerErrorResponse(c, exceptions.ErrTokenVerification)
 	}
 
-	tokenService.RemoveRedisKeys(c, appState.Redis, ctx, userData.AccessToken.String(), tokenDetail.TokenUUID.String())
+	// Revoking the family also invalidates refresh tokens rotated out of it.
+	if err := utils.NewTokenFamilyStore(appState).RevokeByToken(ctx, tokenDetail.TokenUUID); err != nil {
+		return exceptions.HandlerErrorResponse(c, err)
+	}
+	tokenService.RemoveRedisKeys(c, appState.Redis, ctx, userData.AccessToken.String())
 
 	c.ClearCookie("refresh_token")
 	c.ClearCookie("access_token")
@@ -2,18 +2,13 @@ package authen
 
 import (
 	"context"
-	"encoding/json"
-	"errors"
-	"fmt"
 	"log"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
-	"gorm.io/gorm"
+	"golang.org/x/oauth2"
 
-	"github.com/starks97/alcohol-tracker-api/internal/dtos"
-	"github.com/starks97/alcohol-tracker-api/internal/entities"
 	"github.com/starks97/alcohol-tracker-api/internal/exceptions"
-	"github.com/starks97/alcohol-tracker-api/internal/repositories"
 	"github.com/starks97/alcohol-tracker-api/internal/responses"
 	"github.com/starks97/alcohol-tracker-api/internal/state"
 	"github.com/starks97/alcohol-tracker-api/internal/strategies"
@@ -43,26 +38,11 @@ func OAuthLoginHandler(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Invalid auth strategy"})
 	}
 
-	state, err := utils.GenerateRandomString(32)
+	url, err := startOAuthFlow(c, appState, authStrategy, utils.OAuthFlow{Mode: utils.OAuthFlowLogin, Provider: provider})
 	if err != nil {
-		log.Print("can not generate random state", err)
-		return fmt.Errorf("GenerateRandomString: %w", exceptions.HandlerErrorResponse(c, exceptions.ErrTokenNotGenerated))
-	}
-
-	cookie := fiber.Cookie{
-		Name:     "oauth_state",
-		Value:    state,
-		HTTPOnly: true,
-		Secure:   true,
-		SameSite: "localhost",
+		return exceptions.HandlerErrorResponse(c, err)
 	}
 
-	c.Cookie(&cookie)
-
-	url := authStrategy.GenerateAuthURL(state)
-
-	// Generate the authorization URL using a fixed state value.
-
 	// Redirect the user to the generated URL with a "See Other" status.
 	c.Status(fiber.StatusSeeOther)
 	c.Redirect(url)
@@ -71,10 +51,12 @@ func OAuthLoginHandler(c *fiber.Ctx) error {
 	return c.JSON(url)
 }
 
-// OAuthCallBackHandler handles the callback from Google's OAuth 2.0 authorization server.
+// OAuthCallBackHandler handles the callback from the provider's OAuth 2.0 authorization server.
 // It verifies the state parameter to prevent CSRF attacks, exchanges the authorization code
-// for an access token, retrieves user information from Google, and either creates a new user
-// or logs in an existing user. Finally, it generates JWT tokens and sets a refresh token cookie.
+// for an access token and retrieves user information from the provider. A login flow then
+// logs in the user of the provider account, creating it on first login, and generates JWT
+// tokens and sets a refresh token cookie. A link flow links the provider account to the
+// user who started it.
 //
 // Parameters:
 //   - c: *fiber.Ctx - The Fiber context.
@@ -87,100 +69,119 @@ func OAuthCallBackHandler(c *fiber.Ctx) error {
 	ctx := c.Locals("ctx").(context.Context)
 	provider := c.Params("provider")
 
-	tokenService := utils.NewTokenService(appState)
-
 	authStrategy, err := strategies.NewAuthStrategy(appState, provider)
 	if err != nil {
-		return exceptions.HandlerErrorResponse(c, err)
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrUnknownProvider)
 	}
 
-	// Initialize user repository.
-	userRepo := repositories.NewUserRepository(appState.DB)
+	// Providers using form_post send the parameters in the body of a POST, the others in
+	// the query string of a GET.
+	formPostStrategy, usesFormPost := authStrategy.(strategies.FormPostStrategy)
+	if usesFormPost != (c.Method() == fiber.MethodPost) {
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrOAuthState)
+	}
+	callbackParam := c.Query
+	if usesFormPost {
+		callbackParam = c.FormValue
+		formPostStrategy.UseCallbackForm(func(key string) string { return c.FormValue(key) })
+	}
 
-	// Retrieve the state parameter from the cookie to prevent CSRF attacks.
-	cookieState := c.Cookies("oauth_state")
+	// The state must match the cookie of the browser that started the flow, to prevent
+	// CSRF, and a flow stored in Redis, which can be completed only once.
+	queryState := callbackParam("state")
+	if cookieState := c.Cookies("oauth_state"); cookieState == "" || cookieState != queryState {
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrOAuthState)
+	}
+	c.ClearCookie("oauth_state")
 
-	// Retrieve the authorization code and state from the query parameters.
-	code := c.Query("code")
-	queryState := c.Query("state")
+	flow, err := utils.NewOAuthFlowStore(appState).Finish(ctx, queryState)
+	if err != nil {
+		return exceptions.HandlerErrorResponse(c, err)
+	}
+	if flow.Provider != provider {
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrOAuthState)
+	}
 
-	// Verify that the state from the cookie matches the state from the query parameters.
-	if cookieState != queryState {
-		return c.SendString("States don't Match!!")
+	if nonceStrategy, ok := authStrategy.(strategies.NonceStrategy); ok {
+		nonceStrategy.UseNonce(flow.Nonce)
 	}
 
-	// Exchange the authorization code for an access token from Google.
-	token, err := authStrategy.ExchangeCode(context.Background(), code)
+	// Exchange the authorization code for an access token from the provider.
+	// The verifier proves to the provider that this server started the flow the code was
+	// issued for.
+	token, err := authStrategy.ExchangeCode(ctx, callbackParam("code"), oauth2.VerifierOption(flow.Verifier))
 	if err != nil {
 		log.Println("Failed to exchange token:", err)
 		return exceptions.HandlerErrorResponse(c, exceptions.ErrExchangeToken)
 	}
 
-	// Retrieve user information from Google's userinfo endpoint using the access token.
-	userData, err := authStrategy.GetUserInfo(token)
+	// Retrieve user information from the provider using the access token.
+	oauthUser, err := authStrategy.GetUserInfo(ctx, token)
 	if err != nil {
 		log.Println("Failed to get user info:", err)
-		return exceptions.HandlerErrorResponse(c, exceptions.ErrUserNotFound)
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrToReadUserInfo)
 	}
+	if oauthUser.ID == "" {
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrToReadUserInfo)
+	}
+
+	if flow.Mode == utils.OAuthFlowLink {
+		if err := utils.LinkOAuthIdentity(appState, flow.UserID, provider, oauthUser, token.RefreshToken); err != nil {
+			return exceptions.HandlerErrorResponse(c, err)
+		}
 
-	// Unmarshal the user information into a GoogleUser struct.
-	var oauthUser dtos.OAuthDto
-	err = json.Unmarshal(userData, &oauthUser)
+		message := "Your " + provider + " account has been linked"
+		return c.JSON(responses.SuccessResponse{
+			Status:  "success",
+			Message: &message,
+		})
+	}
+
+	user, err := utils.ResolveOAuthUser(appState, provider, oauthUser, token.RefreshToken)
 	if err != nil {
-		log.Println("Failed to unmarshal user info:", err)
-		return exceptions.HandlerErrorResponse(c, exceptions.ErrToUnmarshalUserInfo)
+		return exceptions.HandlerErrorResponse(c, err)
 	}
 
-	// Check if the user exists in the database.
-	user, err := userRepo.GetUserByEmail(oauthUser.Email)
+	return completeLogin(c, ctx, appState, user)
+}
 
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			user = &entities.User{
-				Name:           oauthUser.Name,
-				Email:          oauthUser.Email,
-				Provider:       &provider,
-				ProviderID:     &oauthUser.ID,
-				ProfilePicture: &oauthUser.Picture,
-			}
-			_, err = userRepo.CreateUser(user)
-			if err != nil {
-				log.Println("Failed to create user:", err)
-				return exceptions.HandlerErrorResponse(c, exceptions.ErrUserNotCreated)
-			}
-		} else {
-			log.Println("Failed to get user:", err)
-			return exceptions.HandlerErrorResponse(c, fmt.Errorf("failed to get user: %w", err))
-		}
-	} else {
-		//user exist update user
-		user.Provider = &provider
-		user.ProviderID = &oauthUser.ID
-		user.ProfilePicture = &oauthUser.Picture
-		user.Name = oauthUser.Name
-		user.ProviderRefreshToken = &token.AccessToken
-
-		_, err = userRepo.UpdateUser(user)
+// startOAuthFlow stores a flow, sets the state cookie binding it to the browser and
+// returns the provider's authorization URL. OpenID Connect providers also get a nonce,
+// kept with the flow, that their ID token must carry.
+func startOAuthFlow(c *fiber.Ctx, appState *state.AppState, authStrategy strategies.AuthStrategy, flow utils.OAuthFlow) (string, error) {
+	ctx := c.Locals("ctx").(context.Context)
+
+	nonceStrategy, usesNonce := authStrategy.(strategies.NonceStrategy)
+	if usesNonce {
+		nonce, err := utils.GenerateRandomString(32)
 		if err != nil {
-			log.Println("Failed to update user:", err)
-			return exceptions.HandlerErrorResponse(c, exceptions.ErrUserNotUpdated)
+			log.Println("Failed to generate OIDC nonce:", err)
+			return "", exceptions.ErrRedisSet
 		}
+		flow.Nonce = nonce
+		nonceStrategy.UseNonce(nonce)
 	}
 
-	// Generate and store JWT tokens in Redis, and set a refresh token cookie.
-	tokenResult, err := tokenService.StoreToken(c, ctx, user.ID, "both")
+	state, challenge, err := utils.NewOAuthFlowStore(appState).Start(ctx, flow)
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	// Create a login response with the access token.
-	accessToken := responses.LoginResponse{
-		AccessToken: *tokenResult.Token,
+	cookie := fiber.Cookie{
+		Name:     "oauth_state",
+		Value:    state,
+		HTTPOnly: true,
+		Secure:   true,
+		SameSite: "localhost",
+		Expires:  time.Now().Add(utils.OAuthFlowTTL),
+	}
+
+	// A form_post callback is a cross-site POST, which carries only SameSite=None cookies.
+	if _, usesFormPost := authStrategy.(strategies.FormPostStrategy); usesFormPost {
+		cookie.SameSite = fiber.CookieSameSiteNoneMode
 	}
 
-	// Return a success response with the access token.
-	return c.JSON(responses.SuccessResponse{
-		Status: "success",
-		Data:   accessToken,
-	})
+	c.Cookie(&cookie)
+
+	return authStrategy.GenerateAuthURL(state, challenge), nil
 }
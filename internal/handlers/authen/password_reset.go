@@ -0,0 +1,98 @@
+package authen
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/starks97/alcohol-tracker-api/internal/dtos"
+	"github.com/starks97/alcohol-tracker-api/internal/exceptions"
+	"github.com/starks97/alcohol-tracker-api/internal/repositories"
+	"github.com/starks97/alcohol-tracker-api/internal/responses"
+	"github.com/starks97/alcohol-tracker-api/internal/state"
+	"github.com/starks97/alcohol-tracker-api/internal/utils"
+)
+
+// ForgotPasswordHandler emails a password reset link to the given address. The response
+// is the same whether or not an account uses the address, and the work happens after the
+// response is sent so that its timing does not tell either.
+func ForgotPasswordHandler(c *fiber.Ctx) error {
+	appState := c.Locals("appState").(*state.AppState)
+
+	var forgotDataFromReq dtos.ForgotPasswordDto
+
+	if err := c.BodyParser(&forgotDataFromReq); err != nil {
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrRequestBody)
+	}
+
+	if err := utils.ParseValidatorMessage(&forgotDataFromReq, appState.Validator); err != nil {
+		if validationErr, ok := err.(*utils.ValidationError); ok {
+			return exceptions.HandlerValidationErrorResponse(c, exceptions.ErrValidationFailed, validationErr.Errors)
+		}
+		return exceptions.HandlerErrorResponse(c, err)
+	}
+
+	email := strings.TrimSpace(forgotDataFromReq.Email)
+	utils.RunInBackground("send password reset email", func(ctx context.Context) error {
+		return utils.SendPasswordReset(ctx, appState, email)
+	})
+
+	message := "If an account uses this email address, we sent it a link to reset the password"
+	return c.JSON(responses.SuccessResponse{
+		Status:  "success",
+		Message: &message,
+	})
+}
+
+// ResetPasswordHandler sets a new password with the token of a password reset email and
+// signs the user out of every device. Receiving the email proves the address is the
+// user's, so it is marked verified too.
+func ResetPasswordHandler(c *fiber.Ctx) error {
+	appState := c.Locals("appState").(*state.AppState)
+	ctx := c.Locals("ctx").(context.Context)
+	userRepo := repositories.NewUserRepository(appState.DB)
+
+	var resetDataFromReq dtos.ResetPasswordDto
+
+	if err := c.BodyParser(&resetDataFromReq); err != nil {
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrRequestBody)
+	}
+
+	if err := utils.ParseValidatorMessage(&resetDataFromReq, appState.Validator); err != nil {
+		if validationErr, ok := err.(*utils.ValidationError); ok {
+			return exceptions.HandlerValidationErrorResponse(c, exceptions.ErrValidationFailed, validationErr.Errors)
+		}
+		return exceptions.HandlerErrorResponse(c, err)
+	}
+
+	user, err := utils.RedeemPasswordReset(ctx, appState, resetDataFromReq.Token)
+	if err != nil {
+		return exceptions.HandlerErrorResponse(c, err)
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(resetDataFromReq.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return exceptions.HandlerErrorResponse(c, err)
+	}
+
+	if err := userRepo.ResetPassword(user.ID, string(hashedPassword)); err != nil {
+		log.Println("Failed to reset password:", err)
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrUserNotUpdated)
+	}
+
+	if err := utils.NewTokenFamilyStore(appState).RevokeAll(ctx, user.ID); err != nil {
+		log.Println("Failed to revoke sessions:", err)
+	}
+
+	c.ClearCookie("refresh_token")
+	c.ClearCookie("access_token")
+
+	message := "Your password has been reset. Please log in with your new password"
+	return c.JSON(responses.SuccessResponse{
+		Status:  "success",
+		Message: &message,
+	})
+}
@@ -2,9 +2,9 @@ package authen
 
 import (
 	"context"
+	"errors"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/google/uuid"
 
 	"github.com/starks97/alcohol-tracker-api/internal/exceptions"
 	"github.com/starks97/alcohol-tracker-api/internal/repositories"
@@ -14,6 +14,15 @@ import (
 	"github.com/starks97/alcohol-tracker-api/internal/utils"
 )
 
+// RefreshTokenHandler exchanges the refresh token cookie for a new access token and
+// rotates the refresh token, so that each refresh token can only be used once. A refresh
+// token presented again after it was rotated signs out every session descending from the
+// same login.
+//
+// Two refreshes sent in parallel with the same cookie, such as by two tabs of the app, look
+// the same as a stolen token being replayed: the second one signs the user out. Clients
+// must let one refresh finish before sending another, for example by sharing a single
+// in-flight refresh between tabs.
 func RefreshTokenHandler(c *fiber.Ctx) error {
 	appState := c.Locals("appState").(*state.AppState)
 	ctx := c.Locals("ctx").(context.Context)
@@ -26,39 +35,29 @@ func RefreshTokenHandler(c *fiber.Ctx) error {
 		return exceptions.HandlerErrorResponse(c, exceptions.ErrTokenMissing)
 	}
 
-	verifyToken, err := services.VerifyJwtToken(appState.Config.RefreshTokenPublicKey, reCookie)
+	verifyToken, err := utils.VerifyJwtToken(appState, services.TokenTypeRefresh, reCookie)
 	if err != nil {
 		return exceptions.HandlerErrorResponse(c, exceptions.ErrTokenVerification)
 	}
 
-	accessTokenUuid := verifyToken.TokenUUID
-
-	redisValue, err := tokenService.GetRedisValue(c, appState.Redis, ctx, accessTokenUuid.String())
-	if err != nil {
-		// Return the error from GetAndCompareRedisValue, which already handles error responses.
-		return err
-	}
-
-	userID, err := uuid.Parse(redisValue)
-	if err != nil {
-		return exceptions.HandlerErrorResponse(c, exceptions.ErrUserIDParse)
-	}
-
-	user, err := userRepo.GetUserByID(userID)
+	user, err := userRepo.GetUserByID(verifyToken.UserID)
 	if err != nil {
 		// Return a custom error response indicating that the user was not found.
 		return exceptions.HandlerErrorResponse(c, exceptions.ErrUserNotFound)
 	}
 
-	// Verify that the user ID from Redis matches the user ID from the database.
-	if user.ID != userID {
+	// Verify that the user ID from the token matches the user ID from the database.
+	if user.ID != verifyToken.UserID {
 		// Return a custom error response indicating a user ID mismatch.
 		return exceptions.HandlerErrorResponse(c, exceptions.ErrUserIDMismatch)
 	}
 
-	tokenResult, err := tokenService.StoreToken(c, ctx, user.ID, "access")
+	tokenResult, err := tokenService.RotateTokens(c, ctx, verifyToken)
 	if err != nil {
-		return err
+		if errors.Is(err, exceptions.ErrRefreshTokenReused) || errors.Is(err, exceptions.ErrRedisNotFound) {
+			c.ClearCookie("refresh_token")
+		}
+		return exceptions.HandlerErrorResponse(c, err)
 	}
 
 	accessToken := responses.LoginResponse{
@@ -1,6 +1,7 @@
 package authen
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/gofiber/fiber/v2"
@@ -59,12 +60,16 @@ func Register(c *fiber.Ctx) error {
 		return exceptions.HandlerErrorResponse(c, exceptions.ErrUserNotCreated)
 	}
 
-	message := "User registered successfully"
+	utils.RunInBackground("send verification email", func(ctx context.Context) error {
+		return utils.SendEmailVerification(ctx, appState, createUser)
+	})
+
+	message := "User registered successfully. Check your inbox to verify your email address"
 
 	return c.JSON(responses.SuccessResponse{
 		Status:  "success",
 		Message: &message,
-		Data:    createUser,
+		Data:    responses.NewUserResponse(createUser),
 	})
 
 }
@@ -0,0 +1,125 @@
+package authen
+
+import (
+	"context"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/starks97/alcohol-tracker-api/internal/exceptions"
+	"github.com/starks97/alcohol-tracker-api/internal/responses"
+	"github.com/starks97/alcohol-tracker-api/internal/services"
+	"github.com/starks97/alcohol-tracker-api/internal/state"
+	"github.com/starks97/alcohol-tracker-api/internal/utils"
+)
+
+// ListSessionsHandler returns the authenticated user's active logins with the device, IP
+// address and when each was created and last used.
+func ListSessionsHandler(c *fiber.Ctx) error {
+	userData := c.Locals("mdlData").(*responses.JwtMiddlewareResponse)
+	appState := c.Locals("appState").(*state.AppState)
+	ctx := c.Locals("ctx").(context.Context)
+	familyStore := utils.NewTokenFamilyStore(appState)
+
+	sessions, err := familyStore.ListSessions(ctx, userData.User.ID)
+	if err != nil {
+		return exceptions.HandlerErrorResponse(c, err)
+	}
+
+	currentID := currentSessionID(c, ctx, appState, familyStore)
+
+	sessionResponses := make([]responses.AuthSessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		sessionResponses = append(sessionResponses, responses.AuthSessionResponse{
+			ID:         session.ID,
+			UserAgent:  session.UserAgent,
+			IP:         session.IP,
+			CreatedAt:  session.CreatedAt,
+			LastUsedAt: session.LastUsedAt,
+			Current:    session.ID == currentID,
+		})
+	}
+
+	return c.JSON(responses.SuccessResponse{
+		Status: "success",
+		Data:   sessionResponses,
+	})
+}
+
+// RevokeSessionHandler signs out one of the authenticated user's logins, for example on a
+// lost phone. Its refresh token and current access token stop working immediately.
+func RevokeSessionHandler(c *fiber.Ctx) error {
+	userData := c.Locals("mdlData").(*responses.JwtMiddlewareResponse)
+	appState := c.Locals("appState").(*state.AppState)
+	ctx := c.Locals("ctx").(context.Context)
+	familyStore := utils.NewTokenFamilyStore(appState)
+
+	sessionID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrAuthSessionIDParse)
+	}
+
+	currentID := currentSessionID(c, ctx, appState, familyStore)
+
+	if err := familyStore.RevokeSession(ctx, userData.User.ID, sessionID); err != nil {
+		return exceptions.HandlerErrorResponse(c, err)
+	}
+
+	if sessionID == currentID {
+		c.ClearCookie("refresh_token")
+		c.ClearCookie("access_token")
+	}
+
+	message := "Session signed out successfully"
+	return c.JSON(responses.SuccessResponse{
+		Status:  "success",
+		Message: &message,
+	})
+}
+
+// LogOutAllHandler signs the authenticated user out of every device, including the one
+// making the request.
+func LogOutAllHandler(c *fiber.Ctx) error {
+	userData := c.Locals("mdlData").(*responses.JwtMiddlewareResponse)
+	appState := c.Locals("appState").(*state.AppState)
+	ctx := c.Locals("ctx").(context.Context)
+
+	if err := utils.NewTokenFamilyStore(appState).RevokeAll(ctx, userData.User.ID); err != nil {
+		return exceptions.HandlerErrorResponse(c, err)
+	}
+	// The sessions are revoked; the access token expires soon even if it cannot be removed.
+	if err := appState.Redis.Del(ctx, userData.AccessToken.String()).Err(); err != nil {
+		log.Println("Failed to remove access token:", err)
+	}
+
+	c.ClearCookie("refresh_token")
+	c.ClearCookie("access_token")
+
+	message := "You have been logged out of every device"
+	return c.JSON(responses.SuccessResponse{
+		Status:  "success",
+		Message: &message,
+	})
+}
+
+// currentSessionID returns the session of the refresh token cookie sent with the request,
+// or uuid.Nil when there is none.
+func currentSessionID(c *fiber.Ctx, ctx context.Context, appState *state.AppState, familyStore *utils.TokenFamilyStore) uuid.UUID {
+	reCookie := c.Cookies("refresh_token")
+	if reCookie == "" {
+		return uuid.Nil
+	}
+
+	tokenDetail, err := utils.VerifyJwtToken(appState, services.TokenTypeRefresh, reCookie)
+	if err != nil {
+		return uuid.Nil
+	}
+
+	familyID, err := familyStore.FamilyOf(ctx, tokenDetail.TokenUUID)
+	if err != nil {
+		log.Println("Failed to get current session:", err)
+		return uuid.Nil
+	}
+	return familyID
+}
@@ -0,0 +1,242 @@
+package authen
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/starks97/alcohol-tracker-api/internal/dtos"
+	"github.com/starks97/alcohol-tracker-api/internal/exceptions"
+	"github.com/starks97/alcohol-tracker-api/internal/repositories"
+	"github.com/starks97/alcohol-tracker-api/internal/responses"
+	"github.com/starks97/alcohol-tracker-api/internal/services"
+	"github.com/starks97/alcohol-tracker-api/internal/state"
+	"github.com/starks97/alcohol-tracker-api/internal/utils"
+)
+
+// EnrollTwoFactorHandler generates a new TOTP secret for the authenticated user and returns
+// it with its otpauth:// URI. 2FA stays off until the secret is confirmed with
+// EnableTwoFactorHandler; enrolling again replaces an unconfirmed secret.
+func EnrollTwoFactorHandler(c *fiber.Ctx) error {
+	appState := c.Locals("appState").(*state.AppState)
+	userData := c.Locals("mdlData").(*responses.JwtMiddlewareResponse)
+	userRepo := repositories.NewUserRepository(appState.DB)
+
+	user := userData.User
+
+	if user.Password == nil {
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrTwoFactorNoPassword)
+	}
+	if user.TwoFactorEnabled {
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrTwoFactorEnabled)
+	}
+
+	secret, err := services.GenerateTotpSecret()
+	if err != nil {
+		log.Println("Failed to generate TOTP secret:", err)
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrTwoFactorNotUpdated)
+	}
+
+	if err := userRepo.UpdateTwoFactor(user.ID, &secret, false); err != nil {
+		log.Println("Failed to store TOTP secret:", err)
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrTwoFactorNotUpdated)
+	}
+
+	message := "Scan the code with your authenticator app, then confirm it with a code to enable two-factor authentication"
+	return c.JSON(responses.SuccessResponse{
+		Status: "success",
+		Data: responses.TwoFactorEnrollmentResponse{
+			Secret:     secret,
+			OtpauthURI: services.TotpURI(appState.Config.TotpIssuer, user.Email, secret),
+		},
+		Message: &message,
+	})
+}
+
+// EnableTwoFactorHandler turns 2FA on once the user proves their authenticator app holds
+// the enrolled secret, and returns the recovery codes. They are never shown again.
+func EnableTwoFactorHandler(c *fiber.Ctx) error {
+	appState := c.Locals("appState").(*state.AppState)
+	userData := c.Locals("mdlData").(*responses.JwtMiddlewareResponse)
+	ctx := c.Locals("ctx").(context.Context)
+	userRepo := repositories.NewUserRepository(appState.DB)
+	recoveryRepo := repositories.NewRecoveryCodeRepository(appState.DB)
+
+	var twoFactorDataFromReq dtos.EnableTwoFactorDto
+
+	if err := c.BodyParser(&twoFactorDataFromReq); err != nil {
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrRequestBody)
+	}
+
+	if err := utils.ParseValidatorMessage(&twoFactorDataFromReq, appState.Validator); err != nil {
+		if validationErr, ok := err.(*utils.ValidationError); ok {
+			return exceptions.HandlerValidationErrorResponse(c, exceptions.ErrValidationFailed, validationErr.Errors)
+		}
+		return exceptions.HandlerErrorResponse(c, err)
+	}
+
+	user := userData.User
+
+	if user.TwoFactorEnabled {
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrTwoFactorEnabled)
+	}
+
+	if err := utils.VerifySecondFactor(ctx, appState, &user, twoFactorDataFromReq.Code, time.Now()); err != nil {
+		return exceptions.HandlerErrorResponse(c, err)
+	}
+
+	recoveryCodes, err := services.GenerateRecoveryCodes()
+	if err != nil {
+		log.Println("Failed to generate recovery codes:", err)
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrTwoFactorNotUpdated)
+	}
+
+	codeHashes := make([]string, 0, len(recoveryCodes))
+	for _, code := range recoveryCodes {
+		codeHashes = append(codeHashes, services.HashRecoveryCode(code))
+	}
+
+	if err := recoveryRepo.ReplaceRecoveryCodes(user.ID, codeHashes); err != nil {
+		log.Println("Failed to store recovery codes:", err)
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrTwoFactorNotUpdated)
+	}
+
+	if err := userRepo.UpdateTwoFactor(user.ID, user.TotpSecret, true); err != nil {
+		log.Println("Failed to enable two-factor authentication:", err)
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrTwoFactorNotUpdated)
+	}
+
+	message := "Two-factor authentication enabled. Store the recovery codes somewhere safe, they will not be shown again"
+	return c.JSON(responses.SuccessResponse{
+		Status:  "success",
+		Data:    responses.RecoveryCodesResponse{RecoveryCodes: recoveryCodes},
+		Message: &message,
+	})
+}
+
+// DisableTwoFactorHandler turns 2FA off and discards the secret and recovery codes. It
+// asks for both the password and a second factor, so that a stolen session is not enough.
+func DisableTwoFactorHandler(c *fiber.Ctx) error {
+	appState := c.Locals("appState").(*state.AppState)
+	userData := c.Locals("mdlData").(*responses.JwtMiddlewareResponse)
+	ctx := c.Locals("ctx").(context.Context)
+	userRepo := repositories.NewUserRepository(appState.DB)
+	recoveryRepo := repositories.NewRecoveryCodeRepository(appState.DB)
+
+	var twoFactorDataFromReq dtos.DisableTwoFactorDto
+
+	if err := c.BodyParser(&twoFactorDataFromReq); err != nil {
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrRequestBody)
+	}
+
+	if err := utils.ParseValidatorMessage(&twoFactorDataFromReq, appState.Validator); err != nil {
+		if validationErr, ok := err.(*utils.ValidationError); ok {
+			return exceptions.HandlerValidationErrorResponse(c, exceptions.ErrValidationFailed, validationErr.Errors)
+		}
+		return exceptions.HandlerErrorResponse(c, err)
+	}
+
+	user := userData.User
+
+	if !user.TwoFactorEnabled {
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrTwoFactorNotEnabled)
+	}
+
+	if user.Password == nil || bcrypt.CompareHashAndPassword([]byte(*user.Password), []byte(twoFactorDataFromReq.Password)) != nil {
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrInvalidCredentials)
+	}
+
+	if err := utils.VerifySecondFactor(ctx, appState, &user, twoFactorDataFromReq.Code, time.Now()); err != nil {
+		return exceptions.HandlerErrorResponse(c, err)
+	}
+
+	if err := userRepo.UpdateTwoFactor(user.ID, nil, false); err != nil {
+		log.Println("Failed to disable two-factor authentication:", err)
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrTwoFactorNotUpdated)
+	}
+
+	if err := recoveryRepo.DeleteRecoveryCodes(user.ID); err != nil {
+		log.Println("Failed to delete recovery codes:", err)
+	}
+
+	message := "Two-factor authentication disabled"
+	return c.JSON(responses.SuccessResponse{
+		Status:  "success",
+		Message: &message,
+	})
+}
+
+// VerifyTwoFactorHandler finishes a login started by LoginHandler for a user with 2FA
+// enabled. The challenge is redeemed once the code is accepted, and discarded after
+// utils.TwoFactorChallengeMaxAttempts wrong codes.
+func VerifyTwoFactorHandler(c *fiber.Ctx) error {
+	appState := c.Locals("appState").(*state.AppState)
+	ctx := c.Locals("ctx").(context.Context)
+	userRepo := repositories.NewUserRepository(appState.DB)
+	tokenService := utils.NewTokenService(appState)
+	challengeStore := utils.NewTwoFactorChallengeStore(appState)
+
+	var twoFactorDataFromReq dtos.VerifyTwoFactorDto
+
+	if err := c.BodyParser(&twoFactorDataFromReq); err != nil {
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrRequestBody)
+	}
+
+	if err := utils.ParseValidatorMessage(&twoFactorDataFromReq, appState.Validator); err != nil {
+		if validationErr, ok := err.(*utils.ValidationError); ok {
+			return exceptions.HandlerValidationErrorResponse(c, exceptions.ErrValidationFailed, validationErr.Errors)
+		}
+		return exceptions.HandlerErrorResponse(c, err)
+	}
+
+	challenge := twoFactorDataFromReq.ChallengeToken
+
+	value, err := challengeStore.Lookup(ctx, challenge)
+	if err != nil {
+		if errors.Is(err, exceptions.ErrRedisNotFound) {
+			return exceptions.HandlerErrorResponse(c, exceptions.ErrTwoFactorChallenge)
+		}
+		return exceptions.HandlerErrorResponse(c, err)
+	}
+
+	userID, err := uuid.Parse(value)
+	if err != nil {
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrTwoFactorChallenge)
+	}
+
+	user, err := userRepo.GetUserByID(userID)
+	if err != nil || !user.TwoFactorEnabled {
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrTwoFactorChallenge)
+	}
+
+	if err := utils.VerifySecondFactor(ctx, appState, user, twoFactorDataFromReq.Code, time.Now()); err != nil {
+		if errors.Is(err, exceptions.ErrTwoFactorCode) {
+			challengeStore.Fail(ctx, challenge, utils.TwoFactorChallengeMaxAttempts)
+		}
+		return exceptions.HandlerErrorResponse(c, err)
+	}
+
+	if _, err := challengeStore.Consume(ctx, challenge); err != nil {
+		if errors.Is(err, exceptions.ErrRedisNotFound) {
+			return exceptions.HandlerErrorResponse(c, exceptions.ErrTwoFactorChallenge)
+		}
+		return exceptions.HandlerErrorResponse(c, err)
+	}
+
+	tokenResult, err := tokenService.StoreToken(c, ctx, user.ID, "both")
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(responses.SuccessResponse{
+		Status: "success",
+		Data: responses.LoginResponse{
+			AccessToken: *tokenResult.Token,
+		},
+	})
+}
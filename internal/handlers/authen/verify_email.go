@@ -0,0 +1,97 @@
+package authen
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/starks97/alcohol-tracker-api/internal/dtos"
+	"github.com/starks97/alcohol-tracker-api/internal/exceptions"
+	"github.com/starks97/alcohol-tracker-api/internal/repositories"
+	"github.com/starks97/alcohol-tracker-api/internal/responses"
+	"github.com/starks97/alcohol-tracker-api/internal/state"
+	"github.com/starks97/alcohol-tracker-api/internal/utils"
+)
+
+// VerifyEmailHandler marks the email address of the user a verification token was sent to
+// as verified. Each token works once.
+func VerifyEmailHandler(c *fiber.Ctx) error {
+	appState := c.Locals("appState").(*state.AppState)
+	ctx := c.Locals("ctx").(context.Context)
+	userRepo := repositories.NewUserRepository(appState.DB)
+
+	var verifyDataFromReq dtos.VerifyEmailDto
+
+	if err := c.BodyParser(&verifyDataFromReq); err != nil {
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrRequestBody)
+	}
+
+	if err := utils.ParseValidatorMessage(&verifyDataFromReq, appState.Validator); err != nil {
+		if validationErr, ok := err.(*utils.ValidationError); ok {
+			return exceptions.HandlerValidationErrorResponse(c, exceptions.ErrValidationFailed, validationErr.Errors)
+		}
+		return exceptions.HandlerErrorResponse(c, err)
+	}
+
+	value, err := utils.NewEmailVerificationStore(appState).Consume(ctx, verifyDataFromReq.Token)
+	if err != nil {
+		if errors.Is(err, exceptions.ErrRedisNotFound) {
+			return exceptions.HandlerErrorResponse(c, exceptions.ErrEmailToken)
+		}
+		return exceptions.HandlerErrorResponse(c, err)
+	}
+
+	userID, email, ok := utils.ParseEmailVerification(value)
+	if !ok {
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrEmailToken)
+	}
+
+	updated, err := userRepo.MarkEmailVerified(userID, email)
+	if err != nil {
+		log.Println("Failed to verify email:", err)
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrUserNotUpdated)
+	}
+	if !updated {
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrEmailToken)
+	}
+
+	message := "Your email address has been verified"
+	return c.JSON(responses.SuccessResponse{
+		Status:  "success",
+		Message: &message,
+	})
+}
+
+// ResendVerificationEmailHandler sends the authenticated user a new verification link, at
+// most once every utils.EmailVerificationCooldown. Earlier links keep working until they
+// expire.
+func ResendVerificationEmailHandler(c *fiber.Ctx) error {
+	appState := c.Locals("appState").(*state.AppState)
+	userData := c.Locals("mdlData").(*responses.JwtMiddlewareResponse)
+	ctx := c.Locals("ctx").(context.Context)
+
+	if userData.User.EmailVerified {
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrEmailVerified)
+	}
+
+	reserved, err := utils.ReserveEmailVerification(ctx, appState, userData.User.ID)
+	if err != nil {
+		return exceptions.HandlerErrorResponse(c, err)
+	}
+	if !reserved {
+		c.Set(fiber.HeaderRetryAfter, "60")
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrEmailTooSoon)
+	}
+
+	if err := utils.SendEmailVerification(ctx, appState, &userData.User); err != nil {
+		return exceptions.HandlerErrorResponse(c, err)
+	}
+
+	message := "We sent a new verification link to " + userData.User.Email
+	return c.JSON(responses.SuccessResponse{
+		Status:  "success",
+		Message: &message,
+	})
+}
@@ -0,0 +1,93 @@
+package beverages
+
+import (
+	"errors"
+	"log"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+
+	"github.com/starks97/alcohol-tracker-api/internal/exceptions"
+	"github.com/starks97/alcohol-tracker-api/internal/repositories"
+	"github.com/starks97/alcohol-tracker-api/internal/responses"
+	"github.com/starks97/alcohol-tracker-api/internal/state"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 50
+)
+
+// SearchBeveragesHandler runs a full-text search over the beverage catalog.
+//
+// Supported query parameters:
+//   - q: the search text, matched against brand, name and style.
+//   - limit: maximum number of results, defaults to 20 and is capped at 50.
+func SearchBeveragesHandler(c *fiber.Ctx) error {
+	appState := c.Locals("appState").(*state.AppState)
+	beverageRepo := repositories.NewBeverageRepository(appState.DB)
+
+	limit := defaultSearchLimit
+	if rawLimit := c.Query("limit"); rawLimit != "" {
+		parsed, err := strconv.Atoi(rawLimit)
+		if err != nil || parsed <= 0 {
+			return exceptions.HandlerErrorResponse(c, exceptions.ErrInvalidQuery)
+		}
+		limit = min(parsed, maxSearchLimit)
+	}
+
+	beverages, err := beverageRepo.SearchBeverages(c.Query("q"), limit)
+	if err != nil {
+		log.Println("Failed to search beverages:", err)
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrDatabase)
+	}
+
+	beverageResponses := make([]responses.BeverageResponse, 0, len(beverages))
+	for i := range beverages {
+		beverageResponses = append(beverageResponses, responses.NewBeverageResponse(&beverages[i]))
+	}
+
+	return c.JSON(responses.SuccessResponse{
+		Status: "success",
+		Data:   beverageResponses,
+	})
+}
+
+// GetBeverageByBarcodeHandler looks up a catalog item by its EAN/UPC barcode.
+func GetBeverageByBarcodeHandler(c *fiber.Ctx) error {
+	appState := c.Locals("appState").(*state.AppState)
+	beverageRepo := repositories.NewBeverageRepository(appState.DB)
+
+	code := c.Params("code")
+	if !isBarcode(code) {
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrBarcodeInvalid)
+	}
+
+	beverage, err := beverageRepo.GetBeverageByBarcode(code)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return exceptions.HandlerErrorResponse(c, exceptions.ErrBeverageNotFound)
+		}
+		log.Println("Failed to get beverage by barcode:", err)
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrDatabase)
+	}
+
+	return c.JSON(responses.SuccessResponse{
+		Status: "success",
+		Data:   responses.NewBeverageResponse(beverage),
+	})
+}
+
+// isBarcode reports whether code looks like an EAN-8, UPC-A, EAN-13 or GTIN-14 barcode.
+func isBarcode(code string) bool {
+	if len(code) < 8 || len(code) > 14 {
+		return false
+	}
+	for _, char := range code {
+		if char < '0' || char > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,360 @@
+package drinks
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/starks97/alcohol-tracker-api/internal/dtos"
+	"github.com/starks97/alcohol-tracker-api/internal/entities"
+	"github.com/starks97/alcohol-tracker-api/internal/exceptions"
+	"github.com/starks97/alcohol-tracker-api/internal/repositories"
+	"github.com/starks97/alcohol-tracker-api/internal/responses"
+	"github.com/starks97/alcohol-tracker-api/internal/state"
+	"github.com/starks97/alcohol-tracker-api/internal/units"
+	"github.com/starks97/alcohol-tracker-api/internal/utils"
+)
+
+const (
+	defaultListLimit = 50
+	maxListLimit     = 100
+)
+
+// CreateDrinkHandler logs a new drink for the authenticated user.
+// When consumed_at is omitted the drink is recorded at the time of the request, and when
+// beverage_id is set any missing name, category, volume or ABV is taken from the catalog.
+// Calories are estimated from the ethanol and category unless the client sends them.
+// The drink is grouped into a drinking session, and if the user has set consumption limits,
+// the response includes their updated status.
+func CreateDrinkHandler(c *fiber.Ctx) error {
+	appState := c.Locals("appState").(*state.AppState)
+	userData := c.Locals("mdlData").(*responses.JwtMiddlewareResponse)
+	beverageRepo := repositories.NewBeverageRepository(appState.DB)
+
+	var drinkDataFromReq dtos.CreateDrinkDto
+
+	if err := c.BodyParser(&drinkDataFromReq); err != nil {
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrRequestBody)
+	}
+
+	if err := utils.ParseValidatorMessage(&drinkDataFromReq, appState.Validator); err != nil {
+		if validationErr, ok := err.(*utils.ValidationError); ok {
+			return exceptions.HandlerValidationErrorResponse(c, exceptions.ErrValidationFailed, validationErr.Errors)
+		}
+		return exceptions.HandlerErrorResponse(c, err)
+	}
+
+	consumedAt := time.Now()
+	if drinkDataFromReq.ConsumedAt != nil {
+		consumedAt = *drinkDataFromReq.ConsumedAt
+	}
+
+	drink := &entities.DrinkEntry{
+		UserID:       userData.User.ID,
+		BeverageID:   drinkDataFromReq.BeverageID,
+		BeverageName: drinkDataFromReq.BeverageName,
+		Category:     drinkDataFromReq.Category,
+		VolumeMl:     drinkDataFromReq.VolumeMl,
+		ConsumedAt:   consumedAt,
+		Price:        drinkDataFromReq.Price,
+		Currency:     drinkDataFromReq.Currency,
+		Notes:        drinkDataFromReq.Notes,
+		ImageRef:     drinkDataFromReq.ImageRef,
+	}
+	if drinkDataFromReq.ABV != nil {
+		drink.ABV = *drinkDataFromReq.ABV
+	}
+
+	if drinkDataFromReq.BeverageID != nil {
+		beverage, err := beverageRepo.GetBeverageByID(*drinkDataFromReq.BeverageID)
+		if err != nil {
+			return exceptions.HandlerErrorResponse(c, beverageLookupError(err))
+		}
+		applyBeverageDefaults(drink, beverage, drinkDataFromReq.ABV == nil)
+
+		if drink.VolumeMl == 0 {
+			return exceptions.HandlerValidationErrorResponse(c, exceptions.ErrValidationFailed, map[string][]string{
+				"VolumeMl": {"Please provide a value for VolumeMl, this beverage has no default serving size."},
+			})
+		}
+	}
+
+	if drinkDataFromReq.Calories != nil {
+		drink.Calories = drinkDataFromReq.Calories
+		drink.CaloriesProvided = true
+	} else {
+		estimateCalories(drink)
+	}
+
+	// The drink is saved together with its session, so that neither is left half written.
+	err := appState.DB.Transaction(func(tx *gorm.DB) error {
+		if _, err := repositories.NewDrinkRepository(tx).CreateDrink(drink); err != nil {
+			return err
+		}
+		return utils.AssignDrinkSession(appState, tx, &userData.User, drink)
+	})
+	if err != nil {
+		log.Println("Failed to create drink:", err)
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrDrinkNotCreated)
+	}
+
+	// The drink is already saved, so failing to evaluate the limits is logged rather than
+	// returned.
+	_, limitStatus, err := utils.EvaluateUserLimits(appState, &userData.User, time.Now())
+	if err != nil {
+		log.Println("Failed to evaluate limits:", err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(responses.SuccessResponse{
+		Status: "success",
+		Data: responses.DrinkCreatedResponse{
+			DrinkResponse: responses.NewDrinkResponse(drink, drinkLocale(appState, userData)),
+			LimitStatus:   responses.NewLimitStatusResponse(limitStatus),
+		},
+	})
+}
+
+// GetDrinkHandler returns a single drink owned by the authenticated user.
+func GetDrinkHandler(c *fiber.Ctx) error {
+	appState := c.Locals("appState").(*state.AppState)
+	userData := c.Locals("mdlData").(*responses.JwtMiddlewareResponse)
+	drinkRepo := repositories.NewDrinkRepository(appState.DB)
+
+	drinkID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrDrinkIDParse)
+	}
+
+	drink, err := drinkRepo.GetDrinkByID(userData.User.ID, drinkID)
+	if err != nil {
+		return exceptions.HandlerErrorResponse(c, drinkLookupError(err))
+	}
+
+	return c.JSON(responses.SuccessResponse{
+		Status: "success",
+		Data:   responses.NewDrinkResponse(drink, drinkLocale(appState, userData)),
+	})
+}
+
+// ListDrinksHandler returns the authenticated user's drinks, most recent first.
+//
+// Supported query parameters:
+//   - from, to: RFC 3339 timestamps bounding consumed_at (inclusive).
+//   - limit: page size, defaults to 50 and is capped at 100.
+//   - offset: number of drinks to skip.
+func ListDrinksHandler(c *fiber.Ctx) error {
+	appState := c.Locals("appState").(*state.AppState)
+	userData := c.Locals("mdlData").(*responses.JwtMiddlewareResponse)
+	drinkRepo := repositories.NewDrinkRepository(appState.DB)
+
+	query, err := utils.ParseListQuery(c, defaultListLimit, maxListLimit)
+	if err != nil {
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrInvalidQuery)
+	}
+
+	drinks, total, err := drinkRepo.ListDrinks(userData.User.ID, repositories.DrinkFilter(query))
+	if err != nil {
+		log.Println("Failed to list drinks:", err)
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrDatabase)
+	}
+
+	locale := drinkLocale(appState, userData)
+	drinkResponses := make([]responses.DrinkResponse, 0, len(drinks))
+	for i := range drinks {
+		drinkResponses = append(drinkResponses, responses.NewDrinkResponse(&drinks[i], locale))
+	}
+
+	return c.JSON(responses.SuccessResponse{
+		Status: "success",
+		Data: responses.DrinkListResponse{
+			Drinks: drinkResponses,
+			Total:  total,
+			Limit:  query.Limit,
+			Offset: query.Offset,
+		},
+	})
+}
+
+// UpdateDrinkHandler applies a partial update to a drink owned by the authenticated user.
+func UpdateDrinkHandler(c *fiber.Ctx) error {
+	appState := c.Locals("appState").(*state.AppState)
+	userData := c.Locals("mdlData").(*responses.JwtMiddlewareResponse)
+	drinkRepo := repositories.NewDrinkRepository(appState.DB)
+	beverageRepo := repositories.NewBeverageRepository(appState.DB)
+
+	drinkID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrDrinkIDParse)
+	}
+
+	var drinkDataFromReq dtos.UpdateDrinkDto
+
+	if err := c.BodyParser(&drinkDataFromReq); err != nil {
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrRequestBody)
+	}
+
+	if err := utils.ParseValidatorMessage(&drinkDataFromReq, appState.Validator); err != nil {
+		if validationErr, ok := err.(*utils.ValidationError); ok {
+			return exceptions.HandlerValidationErrorResponse(c, exceptions.ErrValidationFailed, validationErr.Errors)
+		}
+		return exceptions.HandlerErrorResponse(c, err)
+	}
+
+	drink, err := drinkRepo.GetDrinkByID(userData.User.ID, drinkID)
+	if err != nil {
+		return exceptions.HandlerErrorResponse(c, drinkLookupError(err))
+	}
+
+	if drinkDataFromReq.BeverageID != nil {
+		if _, err := beverageRepo.GetBeverageByID(*drinkDataFromReq.BeverageID); err != nil {
+			return exceptions.HandlerErrorResponse(c, beverageLookupError(err))
+		}
+		drink.BeverageID = drinkDataFromReq.BeverageID
+	}
+	if drinkDataFromReq.BeverageName != nil {
+		drink.BeverageName = *drinkDataFromReq.BeverageName
+	}
+	if drinkDataFromReq.Category != nil {
+		drink.Category = *drinkDataFromReq.Category
+	}
+	if drinkDataFromReq.VolumeMl != nil {
+		drink.VolumeMl = *drinkDataFromReq.VolumeMl
+	}
+	if drinkDataFromReq.ABV != nil {
+		drink.ABV = *drinkDataFromReq.ABV
+	}
+	if drinkDataFromReq.ConsumedAt != nil {
+		drink.ConsumedAt = *drinkDataFromReq.ConsumedAt
+	}
+	if drinkDataFromReq.Price != nil {
+		drink.Price = drinkDataFromReq.Price
+	}
+	if drinkDataFromReq.Currency != nil {
+		drink.Currency = drinkDataFromReq.Currency
+	}
+	if drink.Price != nil && drink.Currency == nil {
+		return exceptions.HandlerValidationErrorResponse(c, exceptions.ErrValidationFailed, map[string][]string{
+			"Currency": {"Please provide a value for Currency together with Price."},
+		})
+	}
+	if drinkDataFromReq.Calories != nil {
+		drink.Calories = drinkDataFromReq.Calories
+		drink.CaloriesProvided = true
+	} else if !drink.CaloriesProvided {
+		estimateCalories(drink)
+	}
+	if drinkDataFromReq.Notes != nil {
+		drink.Notes = drinkDataFromReq.Notes
+	}
+	if drinkDataFromReq.ImageRef != nil {
+		drink.ImageRef = drinkDataFromReq.ImageRef
+	}
+
+	// The consumption time may have moved the drink to another session.
+	err = appState.DB.Transaction(func(tx *gorm.DB) error {
+		if _, err := repositories.NewDrinkRepository(tx).UpdateDrink(drink); err != nil {
+			return err
+		}
+		return utils.AssignDrinkSession(appState, tx, &userData.User, drink)
+	})
+	if err != nil {
+		log.Println("Failed to update drink:", err)
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrDrinkNotUpdated)
+	}
+
+	return c.JSON(responses.SuccessResponse{
+		Status: "success",
+		Data:   responses.NewDrinkResponse(drink, drinkLocale(appState, userData)),
+	})
+}
+
+// DeleteDrinkHandler removes a drink owned by the authenticated user.
+func DeleteDrinkHandler(c *fiber.Ctx) error {
+	appState := c.Locals("appState").(*state.AppState)
+	userData := c.Locals("mdlData").(*responses.JwtMiddlewareResponse)
+	drinkRepo := repositories.NewDrinkRepository(appState.DB)
+
+	drinkID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrDrinkIDParse)
+	}
+
+	drink, err := drinkRepo.GetDrinkByID(userData.User.ID, drinkID)
+	if err != nil {
+		return exceptions.HandlerErrorResponse(c, drinkLookupError(err))
+	}
+
+	// Removing the drink may leave its session empty or split it in two.
+	err = appState.DB.Transaction(func(tx *gorm.DB) error {
+		if err := repositories.NewDrinkRepository(tx).DeleteDrink(userData.User.ID, drinkID); err != nil {
+			return err
+		}
+		if drink.SessionID == nil {
+			return nil
+		}
+		return utils.RefreshSession(appState, tx, &userData.User, *drink.SessionID)
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return exceptions.HandlerErrorResponse(c, exceptions.ErrDrinkNotFound)
+		}
+		log.Println("Failed to delete drink:", err)
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrDrinkNotDeleted)
+	}
+
+	message := "Drink deleted successfully"
+	return c.JSON(responses.SuccessResponse{
+		Status:  "success",
+		Message: &message,
+	})
+}
+
+// drinkLocale returns the standard drink locale of the authenticated user.
+func drinkLocale(appState *state.AppState, userData *responses.JwtMiddlewareResponse) string {
+	return units.ResolveLocale(userData.User.DrinkLocale, appState.Config.DefaultDrinkLocale)
+}
+
+// drinkLookupError maps a repository lookup error to the error returned to the client.
+func drinkLookupError(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return exceptions.ErrDrinkNotFound
+	}
+	log.Println("Failed to get drink:", err)
+	return exceptions.ErrDatabase
+}
+
+// beverageLookupError maps a catalog lookup error to the error returned to the client.
+func beverageLookupError(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return exceptions.ErrBeverageNotFound
+	}
+	log.Println("Failed to get beverage:", err)
+	return exceptions.ErrDatabase
+}
+
+// applyBeverageDefaults fills the fields the client left empty with the catalog values.
+func applyBeverageDefaults(drink *entities.DrinkEntry, beverage *entities.Beverage, useDefaultABV bool) {
+	if drink.BeverageName == "" {
+		drink.BeverageName = beverage.Brand + " " + beverage.Name
+	}
+	if drink.Category == "" {
+		drink.Category = beverage.Category
+	}
+	if drink.VolumeMl == 0 && len(beverage.ServingSizesMl) > 0 {
+		drink.VolumeMl = beverage.ServingSizesMl[0]
+	}
+	if useDefaultABV {
+		drink.ABV = beverage.DefaultABV
+	}
+}
+
+// estimateCalories sets the drink's calories from its ethanol and category.
+func estimateCalories(drink *entities.DrinkEntry) {
+	calories := units.EstimateCalories(drink.VolumeMl, drink.ABV, drink.Category)
+	drink.Calories = &calories
+	drink.CaloriesProvided = false
+}
@@ -0,0 +1,103 @@
+package me
+
+import (
+	"log"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/starks97/alcohol-tracker-api/internal/dtos"
+	"github.com/starks97/alcohol-tracker-api/internal/exceptions"
+	"github.com/starks97/alcohol-tracker-api/internal/repositories"
+	"github.com/starks97/alcohol-tracker-api/internal/responses"
+	"github.com/starks97/alcohol-tracker-api/internal/services"
+	"github.com/starks97/alcohol-tracker-api/internal/state"
+	"github.com/starks97/alcohol-tracker-api/internal/units"
+	"github.com/starks97/alcohol-tracker-api/internal/utils"
+)
+
+const (
+	// bacLookback is how far back drinks are considered; anything older has long been eliminated.
+	bacLookback = 24 * time.Hour
+
+	defaultBacCurveHours = 6
+)
+
+// BacHandler estimates the authenticated user's current blood alcohol concentration
+// from their logged drinks and returns the projected curve for the next hours.
+//
+// The user's weight and sex come from their profile and can be overridden per request.
+//
+// Supported query parameters:
+//   - hours: length of the projected per-minute curve, 1 to 24 (defaults to 6).
+//   - weight_kg: body weight in kilograms.
+//   - sex: "male" or "female".
+func BacHandler(c *fiber.Ctx) error {
+	appState := c.Locals("appState").(*state.AppState)
+	userData := c.Locals("mdlData").(*responses.JwtMiddlewareResponse)
+	drinkRepo := repositories.NewDrinkRepository(appState.DB)
+
+	query := dtos.BacQueryDto{Hours: defaultBacCurveHours}
+
+	if err := c.QueryParser(&query); err != nil {
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrInvalidQuery)
+	}
+
+	if err := utils.ParseValidatorMessage(&query, appState.Validator); err != nil {
+		if validationErr, ok := err.(*utils.ValidationError); ok {
+			return exceptions.HandlerValidationErrorResponse(c, exceptions.ErrValidationFailed, validationErr.Errors)
+		}
+		return exceptions.HandlerErrorResponse(c, err)
+	}
+
+	user := userData.User
+	if query.WeightKg == 0 && user.WeightKg != nil {
+		query.WeightKg = *user.WeightKg
+	}
+	if query.Sex == "" && user.Sex != nil {
+		query.Sex = *user.Sex
+	}
+	if query.WeightKg == 0 || query.Sex == "" {
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrProfileIncomplete)
+	}
+
+	now := time.Now()
+	from := now.Add(-bacLookback)
+	to := now.Add(time.Duration(query.Hours) * time.Hour)
+
+	drinks, _, err := drinkRepo.ListDrinks(userData.User.ID, repositories.DrinkFilter{From: &from, To: &to})
+	if err != nil {
+		log.Println("Failed to list drinks:", err)
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrDatabase)
+	}
+
+	consumedGrams := 0.0
+	bacDrinks := make([]services.BacDrink, 0, len(drinks))
+	for _, drink := range drinks {
+		ethanolGrams := units.EthanolGrams(drink.VolumeMl, drink.ABV)
+		if !drink.ConsumedAt.After(now) {
+			consumedGrams += ethanolGrams
+		}
+		bacDrinks = append(bacDrinks, services.BacDrink{
+			EthanolGrams: ethanolGrams,
+			ConsumedAt:   drink.ConsumedAt,
+		})
+	}
+
+	profile := services.BacProfile{
+		WeightKg:        query.WeightKg,
+		Sex:             query.Sex,
+		EliminationRate: appState.Config.BacEliminationRate,
+	}
+
+	estimate, err := services.EstimateBac(bacDrinks, profile, now, query.Hours)
+	if err != nil {
+		log.Println("Failed to estimate BAC:", err)
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrBacEstimation)
+	}
+
+	return c.JSON(responses.SuccessResponse{
+		Status: "success",
+		Data:   responses.NewBacResponse(estimate, profile.EliminationRate, consumedGrams, units.ResolveLocale(user.DrinkLocale, appState.Config.DefaultDrinkLocale)),
+	})
+}
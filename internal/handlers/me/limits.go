@@ -0,0 +1,80 @@
+package me
+
+import (
+	"log"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/starks97/alcohol-tracker-api/internal/dtos"
+	"github.com/starks97/alcohol-tracker-api/internal/entities"
+	"github.com/starks97/alcohol-tracker-api/internal/exceptions"
+	"github.com/starks97/alcohol-tracker-api/internal/repositories"
+	"github.com/starks97/alcohol-tracker-api/internal/responses"
+	"github.com/starks97/alcohol-tracker-api/internal/state"
+	"github.com/starks97/alcohol-tracker-api/internal/utils"
+)
+
+// GetLimitsHandler returns the authenticated user's consumption limits together with
+// the current remaining allowances, exceeded flags and streak.
+func GetLimitsHandler(c *fiber.Ctx) error {
+	appState := c.Locals("appState").(*state.AppState)
+	userData := c.Locals("mdlData").(*responses.JwtMiddlewareResponse)
+
+	limit, status, err := utils.EvaluateUserLimits(appState, &userData.User, time.Now())
+	if err != nil {
+		log.Println("Failed to evaluate limits:", err)
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrDatabase)
+	}
+
+	return c.JSON(responses.SuccessResponse{
+		Status: "success",
+		Data:   responses.NewLimitsResponse(limit, status),
+	})
+}
+
+// SetLimitsHandler replaces the authenticated user's consumption limits. Limits left out
+// of the request are cleared.
+func SetLimitsHandler(c *fiber.Ctx) error {
+	appState := c.Locals("appState").(*state.AppState)
+	userData := c.Locals("mdlData").(*responses.JwtMiddlewareResponse)
+	limitRepo := repositories.NewLimitRepository(appState.DB)
+
+	var limitsDataFromReq dtos.SetLimitsDto
+
+	if err := c.BodyParser(&limitsDataFromReq); err != nil {
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrRequestBody)
+	}
+
+	if err := utils.ParseValidatorMessage(&limitsDataFromReq, appState.Validator); err != nil {
+		if validationErr, ok := err.(*utils.ValidationError); ok {
+			return exceptions.HandlerValidationErrorResponse(c, exceptions.ErrValidationFailed, validationErr.Errors)
+		}
+		return exceptions.HandlerErrorResponse(c, err)
+	}
+
+	limit := &entities.DrinkingLimit{
+		UserID:                 userData.User.ID,
+		DailyStandardDrinks:    limitsDataFromReq.DailyStandardDrinks,
+		WeeklyStandardDrinks:   limitsDataFromReq.WeeklyStandardDrinks,
+		AlcoholFreeDaysPerWeek: limitsDataFromReq.AlcoholFreeDaysPerWeek,
+	}
+
+	if _, err := limitRepo.SaveLimit(limit); err != nil {
+		log.Println("Failed to save limits:", err)
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrLimitsNotUpdated)
+	}
+
+	limit, status, err := utils.EvaluateUserLimits(appState, &userData.User, time.Now())
+	if err != nil {
+		log.Println("Failed to evaluate limits:", err)
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrDatabase)
+	}
+
+	message := "Limits updated successfully"
+	return c.JSON(responses.SuccessResponse{
+		Status:  "success",
+		Data:    responses.NewLimitsResponse(limit, status),
+		Message: &message,
+	})
+}
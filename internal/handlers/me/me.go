@@ -1 +1,171 @@
 package me
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/starks97/alcohol-tracker-api/internal/dtos"
+	"github.com/starks97/alcohol-tracker-api/internal/exceptions"
+	"github.com/starks97/alcohol-tracker-api/internal/repositories"
+	"github.com/starks97/alcohol-tracker-api/internal/responses"
+	"github.com/starks97/alcohol-tracker-api/internal/state"
+	"github.com/starks97/alcohol-tracker-api/internal/units"
+	"github.com/starks97/alcohol-tracker-api/internal/utils"
+)
+
+// GetMeHandler returns the profile of the authenticated user.
+func GetMeHandler(c *fiber.Ctx) error {
+	userData := c.Locals("mdlData").(*responses.JwtMiddlewareResponse)
+
+	return c.JSON(responses.SuccessResponse{
+		Status: "success",
+		Data:   responses.NewUserResponse(&userData.User),
+	})
+}
+
+// UpdateMeHandler applies a partial update to the authenticated user's profile.
+// A new password is validated, hashed with bcrypt and only accepted from users with a
+// verified email address, together with the current password when the account already
+// has one.
+func UpdateMeHandler(c *fiber.Ctx) error {
+	appState := c.Locals("appState").(*state.AppState)
+	userData := c.Locals("mdlData").(*responses.JwtMiddlewareResponse)
+	userRepo := repositories.NewUserRepository(appState.DB)
+
+	var userDataFromReq dtos.UpdateUserDto
+
+	if err := c.BodyParser(&userDataFromReq); err != nil {
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrRequestBody)
+	}
+
+	if err := utils.ParseValidatorMessage(&userDataFromReq, appState.Validator); err != nil {
+		if validationErr, ok := err.(*utils.ValidationError); ok {
+			return exceptions.HandlerValidationErrorResponse(c, exceptions.ErrValidationFailed, validationErr.Errors)
+		}
+		return exceptions.HandlerErrorResponse(c, err)
+	}
+
+	user := userData.User
+
+	if userDataFromReq.Name != nil {
+		user.Name = *userDataFromReq.Name
+	}
+	if userDataFromReq.ProfilePicture != nil {
+		user.ProfilePicture = userDataFromReq.ProfilePicture
+	}
+
+	// Measurements in the request use the unit system sent alongside them, falling back
+	// to the stored preference, and are always persisted in metric.
+	unitSystem := units.UnitSystemOrDefault(user.UnitSystem)
+	if userDataFromReq.UnitSystem != nil {
+		unitSystem = *userDataFromReq.UnitSystem
+		user.UnitSystem = unitSystem
+	}
+	measurementErrors := map[string][]string{}
+	if userDataFromReq.Weight != nil {
+		weightKg := units.WeightToKg(*userDataFromReq.Weight, unitSystem)
+		if !units.ValidWeightKg(weightKg) {
+			measurementErrors["Weight"] = []string{fmt.Sprintf("Weight must be at most %v kg (%v lb).",
+				units.MaxWeightKg, units.WeightFromKg(units.MaxWeightKg, units.UnitSystemImperial))}
+		}
+		user.WeightKg = &weightKg
+	}
+	if userDataFromReq.Height != nil {
+		heightCm := units.HeightToCm(*userDataFromReq.Height, unitSystem)
+		if !units.ValidHeightCm(heightCm) {
+			measurementErrors["Height"] = []string{fmt.Sprintf("Height must be at most %v cm (%v in).",
+				units.MaxHeightCm, units.HeightFromCm(units.MaxHeightCm, units.UnitSystemImperial))}
+		}
+		user.HeightCm = &heightCm
+	}
+	if len(measurementErrors) > 0 {
+		return exceptions.HandlerValidationErrorResponse(c, exceptions.ErrValidationFailed, measurementErrors)
+	}
+	if userDataFromReq.Sex != nil {
+		user.Sex = userDataFromReq.Sex
+	}
+	if userDataFromReq.DrinkLocale != nil {
+		user.DrinkLocale = userDataFromReq.DrinkLocale
+	}
+	if userDataFromReq.TimeZone != nil {
+		user.TimeZone = userDataFromReq.TimeZone
+	}
+	if userDataFromReq.DateOfBirth != nil {
+		dateOfBirth, err := time.Parse(time.DateOnly, *userDataFromReq.DateOfBirth)
+		if err != nil || !dateOfBirth.Before(time.Now()) {
+			return exceptions.HandlerValidationErrorResponse(c, exceptions.ErrValidationFailed, map[string][]string{
+				"DateOfBirth": {"DateOfBirth must be a date in the past."},
+			})
+		}
+		user.DateOfBirth = &dateOfBirth
+	}
+
+	if userDataFromReq.Password != nil {
+		// Setting a password on an unverified address would let whoever registered it
+		// keep a way into the account.
+		if !user.EmailVerified {
+			return exceptions.HandlerErrorResponse(c, exceptions.ErrEmailNotVerified)
+		}
+		if user.Password != nil {
+			if userDataFromReq.CurrentPassword == nil {
+				return exceptions.HandlerErrorResponse(c, exceptions.ErrPasswordRequired)
+			}
+			if err := bcrypt.CompareHashAndPassword([]byte(*user.Password), []byte(*userDataFromReq.CurrentPassword)); err != nil {
+				return exceptions.HandlerErrorResponse(c, exceptions.ErrInvalidCredentials)
+			}
+		}
+
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(*userDataFromReq.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return exceptions.HandlerErrorResponse(c, err)
+		}
+		passwordFromBytes := string(hashedPassword)
+		user.Password = &passwordFromBytes
+	}
+
+	updatedUser, err := userRepo.UpdateUser(&user)
+	if err != nil {
+		log.Println("Failed to update user:", err)
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrUserNotUpdated)
+	}
+
+	return c.JSON(responses.SuccessResponse{
+		Status: "success",
+		Data:   responses.NewUserResponse(updatedUser),
+	})
+}
+
+// DeleteMeHandler deletes the authenticated user's account together with their drink log,
+// revokes the current tokens and clears the authentication cookies.
+func DeleteMeHandler(c *fiber.Ctx) error {
+	appState := c.Locals("appState").(*state.AppState)
+	userData := c.Locals("mdlData").(*responses.JwtMiddlewareResponse)
+	ctx := c.Locals("ctx").(context.Context)
+	userRepo := repositories.NewUserRepository(appState.DB)
+
+	if err := userRepo.DeleteUser(userData.User.ID); err != nil {
+		log.Println("Failed to delete user:", err)
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrUserNotDeleted)
+	}
+
+	if err := utils.NewTokenFamilyStore(appState).RevokeAll(ctx, userData.User.ID); err != nil {
+		log.Println("Failed to revoke sessions:", err)
+	}
+	if err := appState.Redis.Del(ctx, userData.AccessToken.String()).Err(); err != nil {
+		log.Println("Failed to remove access token:", err)
+	}
+
+	c.ClearCookie("refresh_token")
+	c.ClearCookie("access_token")
+
+	message := "Your account has been deleted"
+	return c.JSON(responses.SuccessResponse{
+		Status:  "success",
+		Message: &message,
+	})
+}
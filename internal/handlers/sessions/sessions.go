@@ -0,0 +1,255 @@
+package sessions
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/starks97/alcohol-tracker-api/internal/dtos"
+	"github.com/starks97/alcohol-tracker-api/internal/entities"
+	"github.com/starks97/alcohol-tracker-api/internal/exceptions"
+	"github.com/starks97/alcohol-tracker-api/internal/repositories"
+	"github.com/starks97/alcohol-tracker-api/internal/responses"
+	"github.com/starks97/alcohol-tracker-api/internal/state"
+	"github.com/starks97/alcohol-tracker-api/internal/units"
+	"github.com/starks97/alcohol-tracker-api/internal/utils"
+)
+
+const (
+	defaultListLimit = 20
+	maxListLimit     = 100
+)
+
+// ListSessionsHandler returns the authenticated user's drinking sessions, most recent first.
+//
+// Supported query parameters:
+//   - from, to: RFC 3339 timestamps bounding started_at (inclusive).
+//   - limit: page size, defaults to 20 and is capped at 100.
+//   - offset: number of sessions to skip.
+func ListSessionsHandler(c *fiber.Ctx) error {
+	appState := c.Locals("appState").(*state.AppState)
+	userData := c.Locals("mdlData").(*responses.JwtMiddlewareResponse)
+	sessionRepo := repositories.NewSessionRepository(appState.DB)
+
+	query, err := utils.ParseListQuery(c, defaultListLimit, maxListLimit)
+	if err != nil {
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrInvalidQuery)
+	}
+
+	sessions, total, err := sessionRepo.ListSessions(userData.User.ID, repositories.SessionFilter(query))
+	if err != nil {
+		log.Println("Failed to list drinking sessions:", err)
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrDatabase)
+	}
+
+	now := time.Now()
+	locale := sessionLocale(appState, userData)
+	sessionResponses := make([]responses.SessionResponse, 0, len(sessions))
+	for i := range sessions {
+		active := utils.SessionActive(&sessions[i], appState.Config.SessionGap, now)
+		sessionResponses = append(sessionResponses, responses.NewSessionResponse(&sessions[i], locale, active))
+	}
+
+	return c.JSON(responses.SuccessResponse{
+		Status: "success",
+		Data: responses.SessionListResponse{
+			Sessions: sessionResponses,
+			Total:    total,
+			Limit:    query.Limit,
+			Offset:   query.Offset,
+		},
+	})
+}
+
+// GetSessionHandler returns a drinking session owned by the authenticated user together
+// with its drinks, in the order they were consumed.
+func GetSessionHandler(c *fiber.Ctx) error {
+	appState := c.Locals("appState").(*state.AppState)
+	userData := c.Locals("mdlData").(*responses.JwtMiddlewareResponse)
+	sessionRepo := repositories.NewSessionRepository(appState.DB)
+
+	sessionID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrSessionIDParse)
+	}
+
+	session, err := sessionRepo.GetSessionByID(userData.User.ID, sessionID)
+	if err != nil {
+		return exceptions.HandlerErrorResponse(c, sessionLookupError(err))
+	}
+
+	drinks, err := sessionRepo.ListSessionDrinks(userData.User.ID, sessionID)
+	if err != nil {
+		log.Println("Failed to list session drinks:", err)
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrDatabase)
+	}
+
+	locale := sessionLocale(appState, userData)
+	drinkResponses := make([]responses.DrinkResponse, 0, len(drinks))
+	for i := range drinks {
+		drinkResponses = append(drinkResponses, responses.NewDrinkResponse(&drinks[i], locale))
+	}
+
+	return c.JSON(responses.SuccessResponse{
+		Status: "success",
+		Data: responses.SessionDetailResponse{
+			SessionResponse: responses.NewSessionResponse(session, locale, utils.SessionActive(session, appState.Config.SessionGap, time.Now())),
+			Drinks:          drinkResponses,
+		},
+	})
+}
+
+// StartSessionHandler starts a manual drinking session, such as a night out. Every drink
+// consumed until the session is ended is grouped into it. Only one manual session can
+// run at a time, which the database enforces with a unique index.
+func StartSessionHandler(c *fiber.Ctx) error {
+	appState := c.Locals("appState").(*state.AppState)
+	userData := c.Locals("mdlData").(*responses.JwtMiddlewareResponse)
+	sessionRepo := repositories.NewSessionRepository(appState.DB)
+
+	var sessionDataFromReq dtos.StartSessionDto
+
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&sessionDataFromReq); err != nil {
+			return exceptions.HandlerErrorResponse(c, exceptions.ErrRequestBody)
+		}
+	}
+
+	if err := utils.ParseValidatorMessage(&sessionDataFromReq, appState.Validator); err != nil {
+		if validationErr, ok := err.(*utils.ValidationError); ok {
+			return exceptions.HandlerValidationErrorResponse(c, exceptions.ErrValidationFailed, validationErr.Errors)
+		}
+		return exceptions.HandlerErrorResponse(c, err)
+	}
+
+	if _, err := sessionRepo.GetRunningSession(userData.User.ID); err == nil {
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrSessionRunning)
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Println("Failed to get running session:", err)
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrDatabase)
+	}
+
+	session := &entities.DrinkingSession{
+		UserID:    userData.User.ID,
+		StartedAt: time.Now(),
+		Manual:    true,
+		Location:  sessionDataFromReq.Location,
+	}
+
+	if _, err := sessionRepo.CreateSession(session); err != nil {
+		// Another request started a session since the check above.
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return exceptions.HandlerErrorResponse(c, exceptions.ErrSessionRunning)
+		}
+		log.Println("Failed to create drinking session:", err)
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrSessionNotCreated)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(responses.SuccessResponse{
+		Status: "success",
+		Data:   responses.NewSessionResponse(session, sessionLocale(appState, userData), true),
+	})
+}
+
+// UpdateSessionHandler sets or clears the location label of a drinking session, such as
+// "Joe's birthday" or a venue name. Automatic sessions can be labelled too.
+func UpdateSessionHandler(c *fiber.Ctx) error {
+	appState := c.Locals("appState").(*state.AppState)
+	userData := c.Locals("mdlData").(*responses.JwtMiddlewareResponse)
+	sessionRepo := repositories.NewSessionRepository(appState.DB)
+
+	sessionID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrSessionIDParse)
+	}
+
+	var sessionDataFromReq dtos.UpdateSessionDto
+
+	if err := c.BodyParser(&sessionDataFromReq); err != nil {
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrRequestBody)
+	}
+
+	if err := utils.ParseValidatorMessage(&sessionDataFromReq, appState.Validator); err != nil {
+		if validationErr, ok := err.(*utils.ValidationError); ok {
+			return exceptions.HandlerValidationErrorResponse(c, exceptions.ErrValidationFailed, validationErr.Errors)
+		}
+		return exceptions.HandlerErrorResponse(c, err)
+	}
+
+	var location *string
+	if sessionDataFromReq.Location != "" {
+		location = &sessionDataFromReq.Location
+	}
+
+	if err := sessionRepo.UpdateSessionLocation(userData.User.ID, sessionID, location); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return exceptions.HandlerErrorResponse(c, exceptions.ErrSessionNotFound)
+		}
+		log.Println("Failed to update drinking session:", err)
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrSessionNotUpdated)
+	}
+
+	session, err := sessionRepo.GetSessionByID(userData.User.ID, sessionID)
+	if err != nil {
+		return exceptions.HandlerErrorResponse(c, sessionLookupError(err))
+	}
+
+	return c.JSON(responses.SuccessResponse{
+		Status: "success",
+		Data:   responses.NewSessionResponse(session, sessionLocale(appState, userData), utils.SessionActive(session, appState.Config.SessionGap, time.Now())),
+	})
+}
+
+// EndSessionHandler ends a running manual drinking session.
+func EndSessionHandler(c *fiber.Ctx) error {
+	appState := c.Locals("appState").(*state.AppState)
+	userData := c.Locals("mdlData").(*responses.JwtMiddlewareResponse)
+	sessionRepo := repositories.NewSessionRepository(appState.DB)
+
+	sessionID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrSessionIDParse)
+	}
+
+	session, err := sessionRepo.GetSessionByID(userData.User.ID, sessionID)
+	if err != nil {
+		return exceptions.HandlerErrorResponse(c, sessionLookupError(err))
+	}
+
+	if !session.Manual || session.EndedAt != nil {
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrSessionNotRunning)
+	}
+
+	endedAt := time.Now()
+	session.EndedAt = &endedAt
+
+	if _, err := sessionRepo.UpdateSession(session); err != nil {
+		log.Println("Failed to end drinking session:", err)
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrSessionNotUpdated)
+	}
+
+	message := "Drinking session ended successfully"
+	return c.JSON(responses.SuccessResponse{
+		Status:  "success",
+		Data:    responses.NewSessionResponse(session, sessionLocale(appState, userData), false),
+		Message: &message,
+	})
+}
+
+// sessionLocale returns the standard drink locale of the authenticated user.
+func sessionLocale(appState *state.AppState, userData *responses.JwtMiddlewareResponse) string {
+	return units.ResolveLocale(userData.User.DrinkLocale, appState.Config.DefaultDrinkLocale)
+}
+
+// sessionLookupError maps a repository lookup error to the error returned to the client.
+func sessionLookupError(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return exceptions.ErrSessionNotFound
+	}
+	log.Println("Failed to get drinking session:", err)
+	return exceptions.ErrDatabase
+}
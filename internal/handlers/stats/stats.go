@@ -0,0 +1,186 @@
+package stats
+
+import (
+	"log"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/starks97/alcohol-tracker-api/internal/dtos"
+	"github.com/starks97/alcohol-tracker-api/internal/exceptions"
+	"github.com/starks97/alcohol-tracker-api/internal/repositories"
+	"github.com/starks97/alcohol-tracker-api/internal/responses"
+	"github.com/starks97/alcohol-tracker-api/internal/services"
+	"github.com/starks97/alcohol-tracker-api/internal/state"
+	"github.com/starks97/alcohol-tracker-api/internal/units"
+	"github.com/starks97/alcohol-tracker-api/internal/utils"
+)
+
+// maxStatsRangeYears bounds the requested range so day buckets stay a reasonable size.
+const maxStatsRangeYears = 5
+
+// defaultStatsBuckets is how many buckets, up to the current one, are returned when
+// from is omitted.
+var defaultStatsBuckets = map[string]int{
+	services.StatsBucketDay:   30,
+	services.StatsBucketWeek:  12,
+	services.StatsBucketMonth: 12,
+}
+
+// ConsumptionStatsHandler returns the authenticated user's consumption totals per day,
+// week or month, along with the totals for the whole range and per beverage category.
+// Totals include the drink count, ethanol, standard drinks, calories and money spent per
+// currency.
+// Buckets follow the user's time zone and every bucket in the range is returned, including
+// those without drinks.
+//
+// Supported query parameters:
+//   - from, to: RFC 3339 timestamps or YYYY-MM-DD dates; to is exclusive for timestamps and
+//     inclusive for dates. Defaults to the last 30 days, 12 weeks or 12 months.
+//   - bucket: "day", "week" (starting on Monday) or "month". Defaults to "day".
+func ConsumptionStatsHandler(c *fiber.Ctx) error {
+	appState := c.Locals("appState").(*state.AppState)
+	userData := c.Locals("mdlData").(*responses.JwtMiddlewareResponse)
+	statsRepo := repositories.NewStatsRepository(appState.DB)
+
+	query := dtos.ConsumptionStatsQueryDto{Bucket: services.StatsBucketDay}
+
+	if err := c.QueryParser(&query); err != nil {
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrInvalidQuery)
+	}
+
+	if err := utils.ParseValidatorMessage(&query, appState.Validator); err != nil {
+		if validationErr, ok := err.(*utils.ValidationError); ok {
+			return exceptions.HandlerValidationErrorResponse(c, exceptions.ErrValidationFailed, validationErr.Errors)
+		}
+		return exceptions.HandlerErrorResponse(c, err)
+	}
+
+	loc := utils.UserLocation(&userData.User)
+	from, to, err := statsRange(query, time.Now(), loc)
+	if err != nil {
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrInvalidQuery)
+	}
+
+	buckets, err := statsRepo.ConsumptionByBucket(userData.User.ID, from, to, query.Bucket, loc.String())
+	if err != nil {
+		log.Println("Failed to aggregate consumption:", err)
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrDatabase)
+	}
+
+	categories, err := statsRepo.ConsumptionByCategory(userData.User.ID, from, to)
+	if err != nil {
+		log.Println("Failed to aggregate consumption by category:", err)
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrDatabase)
+	}
+
+	spending, err := statsRepo.SpendingByBucket(userData.User.ID, from, to, query.Bucket, loc.String())
+	if err != nil {
+		log.Println("Failed to aggregate spending:", err)
+		return exceptions.HandlerErrorResponse(c, exceptions.ErrDatabase)
+	}
+
+	spentTotal := make(map[string]float64)
+	spentByBucket := make(map[string]map[string]float64)
+	spentByCategory := make(map[string]map[string]float64)
+	for _, spent := range spending {
+		start := spent.BucketStart.In(loc).Format(time.DateOnly)
+		if spentByBucket[start] == nil {
+			spentByBucket[start] = make(map[string]float64)
+		}
+		if spentByCategory[spent.Category] == nil {
+			spentByCategory[spent.Category] = make(map[string]float64)
+		}
+		spentTotal[spent.Currency] += spent.Amount
+		spentByBucket[start][spent.Currency] += spent.Amount
+		spentByCategory[spent.Category][spent.Currency] += spent.Amount
+	}
+
+	locale := units.ResolveLocale(userData.User.DrinkLocale, appState.Config.DefaultDrinkLocale)
+
+	byStart := make(map[string]repositories.ConsumptionTotals, len(buckets))
+	var totals repositories.ConsumptionTotals
+	for _, bucket := range buckets {
+		byStart[bucket.BucketStart.In(loc).Format(time.DateOnly)] = bucket.ConsumptionTotals
+		totals.DrinkCount += bucket.DrinkCount
+		totals.EthanolGrams += bucket.EthanolGrams
+		totals.Calories += bucket.Calories
+	}
+
+	bucketResponses := []responses.ConsumptionBucketResponse{}
+	for start := services.BucketStart(from, query.Bucket, loc); start.Before(to); start = services.NextBucketStart(start, query.Bucket) {
+		key := start.Format(time.DateOnly)
+		bucketResponses = append(bucketResponses, responses.ConsumptionBucketResponse{
+			Start:                     start,
+			ConsumptionTotalsResponse: responses.NewConsumptionTotalsResponse(byStart[key], spentByBucket[key], locale),
+		})
+	}
+
+	categoryResponses := make([]responses.CategoryConsumptionResponse, 0, len(categories))
+	for _, category := range categories {
+		categoryResponses = append(categoryResponses, responses.CategoryConsumptionResponse{
+			Category:                  category.Category,
+			ConsumptionTotalsResponse: responses.NewConsumptionTotalsResponse(category.ConsumptionTotals, spentByCategory[category.Category], locale),
+		})
+	}
+
+	return c.JSON(responses.SuccessResponse{
+		Status: "success",
+		Data: responses.ConsumptionStatsResponse{
+			From:       from.In(loc),
+			To:         to.In(loc),
+			Bucket:     query.Bucket,
+			TimeZone:   loc.String(),
+			Locale:     locale,
+			Totals:     responses.NewConsumptionTotalsResponse(totals, spentTotal, locale),
+			Buckets:    bucketResponses,
+			Categories: categoryResponses,
+		},
+	})
+}
+
+// statsRange resolves the requested [from, to) range, applying the defaults for the bucket.
+func statsRange(query dtos.ConsumptionStatsQueryDto, now time.Time, loc *time.Location) (time.Time, time.Time, error) {
+	to := services.NextBucketStart(services.BucketStart(now, query.Bucket, loc), query.Bucket)
+	if query.To != "" {
+		parsed, err := parseStatsTime(query.To, loc, true)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		to = parsed
+	}
+
+	from := services.BucketStart(to.Add(-time.Nanosecond), query.Bucket, loc)
+	for i := 1; i < defaultStatsBuckets[query.Bucket]; i++ {
+		from = services.BucketStart(from.Add(-time.Nanosecond), query.Bucket, loc)
+	}
+	if query.From != "" {
+		parsed, err := parseStatsTime(query.From, loc, false)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		from = parsed
+	}
+
+	if !from.Before(to) || from.AddDate(maxStatsRangeYears, 0, 0).Before(to) {
+		return time.Time{}, time.Time{}, exceptions.ErrInvalidQuery
+	}
+	return from, to, nil
+}
+
+// parseStatsTime parses an RFC 3339 timestamp or a YYYY-MM-DD date in loc. With
+// endOfDay set, a date resolves to the following midnight so that it is included.
+func parseStatsTime(value string, loc *time.Location, endOfDay bool) (time.Time, error) {
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return parsed, nil
+	}
+
+	parsed, err := time.ParseInLocation(time.DateOnly, value, loc)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		parsed = parsed.AddDate(0, 0, 1)
+	}
+	return parsed, nil
+}
@@ -0,0 +1,52 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// LogMailer prints emails to the log instead of sending them, for local development and
+// tests. When dir is set, each email is also written there as a .eml file.
+type LogMailer struct {
+	dir string
+
+	mu   sync.Mutex
+	sent []Message
+}
+
+func NewLogMailer(dir string) *LogMailer {
+	return &LogMailer{dir: dir}
+}
+
+func (m *LogMailer) Send(ctx context.Context, message Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	m.sent = append(m.sent, message)
+	m.mu.Unlock()
+
+	log.Printf("Email to %s: %s\n%s", message.To, message.Subject, message.Body)
+
+	if m.dir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(m.dir, 0o700); err != nil {
+		return fmt.Errorf("error creating mail directory: %v", err)
+	}
+	name := fmt.Sprintf("%d.eml", time.Now().UnixNano())
+	return os.WriteFile(filepath.Join(m.dir, name), formatMessage("log@localhost", message), 0o600)
+}
+
+// Sent returns the messages sent so far, oldest first.
+func (m *LogMailer) Sent() []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Message(nil), m.sent...)
+}
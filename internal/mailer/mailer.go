@@ -0,0 +1,46 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/starks97/alcohol-tracker-api/config"
+)
+
+// Mail drivers selectable with MAIL_DRIVER.
+const (
+	DriverSMTP = "smtp"
+	DriverLog  = "log"
+)
+
+// Message is a plain text email.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends emails. Handlers depend on this interface only, so that local development
+// and tests can use LogMailer instead of a real SMTP server.
+type Mailer interface {
+	Send(ctx context.Context, message Message) error
+}
+
+// NewMailer returns the mailer selected by the configuration.
+//
+// Parameters:
+//   - cfg: The application configuration.
+//
+// Returns:
+//   - Mailer: An SMTPMailer or a LogMailer.
+//   - error: An error if the configured driver is unknown.
+func NewMailer(cfg *config.Config) (Mailer, error) {
+	switch cfg.MailDriver {
+	case DriverSMTP:
+		return NewSMTPMailer(cfg.SmtpHost, cfg.SmtpPort, cfg.SmtpUsername, cfg.SmtpPassword, cfg.MailFrom), nil
+	case DriverLog:
+		return NewLogMailer(cfg.MailLogDir), nil
+	default:
+		return nil, fmt.Errorf("unknown mail driver %q, expected %q or %q", cfg.MailDriver, DriverSMTP, DriverLog)
+	}
+}
@@ -20,7 +20,7 @@ import (
 //
 // The middleware performs the following steps:
 // 1. Retrieves the bearer token from the "Authorization" header.
-// 2. Verifies the token using the provided public key.
+// 2. Verifies the token, including its issuer, audience, type and scopes.
 // 3. Retrieves the user ID associated with the token from Redis.
 // 4. Retrieves the user from the database using the retrieved user ID.
 // 5. Verifies that the user ID from Redis matches the user ID from the database.
@@ -54,8 +54,8 @@ func JWTAuthMiddleware() fiber.Handler {
 		// Remove the "Bearer " prefix from the token.
 		token := strings.TrimPrefix(bearerToken, "Bearer ")
 
-		// Verify the JWT token using the provided public key.
-		verifyToken, err := services.VerifyJwtToken(appState.Config.AccessTokenPublicKey, token)
+		// Verify the JWT token with the access token keys.
+		verifyToken, err := utils.VerifyJwtToken(appState, services.TokenTypeAccess, token)
 		if err != nil {
 			// Return a custom error response indicating that token verification failed.
 			return exceptions.HandlerErrorResponse(c, exceptions.ErrTokenVerification)
@@ -94,6 +94,7 @@ func JWTAuthMiddleware() fiber.Handler {
 		var jwtMiddlewareRes = responses.JwtMiddlewareResponse{
 			User:        *user,
 			AccessToken: accessTokenUuid,
+			Scopes:      verifyToken.Scopes,
 		}
 
 		c.Locals("mdlData", &jwtMiddlewareRes)
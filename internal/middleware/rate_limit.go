@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/starks97/alcohol-tracker-api/config"
+	"github.com/starks97/alcohol-tracker-api/internal/exceptions"
+	"github.com/starks97/alcohol-tracker-api/internal/responses"
+	"github.com/starks97/alcohol-tracker-api/internal/state"
+	"github.com/starks97/alcohol-tracker-api/internal/utils"
+)
+
+// RateLimit creates a Fiber middleware handler that limits requests with the named policy
+// of config.Config.RateLimits, using sliding window counters in Redis.
+//
+// Every response carries the RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset and
+// RateLimit-Policy headers; rejected requests get ErrRateLimited with a Retry-After header.
+// When Redis is unavailable, requests are let through or rejected with
+// ErrRateLimitFailed, depending on config.Config.RateLimitFailOpen. Policies keyed
+// by user must run after JWTAuthMiddleware to count per user.
+//
+// Parameters:
+//   - name: The name of the policy, such as "auth".
+//
+// Returns:
+//
+//	fiber.Handler: A Fiber middleware handler.
+func RateLimit(name string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		appState := c.Locals("appState").(*state.AppState)
+		ctx := c.Locals("ctx").(context.Context)
+
+		policy, ok := appState.Config.RateLimits[name]
+		if !ok {
+			log.Printf("Rate limit policy %q is not configured", name)
+			return c.Next()
+		}
+
+		result, err := utils.NewRateLimiter(appState).Allow(ctx, name, rateLimitKey(c, policy), policy, time.Now())
+		if err != nil {
+			if appState.Config.RateLimitFailOpen {
+				return c.Next()
+			}
+			return exceptions.HandlerErrorResponse(c, exceptions.ErrRateLimitFailed)
+		}
+
+		c.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+		c.Set("RateLimit-Policy", strconv.Itoa(policy.Limit)+";w="+strconv.Itoa(ceilSeconds(policy.Window)))
+
+		if !result.Allowed {
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(ceilSeconds(result.RetryAfter)))
+			return exceptions.HandlerErrorResponse(c, exceptions.ErrRateLimited)
+		}
+		return c.Next()
+	}
+}
+
+// rateLimitKey builds the key a request is counted under from the parts the policy uses.
+func rateLimitKey(c *fiber.Ctx, policy config.RateLimitPolicy) string {
+	parts := make([]string, 0, len(policy.KeyBy))
+	for _, keyBy := range policy.KeyBy {
+		switch keyBy {
+		case config.RateLimitByUser:
+			if userData, ok := c.Locals("mdlData").(*responses.JwtMiddlewareResponse); ok {
+				parts = append(parts, "user="+userData.User.ID.String())
+			} else {
+				parts = append(parts, "ip="+c.IP())
+			}
+		case config.RateLimitByRoute:
+			parts = append(parts, "route="+c.Method()+" "+RateLimitRoute(c))
+		default:
+			parts = append(parts, "ip="+c.IP())
+		}
+	}
+	return strings.Join(parts, "|")
+}
+
+// RateLimitRoute returns the path a request is counted under by policies keyed by route.
+// Unless the app routes case-sensitively or strictly, paths that differ only in case or in
+// a trailing slash reach the same handler, so they share a counter too.
+func RateLimitRoute(c *fiber.Ctx) string {
+	path := c.Path()
+	routing := c.App().Config()
+	if !routing.CaseSensitive {
+		path = strings.ToLower(path)
+	}
+	if !routing.StrictRouting && len(path) > 1 {
+		path = strings.TrimRight(path, "/")
+		if path == "" {
+			path = "/"
+		}
+	}
+	return path
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
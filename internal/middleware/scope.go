@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"slices"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/starks97/alcohol-tracker-api/internal/exceptions"
+	"github.com/starks97/alcohol-tracker-api/internal/responses"
+)
+
+// RequireScope creates a Fiber middleware handler that only lets requests through whose
+// access token grants every one of the given scopes. It must run after JWTAuthMiddleware.
+//
+// Parameters:
+//   - scopes: The scopes the access token must grant.
+//
+// Returns:
+//
+//	fiber.Handler: A Fiber middleware handler.
+func RequireScope(scopes ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userData, ok := c.Locals("mdlData").(*responses.JwtMiddlewareResponse)
+		if !ok {
+			return exceptions.HandlerErrorResponse(c, exceptions.ErrTokenMissing)
+		}
+
+		for _, scope := range scopes {
+			if !slices.Contains(userData.Scopes, scope) {
+				return exceptions.HandlerErrorResponse(c, exceptions.ErrInsufficientScope)
+			}
+		}
+		return c.Next()
+	}
+}
@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/starks97/alcohol-tracker-api/internal/exceptions"
+	"github.com/starks97/alcohol-tracker-api/internal/responses"
+)
+
+// RequireVerifiedEmail creates a Fiber middleware handler that only lets users with a
+// verified email address through. It must run after JWTAuthMiddleware.
+//
+// Returns:
+//
+//	fiber.Handler: A Fiber middleware handler.
+func RequireVerifiedEmail() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userData, ok := c.Locals("mdlData").(*responses.JwtMiddlewareResponse)
+		if !ok {
+			return exceptions.HandlerErrorResponse(c, exceptions.ErrTokenMissing)
+		}
+
+		if !userData.User.EmailVerified {
+			return exceptions.HandlerErrorResponse(c, exceptions.ErrEmailNotVerified)
+		}
+		return c.Next()
+	}
+}
@@ -0,0 +1,119 @@
+package repositories
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/starks97/alcohol-tracker-api/internal/entities"
+)
+
+// beverageSearchDocument is the expression indexed by idx_beverages_search.
+// It must stay in sync with the index created in database.ConnectDB.
+const beverageSearchDocument = "to_tsvector('simple', brand || ' ' || name || ' ' || coalesce(style, ''))"
+
+type BeverageRepository interface {
+	GetBeverageByID(id uuid.UUID) (*entities.Beverage, error)
+	GetBeverageByBarcode(code string) (*entities.Beverage, error)
+	SearchBeverages(query string, limit int) ([]entities.Beverage, error)
+	SaveBeverage(beverage *entities.Beverage) (bool, error)
+}
+
+type beverageRepository struct {
+	db *gorm.DB
+}
+
+func NewBeverageRepository(db *gorm.DB) BeverageRepository {
+	return &beverageRepository{db: db}
+}
+
+func (br *beverageRepository) GetBeverageByID(id uuid.UUID) (*entities.Beverage, error) {
+	var beverage entities.Beverage
+	if err := br.db.First(&beverage, id).Error; err != nil {
+		return nil, err
+	}
+	return &beverage, nil
+}
+
+// GetBeverageByBarcode looks up a beverage by its barcode. A 12-digit UPC-A code and its
+// zero-prefixed EAN-13 form identify the same product, so both spellings are matched.
+func (br *beverageRepository) GetBeverageByBarcode(code string) (*entities.Beverage, error) {
+	codes := []string{code}
+	switch {
+	case len(code) == 12:
+		codes = append(codes, "0"+code)
+	case len(code) == 13 && strings.HasPrefix(code, "0"):
+		codes = append(codes, code[1:])
+	}
+
+	var beverage entities.Beverage
+	if err := br.db.Where("barcode IN ?", codes).First(&beverage).Error; err != nil {
+		return nil, err
+	}
+	return &beverage, nil
+}
+
+// SearchBeverages runs a Postgres full-text search over brand, name and style, matching
+// every term of the query as a prefix so results update while the user is typing.
+func (br *beverageRepository) SearchBeverages(query string, limit int) ([]entities.Beverage, error) {
+	tsQuery := prefixTsQuery(query)
+	if tsQuery == "" {
+		return []entities.Beverage{}, nil
+	}
+
+	var beverages []entities.Beverage
+	err := br.db.
+		Where(beverageSearchDocument+" @@ to_tsquery('simple', ?)", tsQuery).
+		Order(gorm.Expr("ts_rank("+beverageSearchDocument+", to_tsquery('simple', ?)) DESC, brand, name", tsQuery)).
+		Limit(limit).
+		Find(&beverages).Error
+	if err != nil {
+		return nil, err
+	}
+	return beverages, nil
+}
+
+// SaveBeverage adds the beverage to the catalog, or updates the item it matches: the one
+// with the same barcode, as GetBeverageByBarcode finds it, or for a beverage without a
+// barcode, the one without a barcode and with the same brand and name. The barcode of a
+// matched item is kept. It reports whether a new item was created.
+func (br *beverageRepository) SaveBeverage(beverage *entities.Beverage) (bool, error) {
+	existing := &entities.Beverage{}
+	var err error
+	if beverage.Barcode != nil {
+		existing, err = br.GetBeverageByBarcode(*beverage.Barcode)
+	} else {
+		err = br.db.Where("barcode IS NULL AND lower(brand) = lower(?) AND lower(name) = lower(?)", beverage.Brand, beverage.Name).
+			First(existing).Error
+	}
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return true, br.db.Create(beverage).Error
+	} else if err != nil {
+		return false, err
+	}
+
+	beverage.ID = existing.ID
+	beverage.Barcode = existing.Barcode
+	beverage.CreatedAt = existing.CreatedAt
+	return false, br.db.Model(existing).
+		Select("brand", "name", "style", "category", "default_abv", "serving_sizes_ml").
+		Updates(beverage).Error
+}
+
+// prefixTsQuery turns free text into a tsquery where every word must match as a prefix,
+// e.g. "guin stout" becomes "guin:* & stout:*". Characters with a meaning in the tsquery
+// syntax are dropped so user input can never produce an invalid query.
+func prefixTsQuery(query string) string {
+	terms := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	for i, term := range terms {
+		terms[i] = term + ":*"
+	}
+	return strings.Join(terms, " & ")
+}
@@ -0,0 +1,126 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/starks97/alcohol-tracker-api/internal/entities"
+)
+
+// DrinkFilter narrows down the drink entries returned by ListDrinks.
+// Zero values mean "no restriction"; a Limit of 0 returns every matching row.
+type DrinkFilter struct {
+	From   *time.Time
+	To     *time.Time
+	Limit  int
+	Offset int
+}
+
+type DrinkRepository interface {
+	CreateDrink(drink *entities.DrinkEntry) (*entities.DrinkEntry, error)
+	GetDrinkByID(userID uuid.UUID, id uuid.UUID) (*entities.DrinkEntry, error)
+	ListDrinks(userID uuid.UUID, filter DrinkFilter) ([]entities.DrinkEntry, int64, error)
+	UpdateDrink(drink *entities.DrinkEntry) (*entities.DrinkEntry, error)
+	DeleteDrink(userID uuid.UUID, id uuid.UUID) error
+}
+
+type drinkRepository struct {
+	db *gorm.DB
+}
+
+func NewDrinkRepository(db *gorm.DB) DrinkRepository {
+	return &drinkRepository{db: db}
+}
+
+func (dr *drinkRepository) CreateDrink(drink *entities.DrinkEntry) (*entities.DrinkEntry, error) {
+	if err := dr.db.Create(drink).Error; err != nil {
+		return nil, err
+	}
+	return drink, nil
+}
+
+// GetDrinkByID returns the drink only when it belongs to the given user, so callers
+// never have to re-check ownership.
+func (dr *drinkRepository) GetDrinkByID(userID uuid.UUID, id uuid.UUID) (*entities.DrinkEntry, error) {
+	var drink entities.DrinkEntry
+	if err := dr.db.Where("id = ? AND user_id = ?", id, userID).First(&drink).Error; err != nil {
+		return nil, err
+	}
+	return &drink, nil
+}
+
+// ListDrinks returns the user's drinks ordered from most to least recent, together
+// with the total number of rows matching the filter (ignoring Limit and Offset).
+func (dr *drinkRepository) ListDrinks(userID uuid.UUID, filter DrinkFilter) ([]entities.DrinkEntry, int64, error) {
+	query := dr.db.Model(&entities.DrinkEntry{}).Where("user_id = ?", userID)
+
+	if filter.From != nil {
+		query = query.Where("consumed_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("consumed_at <= ?", *filter.To)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		query = query.Offset(filter.Offset)
+	}
+
+	var drinks []entities.DrinkEntry
+	if err := query.Order("consumed_at DESC").Find(&drinks).Error; err != nil {
+		return nil, 0, err
+	}
+	return drinks, total, nil
+}
+
+func (dr *drinkRepository) UpdateDrink(drink *entities.DrinkEntry) (*entities.DrinkEntry, error) {
+	// Set here rather than left to GORM, which does not update the struct on map updates,
+	// so that the drink returned has it.
+	drink.UpdatedAt = time.Now()
+	result := dr.db.Model(&entities.DrinkEntry{}).
+		Where("id = ? AND user_id = ?", drink.ID, drink.UserID).
+		Updates(map[string]interface{}{
+			"beverage_id":       drink.BeverageID,
+			"beverage_name":     drink.BeverageName,
+			"category":          drink.Category,
+			"volume_ml":         drink.VolumeMl,
+			"abv":               drink.ABV,
+			"consumed_at":       drink.ConsumedAt,
+			"price":             drink.Price,
+			"currency":          drink.Currency,
+			"calories":          drink.Calories,
+			"calories_provided": drink.CaloriesProvided,
+			"notes":             drink.Notes,
+			"image_ref":         drink.ImageRef,
+			"updated_at":        drink.UpdatedAt,
+		})
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return drink, nil
+}
+
+func (dr *drinkRepository) DeleteDrink(userID uuid.UUID, id uuid.UUID) error {
+	result := dr.db.Where("id = ? AND user_id = ?", id, userID).Delete(&entities.DrinkEntry{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
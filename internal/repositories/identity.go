@@ -0,0 +1,107 @@
+package repositories
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/starks97/alcohol-tracker-api/internal/entities"
+)
+
+// ErrLastLoginMethod is returned by UnlinkIdentity when the identity is the user's only
+// way to log in.
+var ErrLastLoginMethod = errors.New("identity is the last login method of the user")
+
+type IdentityRepository interface {
+	ListIdentities(userID uuid.UUID) ([]entities.UserIdentity, error)
+	GetIdentity(provider string, providerUserID string) (*entities.UserIdentity, error)
+	CreateIdentity(identity *entities.UserIdentity) (*entities.UserIdentity, error)
+	UpdateRefreshToken(identityID uuid.UUID, refreshToken *string) error
+	UnlinkIdentity(userID uuid.UUID, provider string) error
+}
+
+type identityRepository struct {
+	db *gorm.DB
+}
+
+func NewIdentityRepository(db *gorm.DB) IdentityRepository {
+	return &identityRepository{db: db}
+}
+
+func (ir *identityRepository) ListIdentities(userID uuid.UUID) ([]entities.UserIdentity, error) {
+	var identities []entities.UserIdentity
+	if err := ir.db.Where("user_id = ?", userID).Order("linked_at").Find(&identities).Error; err != nil {
+		return nil, err
+	}
+	return identities, nil
+}
+
+func (ir *identityRepository) GetIdentity(provider string, providerUserID string) (*entities.UserIdentity, error) {
+	var identity entities.UserIdentity
+	if err := ir.db.Where("provider = ? AND provider_user_id = ?", provider, providerUserID).First(&identity).Error; err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func (ir *identityRepository) CreateIdentity(identity *entities.UserIdentity) (*entities.UserIdentity, error) {
+	if err := ir.db.Create(identity).Error; err != nil {
+		return nil, err
+	}
+	return identity, nil
+}
+
+// UpdateRefreshToken stores the latest refresh token the provider issued for the identity.
+// It is written from a struct, because the serializer encrypting it does not apply to map
+// updates.
+func (ir *identityRepository) UpdateRefreshToken(identityID uuid.UUID, refreshToken *string) error {
+	return ir.db.Model(&entities.UserIdentity{}).
+		Where("id = ?", identityID).
+		Select("refresh_token").
+		Updates(&entities.UserIdentity{RefreshToken: refreshToken}).Error
+}
+
+// UnlinkIdentity removes the user's identity at the provider, unless the user would be
+// left with no password and no other identity. The user row is locked meanwhile, so that
+// concurrent unlinks cannot remove the last two methods together.
+//
+// Returns gorm.ErrRecordNotFound when the user has no identity at the provider, and
+// ErrLastLoginMethod when it is their only login method.
+func (ir *identityRepository) UnlinkIdentity(userID uuid.UUID, provider string) error {
+	return ir.db.Transaction(func(tx *gorm.DB) error {
+		var user entities.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, userID).Error; err != nil {
+			return err
+		}
+
+		var identities int64
+		if err := tx.Model(&entities.UserIdentity{}).Where("user_id = ?", userID).Count(&identities).Error; err != nil {
+			return err
+		}
+
+		methods := identities
+		if user.Password != nil {
+			methods++
+		}
+
+		result := tx.Where("user_id = ? AND provider = ?", userID, provider).Delete(&entities.UserIdentity{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if methods <= 1 {
+			// Returning an error rolls the delete back.
+			return ErrLastLoginMethod
+		}
+
+		// Users signed up before identities existed may still name the provider on their
+		// row, from which the identity would be restored.
+		return tx.Model(&entities.User{}).
+			Where("id = ? AND provider = ?", userID, provider).
+			Updates(map[string]interface{}{"provider": nil, "provider_id": nil}).Error
+	})
+}
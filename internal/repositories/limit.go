@@ -0,0 +1,42 @@
+package repositories
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/starks97/alcohol-tracker-api/internal/entities"
+)
+
+type LimitRepository interface {
+	GetLimit(userID uuid.UUID) (*entities.DrinkingLimit, error)
+	SaveLimit(limit *entities.DrinkingLimit) (*entities.DrinkingLimit, error)
+}
+
+type limitRepository struct {
+	db *gorm.DB
+}
+
+func NewLimitRepository(db *gorm.DB) LimitRepository {
+	return &limitRepository{db: db}
+}
+
+func (lr *limitRepository) GetLimit(userID uuid.UUID) (*entities.DrinkingLimit, error) {
+	var limit entities.DrinkingLimit
+	if err := lr.db.Where("user_id = ?", userID).First(&limit).Error; err != nil {
+		return nil, err
+	}
+	return &limit, nil
+}
+
+// SaveLimit creates the user's limits or replaces them when they already exist.
+func (lr *limitRepository) SaveLimit(limit *entities.DrinkingLimit) (*entities.DrinkingLimit, error) {
+	err := lr.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"daily_standard_drinks", "weekly_standard_drinks", "alcohol_free_days_per_week", "updated_at"}),
+	}).Create(limit).Error
+	if err != nil {
+		return nil, err
+	}
+	return limit, nil
+}
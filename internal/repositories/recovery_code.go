@@ -0,0 +1,55 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/starks97/alcohol-tracker-api/internal/entities"
+)
+
+type RecoveryCodeRepository interface {
+	ReplaceRecoveryCodes(userID uuid.UUID, codeHashes []string) error
+	UseRecoveryCode(userID uuid.UUID, codeHash string) (bool, error)
+	DeleteRecoveryCodes(userID uuid.UUID) error
+}
+
+type recoveryCodeRepository struct {
+	db *gorm.DB
+}
+
+func NewRecoveryCodeRepository(db *gorm.DB) RecoveryCodeRepository {
+	return &recoveryCodeRepository{db: db}
+}
+
+// ReplaceRecoveryCodes discards the user's recovery codes, used or not, and stores new ones.
+func (rr *recoveryCodeRepository) ReplaceRecoveryCodes(userID uuid.UUID, codeHashes []string) error {
+	return rr.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&entities.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+
+		codes := make([]entities.RecoveryCode, 0, len(codeHashes))
+		for _, codeHash := range codeHashes {
+			codes = append(codes, entities.RecoveryCode{UserID: userID, CodeHash: codeHash})
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+// UseRecoveryCode marks an unused recovery code of the user as used. It reports whether a
+// code was found, and cannot succeed twice for the same code even under concurrent calls.
+func (rr *recoveryCodeRepository) UseRecoveryCode(userID uuid.UUID, codeHash string) (bool, error) {
+	result := rr.db.Model(&entities.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (rr *recoveryCodeRepository) DeleteRecoveryCodes(userID uuid.UUID) error {
+	return rr.db.Where("user_id = ?", userID).Delete(&entities.RecoveryCode{}).Error
+}
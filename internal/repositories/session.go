@@ -0,0 +1,191 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/starks97/alcohol-tracker-api/internal/entities"
+)
+
+type SessionFilter struct {
+	From   *time.Time // Sessions started at or after this time.
+	To     *time.Time // Sessions started at or before this time.
+	Limit  int
+	Offset int
+}
+
+type SessionRepository interface {
+	CreateSession(session *entities.DrinkingSession) (*entities.DrinkingSession, error)
+	GetSessionByID(userID uuid.UUID, id uuid.UUID) (*entities.DrinkingSession, error)
+	GetRunningSession(userID uuid.UUID) (*entities.DrinkingSession, error)
+	ListSessions(userID uuid.UUID, filter SessionFilter) ([]entities.DrinkingSession, int64, error)
+	FindSessionsAt(userID uuid.UUID, at time.Time, gap time.Duration) ([]entities.DrinkingSession, error)
+	UpdateSession(session *entities.DrinkingSession) (*entities.DrinkingSession, error)
+	UpdateSessionLocation(userID uuid.UUID, id uuid.UUID, location *string) error
+	DeleteSession(userID uuid.UUID, id uuid.UUID) error
+	ListSessionDrinks(userID uuid.UUID, sessionID uuid.UUID) ([]entities.DrinkEntry, error)
+	SetDrinkSession(drinkID uuid.UUID, sessionID uuid.UUID) error
+	MoveSessionDrinks(fromSessionIDs []uuid.UUID, toSessionID uuid.UUID) error
+}
+
+type sessionRepository struct {
+	db *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) SessionRepository {
+	return &sessionRepository{db: db}
+}
+
+// CreateSession saves a new session. Starting a manual session while another one of the
+// user is still running fails with gorm.ErrDuplicatedKey.
+func (sr *sessionRepository) CreateSession(session *entities.DrinkingSession) (*entities.DrinkingSession, error) {
+	if err := sr.db.Create(session).Error; err != nil {
+		if translator, ok := sr.db.Dialector.(gorm.ErrorTranslator); ok {
+			err = translator.Translate(err)
+		}
+		return nil, err
+	}
+	return session, nil
+}
+
+func (sr *sessionRepository) GetSessionByID(userID uuid.UUID, id uuid.UUID) (*entities.DrinkingSession, error) {
+	var session entities.DrinkingSession
+	if err := sr.db.Where("id = ? AND user_id = ?", id, userID).First(&session).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// GetRunningSession returns the manual session the user has started and not ended yet.
+func (sr *sessionRepository) GetRunningSession(userID uuid.UUID) (*entities.DrinkingSession, error) {
+	var session entities.DrinkingSession
+	err := sr.db.Where("user_id = ? AND manual AND ended_at IS NULL", userID).
+		Order("started_at DESC").
+		First(&session).Error
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (sr *sessionRepository) ListSessions(userID uuid.UUID, filter SessionFilter) ([]entities.DrinkingSession, int64, error) {
+	query := sr.db.Model(&entities.DrinkingSession{}).Where("user_id = ?", userID)
+
+	if filter.From != nil {
+		query = query.Where("started_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("started_at <= ?", *filter.To)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		query = query.Offset(filter.Offset)
+	}
+
+	var sessions []entities.DrinkingSession
+	if err := query.Order("started_at DESC").Find(&sessions).Error; err != nil {
+		return nil, 0, err
+	}
+	return sessions, total, nil
+}
+
+// FindSessionsAt returns the sessions a drink consumed at the given time belongs to:
+// manual sessions running at that time first, then automatic sessions whose first or
+// last drink is less than gap away from it.
+func (sr *sessionRepository) FindSessionsAt(userID uuid.UUID, at time.Time, gap time.Duration) ([]entities.DrinkingSession, error) {
+	var sessions []entities.DrinkingSession
+	err := sr.db.Where("user_id = ?", userID).
+		Where(sr.db.
+			Where("manual AND started_at <= ? AND (ended_at IS NULL OR ended_at >= ?)", at, at).
+			Or("NOT manual AND started_at < ? AND ended_at > ?", at.Add(gap), at.Add(-gap))).
+		Order("manual DESC, started_at").
+		Find(&sessions).Error
+	if err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+func (sr *sessionRepository) UpdateSession(session *entities.DrinkingSession) (*entities.DrinkingSession, error) {
+	result := sr.db.Model(&entities.DrinkingSession{}).
+		Where("id = ? AND user_id = ?", session.ID, session.UserID).
+		Updates(map[string]interface{}{
+			"started_at":    session.StartedAt,
+			"ended_at":      session.EndedAt,
+			"location":      session.Location,
+			"drink_count":   session.DrinkCount,
+			"ethanol_grams": session.EthanolGrams,
+			"peak_bac":      session.PeakBAC,
+			"peak_bac_at":   session.PeakBACAt,
+		})
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return session, nil
+}
+
+// UpdateSessionLocation sets the location label of a session alone, so that it does not
+// overwrite totals recomputed meanwhile by a drink being logged.
+func (sr *sessionRepository) UpdateSessionLocation(userID uuid.UUID, id uuid.UUID, location *string) error {
+	result := sr.db.Model(&entities.DrinkingSession{}).
+		Where("id = ? AND user_id = ?", id, userID).
+		Update("location", location)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (sr *sessionRepository) DeleteSession(userID uuid.UUID, id uuid.UUID) error {
+	result := sr.db.Where("id = ? AND user_id = ?", id, userID).Delete(&entities.DrinkingSession{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (sr *sessionRepository) ListSessionDrinks(userID uuid.UUID, sessionID uuid.UUID) ([]entities.DrinkEntry, error) {
+	var drinks []entities.DrinkEntry
+	err := sr.db.Where("user_id = ? AND session_id = ?", userID, sessionID).
+		Order("consumed_at").
+		Find(&drinks).Error
+	if err != nil {
+		return nil, err
+	}
+	return drinks, nil
+}
+
+func (sr *sessionRepository) SetDrinkSession(drinkID uuid.UUID, sessionID uuid.UUID) error {
+	return sr.db.Model(&entities.DrinkEntry{}).
+		Where("id = ?", drinkID).
+		Update("session_id", sessionID).Error
+}
+
+// MoveSessionDrinks reassigns every drink of the given sessions to another session, which
+// is how adjacent sessions are merged.
+func (sr *sessionRepository) MoveSessionDrinks(fromSessionIDs []uuid.UUID, toSessionID uuid.UUID) error {
+	return sr.db.Model(&entities.DrinkEntry{}).
+		Where("session_id IN ?", fromSessionIDs).
+		Update("session_id", toSessionID).Error
+}
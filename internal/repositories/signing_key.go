@@ -0,0 +1,65 @@
+package repositories
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/starks97/alcohol-tracker-api/internal/entities"
+)
+
+type SigningKeyRepository interface {
+	ListSigningKeys(purpose string, now time.Time) ([]entities.SigningKey, error)
+	CreateSigningKey(key *entities.SigningKey) error
+	RotateSigningKey(next *entities.SigningKey, overlap time.Duration) error
+	DeleteExpiredSigningKeys(now time.Time) (int64, error)
+}
+
+type signingKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewSigningKeyRepository(db *gorm.DB) SigningKeyRepository {
+	return &signingKeyRepository{db: db}
+}
+
+// ListSigningKeys returns the keys of a purpose that have not expired, oldest first.
+func (skr *signingKeyRepository) ListSigningKeys(purpose string, now time.Time) ([]entities.SigningKey, error) {
+	var keys []entities.SigningKey
+	err := skr.db.
+		Where("purpose = ? AND (expires_at IS NULL OR expires_at > ?)", purpose, now).
+		Order("activates_at").
+		Find(&keys).Error
+	return keys, err
+}
+
+// CreateSigningKey stores a key, unless a key with the same ID exists for its purpose.
+func (skr *signingKeyRepository) CreateSigningKey(key *entities.SigningKey) error {
+	return skr.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}, {Name: "purpose"}},
+		DoNothing: true,
+	}).Create(key).Error
+}
+
+// RotateSigningKey stores next and retires the keys of its purpose that are still current
+// when it activates. They keep verifying tokens for overlap afterwards.
+func (skr *signingKeyRepository) RotateSigningKey(next *entities.SigningKey, overlap time.Duration) error {
+	expiresAt := next.ActivatesAt.Add(overlap)
+
+	return skr.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&entities.SigningKey{}).
+			Where("purpose = ? AND retires_at IS NULL", next.Purpose).
+			Updates(map[string]interface{}{"retires_at": next.ActivatesAt, "expires_at": expiresAt}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(next).Error
+	})
+}
+
+// DeleteExpiredSigningKeys deletes the keys that no longer verify any token.
+func (skr *signingKeyRepository) DeleteExpiredSigningKeys(now time.Time) (int64, error) {
+	result := skr.db.Where("expires_at <= ?", now).Delete(&entities.SigningKey{})
+	return result.RowsAffected, result.Error
+}
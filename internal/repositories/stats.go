@@ -0,0 +1,115 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/starks97/alcohol-tracker-api/internal/entities"
+	"github.com/starks97/alcohol-tracker-api/internal/units"
+)
+
+// consumptionTotalsColumns selects the ConsumptionTotals fields. It takes the ethanol
+// density, the ethanol density again and the kcal per gram of ethanol as parameters; drinks
+// logged before calories were tracked count the calories of their ethanol.
+const consumptionTotalsColumns = "COUNT(*) AS drink_count, " +
+	"COALESCE(SUM(volume_ml * abv / 100 * ?), 0) AS ethanol_grams, " +
+	"COALESCE(SUM(COALESCE(calories, volume_ml * abv / 100 * ? * ?)), 0) AS calories"
+
+// bucketStartColumn truncates consumed_at to a bucket in a time zone. It takes the bucket
+// and the time zone twice as parameters.
+const bucketStartColumn = "date_trunc(?, consumed_at AT TIME ZONE ?) AT TIME ZONE ? AS bucket_start"
+
+// ConsumptionTotals are the aggregates computed over a group of drink entries.
+type ConsumptionTotals struct {
+	DrinkCount   int64
+	EthanolGrams float64
+	Calories     float64
+}
+
+// BucketConsumption holds the totals of the drinks consumed within one time bucket.
+type BucketConsumption struct {
+	BucketStart time.Time
+	ConsumptionTotals
+}
+
+// CategoryConsumption holds the totals of the drinks of one beverage category.
+type CategoryConsumption struct {
+	Category string
+	ConsumptionTotals
+}
+
+// Spending is the money spent in one currency on the drinks of one bucket and category.
+type Spending struct {
+	BucketStart time.Time
+	Category    string
+	Currency    string
+	Amount      float64
+}
+
+type StatsRepository interface {
+	ConsumptionByBucket(userID uuid.UUID, from time.Time, to time.Time, bucket string, timeZone string) ([]BucketConsumption, error)
+	ConsumptionByCategory(userID uuid.UUID, from time.Time, to time.Time) ([]CategoryConsumption, error)
+	SpendingByBucket(userID uuid.UUID, from time.Time, to time.Time, bucket string, timeZone string) ([]Spending, error)
+}
+
+type statsRepository struct {
+	db *gorm.DB
+}
+
+func NewStatsRepository(db *gorm.DB) StatsRepository {
+	return &statsRepository{db: db}
+}
+
+// ConsumptionByBucket groups the user's drinks consumed in [from, to) by day, week or
+// month. Buckets are truncated in the given IANA time zone, so a drink at 23:30 local
+// time counts towards that local day. Buckets without drinks are not returned.
+func (sr *statsRepository) ConsumptionByBucket(userID uuid.UUID, from time.Time, to time.Time, bucket string, timeZone string) ([]BucketConsumption, error) {
+	var buckets []BucketConsumption
+	err := sr.consumptionQuery(userID, from, to).
+		Select(bucketStartColumn+", "+consumptionTotalsColumns, bucket, timeZone, timeZone, units.EthanolDensity, units.EthanolDensity, units.EthanolKcalPerGram).
+		Group("bucket_start").
+		Order("bucket_start").
+		Scan(&buckets).Error
+	if err != nil {
+		return nil, err
+	}
+	return buckets, nil
+}
+
+// ConsumptionByCategory groups the user's drinks consumed in [from, to) by category.
+func (sr *statsRepository) ConsumptionByCategory(userID uuid.UUID, from time.Time, to time.Time) ([]CategoryConsumption, error) {
+	var categories []CategoryConsumption
+	err := sr.consumptionQuery(userID, from, to).
+		Select("category, "+consumptionTotalsColumns, units.EthanolDensity, units.EthanolDensity, units.EthanolKcalPerGram).
+		Group("category").
+		Order("ethanol_grams DESC").
+		Scan(&categories).Error
+	if err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+// SpendingByBucket sums the price of the user's drinks consumed in [from, to) per bucket,
+// category and currency. Amounts in different currencies are never added together, and
+// drinks without a price are left out.
+func (sr *statsRepository) SpendingByBucket(userID uuid.UUID, from time.Time, to time.Time, bucket string, timeZone string) ([]Spending, error) {
+	var spending []Spending
+	err := sr.consumptionQuery(userID, from, to).
+		Select(bucketStartColumn+", category, currency, SUM(price) AS amount", bucket, timeZone, timeZone).
+		Where("price IS NOT NULL AND currency IS NOT NULL").
+		Group("1, 2, 3").
+		Order("1, 2, 3").
+		Scan(&spending).Error
+	if err != nil {
+		return nil, err
+	}
+	return spending, nil
+}
+
+func (sr *statsRepository) consumptionQuery(userID uuid.UUID, from time.Time, to time.Time) *gorm.DB {
+	return sr.db.Model(&entities.DrinkEntry{}).
+		Where("user_id = ? AND consumed_at >= ? AND consumed_at < ?", userID, from, to)
+}
@@ -6,6 +6,7 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/starks97/alcohol-tracker-api/internal/entities"
+	"github.com/starks97/alcohol-tracker-api/internal/units"
 )
 
 type UserRepository interface {
@@ -14,6 +15,10 @@ type UserRepository interface {
 	GetUserByEmail(email string) (*entities.User, error)
 	GetUserByProvider(provider string, providerID string) (*entities.User, error)
 	UpdateUser(user *entities.User) (*entities.User, error)
+	UpdateTwoFactor(userID uuid.UUID, totpSecret *string, enabled bool) error
+	MarkEmailVerified(userID uuid.UUID, email string) (bool, error)
+	ResetPassword(userID uuid.UUID, hashedPassword string) error
+	DeleteUser(id uuid.UUID) error
 }
 
 type userRepository struct {
@@ -64,11 +69,16 @@ func (usr *userRepository) UpdateUser(user *entities.User) (*entities.User, erro
 	result := usr.db.Model(&entities.User{}).
 		Where("email = ?", user.Email). // You can change this to another unique identifier
 		Updates(map[string]interface{}{
-			"provider":               user.Provider,
-			"provider_id":            user.ProviderID,
-			"profile_picture":        user.ProfilePicture,
-			"provider_refresh_token": user.ProviderRefreshToken,
-			"name":                   user.Name,
+			"profile_picture": user.ProfilePicture,
+			"name":            user.Name,
+			"password":        user.Password,
+			"weight_kg":       user.WeightKg,
+			"height_cm":       user.HeightCm,
+			"sex":             user.Sex,
+			"date_of_birth":   user.DateOfBirth,
+			"unit_system":     units.UnitSystemOrDefault(user.UnitSystem),
+			"drink_locale":    user.DrinkLocale,
+			"time_zone":       user.TimeZone,
 		})
 
 	if result.Error != nil {
@@ -77,3 +87,47 @@ func (usr *userRepository) UpdateUser(user *entities.User) (*entities.User, erro
 
 	return user, nil
 }
+
+// UpdateTwoFactor stores the user's TOTP secret and whether 2FA is enabled. These columns
+// are left out of UpdateUser so that profile updates never touch them. The secret is
+// written with a struct update, so that it is encrypted.
+func (usr *userRepository) UpdateTwoFactor(userID uuid.UUID, totpSecret *string, enabled bool) error {
+	return usr.db.Model(&entities.User{}).
+		Where("id = ?", userID).
+		Select("totp_secret", "two_factor_enabled").
+		Updates(&entities.User{TotpSecret: totpSecret, TwoFactorEnabled: enabled}).Error
+}
+
+// MarkEmailVerified flags the user's email address as verified, provided it is still the
+// given one. It reports whether the user was updated.
+func (usr *userRepository) MarkEmailVerified(userID uuid.UUID, email string) (bool, error) {
+	result := usr.db.Model(&entities.User{}).
+		Where("id = ? AND email = ?", userID, email).
+		Update("email_verified", true)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// ResetPassword replaces the user's password after a reset by email, which also proves
+// that the email address is theirs.
+func (usr *userRepository) ResetPassword(userID uuid.UUID, hashedPassword string) error {
+	return usr.db.Model(&entities.User{}).
+		Where("id = ?", userID).
+		Updates(map[string]interface{}{
+			"password":       hashedPassword,
+			"email_verified": true,
+		}).Error
+}
+
+func (usr *userRepository) DeleteUser(id uuid.UUID) error {
+	result := usr.db.Delete(&entities.User{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -5,16 +5,27 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/starks97/alcohol-tracker-api/internal/entities"
+	"github.com/starks97/alcohol-tracker-api/internal/units"
 )
 
 type UserResponse struct {
-	ID         uuid.UUID `json:"id"`
-	Email      string    `json:"email"`
-	Name       string    `json:"name"`
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
-	Provider   string    `json:"provider"`
-	ProviderID string    `json:"provider_id"`
+	ID             uuid.UUID `json:"id"`
+	Email          string    `json:"email"`
+	EmailVerified  bool      `json:"email_verified"`
+	Name           string    `json:"name"`
+	ProfilePicture *string   `json:"profile_picture"`
+	HasPassword    bool      `json:"has_password"`
+	Weight         *float64  `json:"weight"` // In the unit system below (kg or lb).
+	Height         *float64  `json:"height"` // In the unit system below (cm or in).
+	Sex            *string   `json:"sex"`
+	DateOfBirth    *string   `json:"date_of_birth"` // YYYY-MM-DD.
+	UnitSystem     string    `json:"unit_system"`
+	DrinkLocale    *string   `json:"drink_locale"` // Null when the server default is used.
+	TimeZone       *string   `json:"time_zone"`    // Null when UTC is used.
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+	Provider       string    `json:"provider"`
+	ProviderID     string    `json:"provider_id"`
 }
 
 type ErrorResponse struct {
@@ -25,6 +36,7 @@ type ErrorResponse struct {
 type JwtMiddlewareResponse struct {
 	AccessToken uuid.UUID     `json:"access_token"`
 	User        entities.User `json:"user"`
+	Scopes      []string      `json:"scopes"` // Scopes granted by the access token.
 }
 
 type LoginResponse struct {
@@ -36,3 +48,104 @@ type SuccessResponse struct {
 	Data    interface{} `json:"data,omitempty"`
 	Message *string     `json:"message,omitempty"`
 }
+
+// NewUserResponse maps a User entity to its public JSON representation.
+// Secrets such as the password hash and provider tokens are never copied over, and body
+// measurements are converted to the user's preferred unit system.
+func NewUserResponse(user *entities.User) UserResponse {
+	unitSystem := units.UnitSystemOrDefault(user.UnitSystem)
+
+	response := UserResponse{
+		ID:             user.ID,
+		Email:          user.Email,
+		EmailVerified:  user.EmailVerified,
+		Name:           user.Name,
+		ProfilePicture: user.ProfilePicture,
+		HasPassword:    user.Password != nil,
+		Sex:            user.Sex,
+		UnitSystem:     unitSystem,
+		DrinkLocale:    user.DrinkLocale,
+		TimeZone:       user.TimeZone,
+		CreatedAt:      user.CreatedAt,
+		UpdatedAt:      user.UpdatedAt,
+	}
+
+	if user.WeightKg != nil {
+		weight := units.WeightFromKg(*user.WeightKg, unitSystem)
+		response.Weight = &weight
+	}
+	if user.HeightCm != nil {
+		height := units.HeightFromCm(*user.HeightCm, unitSystem)
+		response.Height = &height
+	}
+	if user.DateOfBirth != nil {
+		dateOfBirth := user.DateOfBirth.Format(time.DateOnly)
+		response.DateOfBirth = &dateOfBirth
+	}
+
+	if user.Provider != nil {
+		response.Provider = *user.Provider
+	}
+	if user.ProviderID != nil {
+		response.ProviderID = *user.ProviderID
+	}
+
+	return response
+}
+
+type AuthSessionResponse struct {
+	ID         uuid.UUID `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	Current    bool      `json:"current"` // Whether this is the session making the request.
+}
+
+// TwoFactorChallengeResponse is returned by the login endpoint instead of tokens when the
+// user has 2FA enabled. The challenge is redeemed at POST /auth/2fa/verify.
+type TwoFactorChallengeResponse struct {
+	TwoFactorRequired bool   `json:"two_factor_required"`
+	ChallengeToken    string `json:"challenge_token"`
+	ExpiresIn         int    `json:"expires_in"` // Seconds.
+}
+
+type TwoFactorEnrollmentResponse struct {
+	Secret     string `json:"secret"`      // Base32 secret, for manual entry.
+	OtpauthURI string `json:"otpauth_uri"` // URI to render as a QR code.
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"` // Shown only once.
+}
+
+type IdentityResponse struct {
+	Provider string    `json:"provider"`
+	Email    string    `json:"email"`
+	LinkedAt time.Time `json:"linked_at"`
+}
+
+type IdentitiesResponse struct {
+	HasPassword bool               `json:"has_password"`
+	Identities  []IdentityResponse `json:"identities"`
+}
+
+type AuthorizationURLResponse struct {
+	AuthorizationURL string `json:"authorization_url"`
+}
+
+// NewIdentitiesResponse lists the login methods of a user.
+func NewIdentitiesResponse(user *entities.User, identities []entities.UserIdentity) IdentitiesResponse {
+	response := IdentitiesResponse{
+		HasPassword: user.Password != nil,
+		Identities:  make([]IdentityResponse, 0, len(identities)),
+	}
+	for _, identity := range identities {
+		response.Identities = append(response.Identities, IdentityResponse{
+			Provider: identity.Provider,
+			Email:    identity.Email,
+			LinkedAt: identity.LinkedAt,
+		})
+	}
+	return response
+}
@@ -0,0 +1,44 @@
+package responses
+
+import (
+	"time"
+
+	"github.com/starks97/alcohol-tracker-api/internal/services"
+	"github.com/starks97/alcohol-tracker-api/internal/units"
+)
+
+type BacPointResponse struct {
+	Time time.Time `json:"time"`
+	BAC  float64   `json:"bac"`
+}
+
+type BacResponse struct {
+	CurrentBAC        float64            `json:"current_bac"`     // g/100ml (percent).
+	EthanolGrams      float64            `json:"ethanol_grams"`   // Consumed in the last 24 hours.
+	StandardDrinks    float64            `json:"standard_drinks"` // Consumed in the last 24 hours.
+	Locale            string             `json:"standard_drink_locale"`
+	SoberAt           *time.Time         `json:"sober_at"`
+	MinutesUntilSober int                `json:"minutes_until_sober"`
+	EliminationRate   float64            `json:"elimination_rate"`
+	Curve             []BacPointResponse `json:"curve"`
+}
+
+// NewBacResponse maps a BAC estimate to its public JSON representation, together with the
+// ethanol consumed in the window the estimate was computed from.
+func NewBacResponse(estimate services.BacEstimate, eliminationRate float64, ethanolGrams float64, locale string) BacResponse {
+	curve := make([]BacPointResponse, 0, len(estimate.Curve))
+	for _, point := range estimate.Curve {
+		curve = append(curve, BacPointResponse{Time: point.Time, BAC: point.BAC})
+	}
+
+	return BacResponse{
+		CurrentBAC:        estimate.Current,
+		EthanolGrams:      units.RoundGrams(ethanolGrams),
+		StandardDrinks:    units.RoundStandardDrinks(units.StandardDrinks(ethanolGrams, locale)),
+		Locale:            locale,
+		SoberAt:           estimate.SoberAt,
+		MinutesUntilSober: estimate.MinutesUntilSober,
+		EliminationRate:   eliminationRate,
+		Curve:             curve,
+	}
+}
@@ -0,0 +1,36 @@
+package responses
+
+import (
+	"github.com/google/uuid"
+	"github.com/starks97/alcohol-tracker-api/internal/entities"
+)
+
+type BeverageResponse struct {
+	ID             uuid.UUID `json:"id"`
+	Brand          string    `json:"brand"`
+	Name           string    `json:"name"`
+	Style          *string   `json:"style,omitempty"`
+	Category       string    `json:"category"`
+	DefaultABV     float64   `json:"default_abv"`
+	ServingSizesMl []float64 `json:"serving_sizes_ml"`
+	Barcode        *string   `json:"barcode,omitempty"`
+}
+
+// NewBeverageResponse maps a Beverage entity to its public JSON representation.
+func NewBeverageResponse(beverage *entities.Beverage) BeverageResponse {
+	servingSizes := beverage.ServingSizesMl
+	if servingSizes == nil {
+		servingSizes = []float64{}
+	}
+
+	return BeverageResponse{
+		ID:             beverage.ID,
+		Brand:          beverage.Brand,
+		Name:           beverage.Name,
+		Style:          beverage.Style,
+		Category:       beverage.Category,
+		DefaultABV:     beverage.DefaultABV,
+		ServingSizesMl: servingSizes,
+		Barcode:        beverage.Barcode,
+	}
+}
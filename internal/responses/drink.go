@@ -0,0 +1,72 @@
+package responses
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/starks97/alcohol-tracker-api/internal/entities"
+	"github.com/starks97/alcohol-tracker-api/internal/units"
+)
+
+type DrinkResponse struct {
+	ID                uuid.UUID  `json:"id"`
+	BeverageID        *uuid.UUID `json:"beverage_id,omitempty"`
+	SessionID         *uuid.UUID `json:"session_id,omitempty"`
+	BeverageName      string     `json:"beverage_name"`
+	Category          string     `json:"category"`
+	VolumeMl          float64    `json:"volume_ml"`
+	ABV               float64    `json:"abv"`
+	EthanolGrams      float64    `json:"ethanol_grams"`
+	StandardDrinks    float64    `json:"standard_drinks"`
+	Locale            string     `json:"standard_drink_locale"`
+	ConsumedAt        time.Time  `json:"consumed_at"`
+	Price             *float64   `json:"price,omitempty"`
+	Currency          *string    `json:"currency,omitempty"`
+	Calories          float64    `json:"calories"`
+	CaloriesEstimated bool       `json:"calories_estimated"`
+	Notes             *string    `json:"notes,omitempty"`
+	ImageRef          *string    `json:"image_ref,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+type DrinkListResponse struct {
+	Drinks []DrinkResponse `json:"drinks"`
+	Total  int64           `json:"total"`
+	Limit  int             `json:"limit"`
+	Offset int             `json:"offset"`
+}
+
+// NewDrinkResponse maps a DrinkEntry entity to its public JSON representation, including
+// its ethanol content expressed in standard drinks of the given locale. Drinks logged before
+// calories were tracked report the calories of their ethanol.
+func NewDrinkResponse(drink *entities.DrinkEntry, locale string) DrinkResponse {
+	ethanolGrams := units.EthanolGrams(drink.VolumeMl, drink.ABV)
+
+	calories := units.EthanolCalories(ethanolGrams)
+	if drink.Calories != nil {
+		calories = *drink.Calories
+	}
+
+	return DrinkResponse{
+		ID:                drink.ID,
+		BeverageID:        drink.BeverageID,
+		SessionID:         drink.SessionID,
+		BeverageName:      drink.BeverageName,
+		Category:          drink.Category,
+		VolumeMl:          drink.VolumeMl,
+		ABV:               drink.ABV,
+		EthanolGrams:      units.RoundGrams(ethanolGrams),
+		StandardDrinks:    units.RoundStandardDrinks(units.StandardDrinks(ethanolGrams, locale)),
+		Locale:            locale,
+		ConsumedAt:        drink.ConsumedAt,
+		Price:             drink.Price,
+		Currency:          drink.Currency,
+		Calories:          units.RoundCalories(calories),
+		CaloriesEstimated: !drink.CaloriesProvided,
+		Notes:             drink.Notes,
+		ImageRef:          drink.ImageRef,
+		CreatedAt:         drink.CreatedAt,
+		UpdatedAt:         drink.UpdatedAt,
+	}
+}
@@ -0,0 +1,17 @@
+package responses
+
+// JSONWebKey is a public key of the JWKS (RFC 7517).
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n"` // Modulus, base64url-encoded.
+	E   string `json:"e"` // Exponent, base64url-encoded.
+}
+
+// JSONWebKeySetResponse is returned as is, without the usual success envelope, as
+// JWKS clients expect.
+type JSONWebKeySetResponse struct {
+	Keys []JSONWebKey `json:"keys"`
+}
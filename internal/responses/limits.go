@@ -0,0 +1,93 @@
+package responses
+
+import (
+	"time"
+
+	"github.com/starks97/alcohol-tracker-api/internal/entities"
+	"github.com/starks97/alcohol-tracker-api/internal/services"
+)
+
+type AllowanceStatusResponse struct {
+	Limit     float64 `json:"limit"`
+	Consumed  float64 `json:"consumed"`
+	Remaining float64 `json:"remaining"`
+	Exceeded  bool    `json:"exceeded"`
+}
+
+type AlcoholFreeDaysStatusResponse struct {
+	Target                int  `json:"target"`
+	DrinkingDays          int  `json:"drinking_days"`
+	RemainingDrinkingDays int  `json:"remaining_drinking_days"`
+	Exceeded              bool `json:"exceeded"`
+}
+
+type LimitStatusResponse struct {
+	Daily           *AllowanceStatusResponse       `json:"daily,omitempty"`
+	Weekly          *AllowanceStatusResponse       `json:"weekly,omitempty"`
+	AlcoholFreeDays *AlcoholFreeDaysStatusResponse `json:"alcohol_free_days,omitempty"`
+	StreakDays      int                            `json:"streak_days"`
+}
+
+type LimitsResponse struct {
+	DailyStandardDrinks    *float64             `json:"daily_standard_drinks"`
+	WeeklyStandardDrinks   *float64             `json:"weekly_standard_drinks"`
+	AlcoholFreeDaysPerWeek *int                 `json:"alcohol_free_days_per_week"`
+	UpdatedAt              *time.Time           `json:"updated_at"`
+	Status                 *LimitStatusResponse `json:"status"`
+}
+
+// DrinkCreatedResponse is returned when a drink is logged. LimitStatus is only present
+// when the user has set consumption limits.
+type DrinkCreatedResponse struct {
+	DrinkResponse
+	LimitStatus *LimitStatusResponse `json:"limit_status,omitempty"`
+}
+
+// NewLimitStatusResponse maps a limit evaluation to its public JSON representation.
+func NewLimitStatusResponse(status *services.LimitStatus) *LimitStatusResponse {
+	if status == nil {
+		return nil
+	}
+
+	response := &LimitStatusResponse{StreakDays: status.StreakDays}
+	if status.Daily != nil {
+		response.Daily = newAllowanceStatusResponse(status.Daily)
+	}
+	if status.Weekly != nil {
+		response.Weekly = newAllowanceStatusResponse(status.Weekly)
+	}
+	if status.AlcoholFreeDays != nil {
+		response.AlcoholFreeDays = &AlcoholFreeDaysStatusResponse{
+			Target:                status.AlcoholFreeDays.Target,
+			DrinkingDays:          status.AlcoholFreeDays.DrinkingDays,
+			RemainingDrinkingDays: status.AlcoholFreeDays.RemainingDrinkingDays,
+			Exceeded:              status.AlcoholFreeDays.Exceeded,
+		}
+	}
+	return response
+}
+
+// NewLimitsResponse maps the user's limits and their evaluation to the JSON returned by
+// /me/limits. A nil limit means the user has not set any goals yet.
+func NewLimitsResponse(limit *entities.DrinkingLimit, status *services.LimitStatus) LimitsResponse {
+	if limit == nil {
+		return LimitsResponse{}
+	}
+
+	return LimitsResponse{
+		DailyStandardDrinks:    limit.DailyStandardDrinks,
+		WeeklyStandardDrinks:   limit.WeeklyStandardDrinks,
+		AlcoholFreeDaysPerWeek: limit.AlcoholFreeDaysPerWeek,
+		UpdatedAt:              &limit.UpdatedAt,
+		Status:                 NewLimitStatusResponse(status),
+	}
+}
+
+func newAllowanceStatusResponse(status *services.AllowanceStatus) *AllowanceStatusResponse {
+	return &AllowanceStatusResponse{
+		Limit:     status.Limit,
+		Consumed:  status.Consumed,
+		Remaining: status.Remaining,
+		Exceeded:  status.Exceeded,
+	}
+}
@@ -0,0 +1,56 @@
+package responses
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/starks97/alcohol-tracker-api/internal/entities"
+	"github.com/starks97/alcohol-tracker-api/internal/units"
+)
+
+type SessionResponse struct {
+	ID             uuid.UUID  `json:"id"`
+	StartedAt      time.Time  `json:"started_at"`
+	EndedAt        *time.Time `json:"ended_at"` // Null while a manual session is running.
+	Active         bool       `json:"active"`
+	Manual         bool       `json:"manual"`
+	Location       *string    `json:"location"`
+	DrinkCount     int        `json:"drink_count"`
+	EthanolGrams   float64    `json:"ethanol_grams"`
+	StandardDrinks float64    `json:"standard_drinks"`
+	Locale         string     `json:"standard_drink_locale"`
+	PeakBAC        *float64   `json:"peak_bac"` // Null when the profile lacks weight or sex. A snapshot: estimated from the profile when the session's drinks last changed, not when weight or sex change later.
+	PeakBACAt      *time.Time `json:"peak_bac_at"`
+}
+
+type SessionListResponse struct {
+	Sessions []SessionResponse `json:"sessions"`
+	Total    int64             `json:"total"`
+	Limit    int               `json:"limit"`
+	Offset   int               `json:"offset"`
+}
+
+type SessionDetailResponse struct {
+	SessionResponse
+	Drinks []DrinkResponse `json:"drinks"`
+}
+
+// NewSessionResponse maps a DrinkingSession entity to its public JSON representation,
+// expressing its ethanol in standard drinks of the given locale.
+func NewSessionResponse(session *entities.DrinkingSession, locale string, active bool) SessionResponse {
+	return SessionResponse{
+		ID:             session.ID,
+		StartedAt:      session.StartedAt,
+		EndedAt:        session.EndedAt,
+		Active:         active,
+		Manual:         session.Manual,
+		Location:       session.Location,
+		DrinkCount:     session.DrinkCount,
+		EthanolGrams:   units.RoundGrams(session.EthanolGrams),
+		StandardDrinks: units.RoundStandardDrinks(units.StandardDrinks(session.EthanolGrams, locale)),
+		Locale:         locale,
+		PeakBAC:        session.PeakBAC,
+		PeakBACAt:      session.PeakBACAt,
+	}
+}
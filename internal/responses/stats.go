@@ -0,0 +1,67 @@
+package responses
+
+import (
+	"sort"
+	"time"
+
+	"github.com/starks97/alcohol-tracker-api/internal/repositories"
+	"github.com/starks97/alcohol-tracker-api/internal/units"
+)
+
+type ConsumptionTotalsResponse struct {
+	DrinkCount     int64           `json:"drink_count"`
+	StandardDrinks float64         `json:"standard_drinks"`
+	EthanolGrams   float64         `json:"ethanol_grams"`
+	Calories       float64         `json:"calories"` // kcal, estimated unless logged with the drink.
+	Spent          []MoneyResponse `json:"spent"`    // One entry per currency.
+}
+
+type MoneyResponse struct {
+	Currency string  `json:"currency"`
+	Amount   float64 `json:"amount"`
+}
+
+type ConsumptionBucketResponse struct {
+	Start time.Time `json:"start"` // Start of the bucket in the user's time zone.
+	ConsumptionTotalsResponse
+}
+
+type CategoryConsumptionResponse struct {
+	Category string `json:"category"`
+	ConsumptionTotalsResponse
+}
+
+type ConsumptionStatsResponse struct {
+	From       time.Time                     `json:"from"`
+	To         time.Time                     `json:"to"` // Exclusive.
+	Bucket     string                        `json:"bucket"`
+	TimeZone   string                        `json:"time_zone"`
+	Locale     string                        `json:"standard_drink_locale"`
+	Totals     ConsumptionTotalsResponse     `json:"totals"`
+	Buckets    []ConsumptionBucketResponse   `json:"buckets"`
+	Categories []CategoryConsumptionResponse `json:"categories"`
+}
+
+// NewConsumptionTotalsResponse maps aggregated drink totals and the amounts spent per
+// currency to their public JSON representation, expressing the ethanol in standard drinks
+// of the given locale.
+func NewConsumptionTotalsResponse(totals repositories.ConsumptionTotals, spent map[string]float64, locale string) ConsumptionTotalsResponse {
+	currencies := make([]string, 0, len(spent))
+	for currency := range spent {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+
+	money := make([]MoneyResponse, 0, len(currencies))
+	for _, currency := range currencies {
+		money = append(money, MoneyResponse{Currency: currency, Amount: units.RoundMoney(spent[currency])})
+	}
+
+	return ConsumptionTotalsResponse{
+		DrinkCount:     totals.DrinkCount,
+		StandardDrinks: units.RoundStandardDrinks(units.StandardDrinks(totals.EthanolGrams, locale)),
+		EthanolGrams:   units.RoundGrams(totals.EthanolGrams),
+		Calories:       units.RoundCalories(totals.Calories),
+		Spent:          money,
+	}
+}
@@ -3,6 +3,11 @@ package routes
 import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/starks97/alcohol-tracker-api/internal/handlers/authen"
+	"github.com/starks97/alcohol-tracker-api/internal/handlers/beverages"
+	"github.com/starks97/alcohol-tracker-api/internal/handlers/drinks"
+	"github.com/starks97/alcohol-tracker-api/internal/handlers/me"
+	"github.com/starks97/alcohol-tracker-api/internal/handlers/sessions"
+	"github.com/starks97/alcohol-tracker-api/internal/handlers/stats"
 	"github.com/starks97/alcohol-tracker-api/internal/middleware"
 
 	"github.com/starks97/alcohol-tracker-api/internal/state"
@@ -11,16 +16,76 @@ import (
 // all routes
 func SetupRoutes(app *fiber.App, appState *state.AppState) {
 
+	app.Get("/.well-known/jwks.json", authen.JWKSHandler)
+
 	//middleware.JWTAuthMiddleware()
-	auth := app.Group("/auth")
+	auth := app.Group("/auth", middleware.RateLimit("auth"))
 
 	auth.Get("/refresh", authen.RefreshTokenHandler)
 
+	// Registered before /:provider so that they are not taken for a provider name.
+	auth.Get("/sessions", middleware.JWTAuthMiddleware(), authen.ListSessionsHandler)
+	auth.Delete("/sessions/:id", middleware.JWTAuthMiddleware(), authen.RevokeSessionHandler)
+	auth.Post("/logout-all", middleware.JWTAuthMiddleware(), authen.LogOutAllHandler)
+
+	auth.Post("/2fa/enroll", middleware.JWTAuthMiddleware(), middleware.RequireVerifiedEmail(), authen.EnrollTwoFactorHandler)
+	auth.Post("/2fa/enable", middleware.JWTAuthMiddleware(), middleware.RequireVerifiedEmail(), authen.EnableTwoFactorHandler)
+	auth.Post("/2fa/disable", middleware.JWTAuthMiddleware(), middleware.RequireVerifiedEmail(), authen.DisableTwoFactorHandler)
+	auth.Post("/2fa/verify", authen.VerifyTwoFactorHandler)
+
+	auth.Post("/verify-email", authen.VerifyEmailHandler)
+	auth.Post("/verify-email/resend", middleware.JWTAuthMiddleware(), authen.ResendVerificationEmailHandler)
+
+	auth.Get("/identities", middleware.JWTAuthMiddleware(), authen.ListIdentitiesHandler)
+	auth.Post("/identities/:provider", middleware.JWTAuthMiddleware(), middleware.RequireVerifiedEmail(), authen.LinkIdentityHandler)
+	auth.Delete("/identities/:provider", middleware.JWTAuthMiddleware(), middleware.RequireVerifiedEmail(), authen.UnlinkIdentityHandler)
+
 	auth.Get("/:provider", authen.OAuthLoginHandler)
 	auth.Get("/:provider/callback", authen.OAuthCallBackHandler)
+	auth.Post("/:provider/callback", authen.OAuthCallBackHandler)
 
 	auth.Post("/register", authen.Register)
 	auth.Post("/login", authen.LoginHandler)
 
+	auth.Post("/password/forgot", authen.ForgotPasswordHandler)
+	auth.Post("/password/reset", authen.ResetPasswordHandler)
+
 	auth.Post("/logout", middleware.JWTAuthMiddleware(), authen.LogOutHandler)
+
+	drink := app.Group("/drinks", middleware.JWTAuthMiddleware(), middleware.RequireScope("drinks"), middleware.RateLimit("api"))
+
+	drink.Get("/", drinks.ListDrinksHandler)
+	drink.Post("/", drinks.CreateDrinkHandler)
+	drink.Get("/:id", drinks.GetDrinkHandler)
+	drink.Patch("/:id", drinks.UpdateDrinkHandler)
+	drink.Delete("/:id", drinks.DeleteDrinkHandler)
+
+	session := app.Group("/sessions", middleware.JWTAuthMiddleware(), middleware.RequireScope("sessions"), middleware.RateLimit("api"))
+
+	session.Get("/", sessions.ListSessionsHandler)
+	session.Post("/start", sessions.StartSessionHandler)
+	session.Get("/:id", sessions.GetSessionHandler)
+	session.Patch("/:id", sessions.UpdateSessionHandler)
+	session.Post("/:id/end", sessions.EndSessionHandler)
+
+	beverage := app.Group("/beverages", middleware.JWTAuthMiddleware(), middleware.RequireScope("beverages"), middleware.RateLimit("api"))
+
+	beverage.Get("/", beverages.SearchBeveragesHandler)
+	beverage.Get("/barcode/:code", beverages.GetBeverageByBarcodeHandler)
+
+	profile := app.Group("/me", middleware.JWTAuthMiddleware(), middleware.RequireScope("profile"), middleware.RateLimit("api"))
+
+	profile.Get("/", me.GetMeHandler)
+	profile.Patch("/", me.UpdateMeHandler)
+	profile.Delete("/", middleware.RequireVerifiedEmail(), me.DeleteMeHandler)
+	profile.Get("/bac", me.BacHandler)
+	profile.Get("/limits", me.GetLimitsHandler)
+	profile.Put("/limits", me.SetLimitsHandler)
+
+	stat := app.Group("/stats", middleware.JWTAuthMiddleware(), middleware.RequireScope("stats"), middleware.RateLimit("api"))
+
+	stat.Get("/consumption", stats.ConsumptionStatsHandler)
+
+	// handlers.UploadImageHandler is not served yet: it writes every upload to the same
+	// file. The "upload" rate limit policy and scope are reserved for it.
 }
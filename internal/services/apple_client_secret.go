@@ -0,0 +1,53 @@
+package services
+
+import (
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// AppleClientSecretTTL is how long a generated Apple client secret is valid. Apple accepts
+// up to six months, but a secret is generated for each code exchange.
+const AppleClientSecretTTL = 5 * time.Minute
+
+// AppleAudience is the audience of Apple client secrets and the issuer of Apple ID tokens.
+const AppleAudience = "https://appleid.apple.com"
+
+// GenerateAppleClientSecret generates the client secret Sign in with Apple expects: a JWT
+// signed with ES256 by a key registered with the Apple developer account.
+//
+// Parameters:
+//   - teamID: The Apple developer team ID, used as the issuer.
+//   - clientID: The Services ID, used as the subject.
+//   - keyID: The ID of the signing key.
+//   - privateKey: The base64-encoded .p8 (PKCS #8 PEM) private key.
+//   - now: The time the secret is issued at.
+//
+// Returns:
+//   - string: The signed client secret.
+//   - error: An error if the key cannot be decoded or the secret cannot be signed.
+func GenerateAppleClientSecret(teamID string, clientID string, keyID string, privateKey string, now time.Time) (string, error) {
+	pemKey, err := base64.StdEncoding.DecodeString(privateKey)
+	if err != nil {
+		return "", fmt.Errorf("error decoding base64: %w", err)
+	}
+
+	key, err := jwt.ParseECPrivateKeyFromPEM(pemKey)
+	if err != nil {
+		return "", err
+	}
+
+	// MapClaims keep the audience a string, as Apple expects, rather than an array.
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"iss": teamID,
+		"sub": clientID,
+		"aud": AppleAudience,
+		"iat": now.Unix(),
+		"exp": now.Add(AppleClientSecretTTL).Unix(),
+	})
+	token.Header["kid"] = keyID
+
+	return token.SignedString(key)
+}
@@ -0,0 +1,196 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// WidmarkRatioMale and WidmarkRatioFemale are the Widmark body water distribution factors.
+const (
+	WidmarkRatioMale   = 0.68
+	WidmarkRatioFemale = 0.55
+)
+
+// BacDrink is a single drink as seen by the BAC estimator.
+type BacDrink struct {
+	EthanolGrams float64
+	ConsumedAt   time.Time
+}
+
+// BacProfile holds the physiological data required by the Widmark formula.
+type BacProfile struct {
+	WeightKg        float64 // Body weight in kilograms.
+	Sex             string  // "male" or "female".
+	EliminationRate float64 // Elimination rate in BAC percentage points per hour.
+}
+
+// BacPoint is one sample of the projected BAC curve.
+type BacPoint struct {
+	Time time.Time
+	BAC  float64
+}
+
+// BacEstimate is the result of EstimateBac.
+type BacEstimate struct {
+	Current           float64    // BAC at the time of the estimate, in g/100ml (percent).
+	SoberAt           *time.Time // When BAC reaches zero; nil when it already is zero.
+	MinutesUntilSober int
+	Curve             []BacPoint // Per-minute projection starting at the time of the estimate.
+}
+
+// WidmarkRatio returns the Widmark distribution factor for the given biological sex.
+func WidmarkRatio(sex string) (float64, error) {
+	switch sex {
+	case "male":
+		return WidmarkRatioMale, nil
+	case "female":
+		return WidmarkRatioFemale, nil
+	default:
+		return 0, fmt.Errorf("unsupported sex for Widmark ratio: %q", sex)
+	}
+}
+
+// EstimateBac estimates the blood alcohol concentration at `now` using the Widmark formula,
+// and projects it minute by minute for the next `hours` hours.
+//
+// Each drink is assumed to be absorbed at the time it was consumed, raising the BAC by
+// grams / (r * body weight in grams) * 100, while alcohol is eliminated at a constant
+// rate from the first drink onwards. Drinks logged in the future are included in the
+// projection but not in the current value.
+//
+// Parameters:
+//   - drinks: The drinks to take into account, in any order.
+//   - profile: The user's weight, sex and elimination rate.
+//   - now: The reference time of the estimate.
+//   - hours: How many hours of per-minute curve to return.
+//
+// Returns:
+//   - BacEstimate: The current BAC, the time until sober and the projected curve.
+//   - error: An error if the profile cannot be used with the Widmark formula.
+func EstimateBac(drinks []BacDrink, profile BacProfile, now time.Time, hours int) (BacEstimate, error) {
+	ratio, err := WidmarkRatio(profile.Sex)
+	if err != nil {
+		return BacEstimate{}, err
+	}
+	if profile.WeightKg <= 0 {
+		return BacEstimate{}, fmt.Errorf("weight must be greater than zero")
+	}
+	if profile.EliminationRate <= 0 {
+		return BacEstimate{}, fmt.Errorf("elimination rate must be greater than zero")
+	}
+
+	sorted := make([]BacDrink, len(drinks))
+	copy(sorted, drinks)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].ConsumedAt.Before(sorted[j].ConsumedAt)
+	})
+
+	now = now.Truncate(time.Minute)
+	end := now.Add(time.Duration(hours) * time.Hour)
+	eliminationPerMinute := profile.EliminationRate / 60
+	bodyWaterGrams := ratio * profile.WeightKg * 1000
+
+	start := now
+	if len(sorted) > 0 && sorted[0].ConsumedAt.Before(now) {
+		start = sorted[0].ConsumedAt.Truncate(time.Minute)
+	}
+
+	estimate := BacEstimate{Curve: make([]BacPoint, 0, hours*60+1)}
+	bac := 0.0
+	next := 0
+	var lastPositive *time.Time
+
+	for t := start; !t.After(end); t = t.Add(time.Minute) {
+		if t.After(start) {
+			bac = math.Max(0, bac-eliminationPerMinute)
+		}
+
+		for next < len(sorted) && !sorted[next].ConsumedAt.After(t) {
+			bac += sorted[next].EthanolGrams / bodyWaterGrams * 100
+			next++
+		}
+
+		if t.Before(now) {
+			continue
+		}
+		if t.Equal(now) {
+			estimate.Current = roundBac(bac)
+		}
+		if bac > 0 {
+			positive := t
+			lastPositive = &positive
+		}
+
+		estimate.Curve = append(estimate.Curve, BacPoint{Time: t, BAC: roundBac(bac)})
+	}
+
+	switch {
+	case bac > 0:
+		// Still not sober at the end of the curve: extrapolate the linear elimination.
+		soberAt := end.Add(time.Duration(math.Ceil(bac/eliminationPerMinute)) * time.Minute)
+		estimate.SoberAt = &soberAt
+	case lastPositive != nil:
+		soberAt := lastPositive.Add(time.Minute)
+		estimate.SoberAt = &soberAt
+	}
+
+	if estimate.SoberAt != nil {
+		estimate.MinutesUntilSober = int(estimate.SoberAt.Sub(now).Minutes())
+	}
+
+	return estimate, nil
+}
+
+// PeakBac returns the highest blood alcohol concentration reached over the given drinks
+// and when it was reached, using the same absorption and elimination model as EstimateBac.
+// The zero BacPoint is returned when there are no drinks.
+//
+// Parameters:
+//   - drinks: The drinks of the occasion, in any order.
+//   - profile: The user's weight, sex and elimination rate.
+//
+// Returns:
+//   - BacPoint: The peak BAC, in g/100ml (percent), and the time it was reached.
+//   - error: An error if the profile cannot be used with the Widmark formula.
+func PeakBac(drinks []BacDrink, profile BacProfile) (BacPoint, error) {
+	ratio, err := WidmarkRatio(profile.Sex)
+	if err != nil {
+		return BacPoint{}, err
+	}
+	if profile.WeightKg <= 0 {
+		return BacPoint{}, fmt.Errorf("weight must be greater than zero")
+	}
+
+	sorted := make([]BacDrink, len(drinks))
+	copy(sorted, drinks)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].ConsumedAt.Before(sorted[j].ConsumedAt)
+	})
+
+	bodyWaterGrams := ratio * profile.WeightKg * 1000
+
+	// BAC only rises when a drink is absorbed, so the peak is always right after a drink.
+	peak := BacPoint{}
+	bac := 0.0
+	for i, drink := range sorted {
+		if i > 0 {
+			elapsedHours := drink.ConsumedAt.Sub(sorted[i-1].ConsumedAt).Hours()
+			bac = math.Max(0, bac-profile.EliminationRate*elapsedHours)
+		}
+		bac += drink.EthanolGrams / bodyWaterGrams * 100
+
+		if bac > peak.BAC {
+			peak = BacPoint{Time: drink.ConsumedAt, BAC: bac}
+		}
+	}
+
+	peak.BAC = roundBac(peak.BAC)
+	return peak, nil
+}
+
+// roundBac rounds a BAC value to four decimal places.
+func roundBac(bac float64) float64 {
+	return math.Round(bac*10000) / 10000
+}
@@ -1,8 +1,14 @@
 package services
 
 import (
+	"crypto/rsa"
+	"crypto/sha256"
 	"encoding/base64"
+	"errors"
 	"fmt"
+	"math/big"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -10,12 +16,76 @@ import (
 	"github.com/starks97/alcohol-tracker-api/internal/dtos"
 )
 
-// GenerateJwtToken generates a JWT token for the given UserID with a specified TTL and private key.
+// Types of the tokens issued, each signed by its own keys.
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+)
+
+// TokenClaims are the claims saying who a token is from, who it is for, what type it is and
+// what it grants. Tokens are issued with them and must carry them to be verified.
+type TokenClaims struct {
+	Issuer   string
+	Audience string
+	Type     string   // TokenTypeAccess or TokenTypeRefresh.
+	Scopes   []string // Granted when issuing; the scopes allowed when verifying.
+}
+
+// SigningKey is an RSA key signing JWTs. Its ID is set as the kid header of the tokens it
+// signs, so that they can be verified after the key is rotated.
+type SigningKey struct {
+	ID         string
+	PrivateKey *rsa.PrivateKey
+}
+
+// VerificationKeys are the public keys tokens are verified with, by key ID. The key with
+// the empty ID verifies the tokens issued before tokens carried a key ID.
+type VerificationKeys map[string]*rsa.PublicKey
+
+// ParseSigningKey parses a base64-encoded PEM RSA private key. Its ID is the RFC 7638
+// thumbprint of its public key.
+//
+// Parameters:
+//   - privateKey: The base64-encoded PEM (PKCS #1 or PKCS #8) private key.
+//
+// Returns:
+//   - SigningKey: The key and its ID.
+//   - error: An error if the key cannot be decoded.
+func ParseSigningKey(privateKey string) (SigningKey, error) {
+	bytesPrivateKey, err := base64.StdEncoding.DecodeString(privateKey)
+	if err != nil {
+		return SigningKey{}, fmt.Errorf("Error decoding base64: %v", err)
+	}
+
+	privateKeyObj, err := jwt.ParseRSAPrivateKeyFromPEM(bytesPrivateKey)
+	if err != nil {
+		return SigningKey{}, err
+	}
+	return SigningKey{ID: KeyThumbprint(&privateKeyObj.PublicKey), PrivateKey: privateKeyObj}, nil
+}
+
+// KeyThumbprint returns the RFC 7638 JWK thumbprint of an RSA public key, base64url-encoded.
+func KeyThumbprint(publicKey *rsa.PublicKey) string {
+	n, e := JSONWebKeyParams(publicKey)
+	// The members are required, in lexicographic order and without whitespace.
+	sum := sha256.Sum256([]byte(`{"e":"` + e + `","kty":"RSA","n":"` + n + `"}`))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// JSONWebKeyParams returns the modulus and exponent of an RSA public key, base64url-encoded
+// as in a JSON Web Key.
+func JSONWebKeyParams(publicKey *rsa.PublicKey) (string, string) {
+	return base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes()),
+		base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+}
+
+// GenerateJwtToken generates a JWT token for the given UserID with a specified TTL and signing key.
 //
 // Parameters:
 //   - UserID: The user ID to include in the token claims.
 //   - ttl: The time-to-live of the token in minutes.
-//   - privateKey: The base64-encoded RSA private key used to sign the token.
+//   - key: The key used to sign the token, named by the kid header.
+//   - tokenClaims: The issuer, audience, type and scopes of the token.
 //
 // Returns:
 //   - models.TokenDetails: The token details, including the generated token and expiry time.
@@ -23,15 +93,17 @@ import (
 //
 // Example:
 //
-//	tokenDetails, err := GenerateJwtToken("user123", 60, privateKey)
+//	tokenDetails, err := GenerateJwtToken(userID, 60, signingKey, tokenClaims)
 //	if err != nil {
 //	    // Handle error
 //	}
 //	// Use tokenDetails
-func GenerateJwtToken(UserID uuid.UUID, ttl int64, privateKey string) (dtos.TokenDetailsDto, error) {
-	bytesPrivateKey, err := base64.StdEncoding.DecodeString(privateKey)
-	if err != nil {
-		return dtos.TokenDetailsDto{}, fmt.Errorf("Error decoding base64: %v", err)
+func GenerateJwtToken(UserID uuid.UUID, ttl int64, key SigningKey, tokenClaims TokenClaims) (dtos.TokenDetailsDto, error) {
+	if key.PrivateKey == nil {
+		return dtos.TokenDetailsDto{}, errors.New("no signing key")
+	}
+	if tokenClaims.Issuer == "" || tokenClaims.Audience == "" || tokenClaims.Type == "" {
+		return dtos.TokenDetailsDto{}, errors.New("the token issuer, audience and type are required")
 	}
 	timeStamp := time.Now().Unix()
 
@@ -41,6 +113,7 @@ func GenerateJwtToken(UserID uuid.UUID, ttl int64, privateKey string) (dtos.Toke
 		UserID:    UserID,
 		TokenUUID: uuid.New(),
 		Token:     nil,
+		Scopes:    tokenClaims.Scopes,
 	}
 
 	tokenDetails.ExpiresIn = &expirationTime
@@ -52,18 +125,17 @@ func GenerateJwtToken(UserID uuid.UUID, ttl int64, privateKey string) (dtos.Toke
 		Exp:       *tokenDetails.ExpiresIn,
 		Iat:       timeStamp,
 		Nbf:       timeStamp,
-	}
-
-	// Parse the private key to use in signing
-	privateKeyObj, err := jwt.ParseRSAPrivateKeyFromPEM(bytesPrivateKey)
-	if err != nil {
-		return tokenDetails, err
+		Iss:       tokenClaims.Issuer,
+		Aud:       jwt.ClaimStrings{tokenClaims.Audience},
+		Typ:       tokenClaims.Type,
+		Scope:     strings.Join(tokenClaims.Scopes, " "),
 	}
 
 	// Create the token with the claims and sign with the private key using RS256
 	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = key.ID
 
-	tokenString, err := token.SignedString(privateKeyObj) // Use the decoded private key for signing
+	tokenString, err := token.SignedString(key.PrivateKey)
 	if err != nil {
 		return tokenDetails, fmt.Errorf("Error signing token: %v", err)
 	}
@@ -72,11 +144,14 @@ func GenerateJwtToken(UserID uuid.UUID, ttl int64, privateKey string) (dtos.Toke
 	return tokenDetails, nil
 }
 
-// VerifyJwtToken verifies a JWT token using the provided public key.
+// VerifyJwtToken verifies a JWT token using the public key named by its kid header. The
+// token must also be issued by and for the expected parties, be of the expected type, and
+// grant no scope other than the expected ones.
 //
 // Parameters:
-//   - publicKey: The base64-encoded RSA public key used for verification.
+//   - keys: The public keys the token may be signed with.
 //   - token: The JWT token string to verify.
+//   - expected: The issuer, audience and type the token must have, and the scopes it may grant.
 //
 // Returns:
 //   - models.TokenDetails: The token details extracted from the token claims if the token is valid.
@@ -84,20 +159,15 @@ func GenerateJwtToken(UserID uuid.UUID, ttl int64, privateKey string) (dtos.Toke
 //
 // Example:
 //
-//	tokenDetails, err := VerifyJwtToken(publicKey, tokenString)
+//	tokenDetails, err := VerifyJwtToken(verificationKeys, tokenString, expectedClaims)
 //	if err != nil {
 //	    // Handle error
 //	}
 //	// Use tokenDetails
-func VerifyJwtToken(publicKey string, token string) (dtos.TokenDetailsDto, error) {
-	bytesPublicKey, err := base64.StdEncoding.DecodeString(publicKey)
-	if err != nil {
-		return dtos.TokenDetailsDto{}, fmt.Errorf("Error decoding base64: %v", err)
-	}
-
-	publicKeyObj, err := jwt.ParseRSAPublicKeyFromPEM(bytesPublicKey)
-	if err != nil {
-		return dtos.TokenDetailsDto{}, fmt.Errorf("error parsing public key: %v", err)
+func VerifyJwtToken(keys VerificationKeys, token string, expected TokenClaims) (dtos.TokenDetailsDto, error) {
+	// An empty expected issuer or audience would skip their check.
+	if expected.Issuer == "" || expected.Audience == "" {
+		return dtos.TokenDetailsDto{}, fmt.Errorf("no expected issuer or audience")
 	}
 
 	// Verify and decode the token
@@ -107,8 +177,18 @@ func VerifyJwtToken(publicKey string, token string) (dtos.TokenDetailsDto, error
 		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return publicKeyObj, nil
-	})
+		kid, _ := token.Header["kid"].(string)
+		publicKey, ok := keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key %q", kid)
+		}
+		return publicKey, nil
+	},
+		jwt.WithIssuer(expected.Issuer),
+		jwt.WithAudience(expected.Audience),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
 
 	if err != nil {
 		return dtos.TokenDetailsDto{}, fmt.Errorf("error verifying token: %v", err)
@@ -118,6 +198,17 @@ func VerifyJwtToken(publicKey string, token string) (dtos.TokenDetailsDto, error
 		return dtos.TokenDetailsDto{}, fmt.Errorf("invalid token")
 	}
 
+	if expected.Type == "" || claims.Typ != expected.Type {
+		return dtos.TokenDetailsDto{}, fmt.Errorf("token type %q is not %q", claims.Typ, expected.Type)
+	}
+
+	scopes := strings.Fields(claims.Scope)
+	for _, scope := range scopes {
+		if !slices.Contains(expected.Scopes, scope) {
+			return dtos.TokenDetailsDto{}, fmt.Errorf("scope %q is not allowed", scope)
+		}
+	}
+
 	tokenUUID, err := uuid.Parse(claims.TokenUUID)
 	if err != nil {
 		return dtos.TokenDetailsDto{}, fmt.Errorf("invalid token UUID: %v", err)
@@ -132,6 +223,7 @@ func VerifyJwtToken(publicKey string, token string) (dtos.TokenDetailsDto, error
 	tokenDetails := dtos.TokenDetailsDto{
 		TokenUUID: tokenUUID,
 		UserID:    userID,
+		Scopes:    scopes,
 	}
 	return tokenDetails, nil
 }
@@ -0,0 +1,163 @@
+package services
+
+import (
+	"math"
+	"time"
+)
+
+// LimitStreakLookbackDays is how many days back the under-the-limit streak is counted.
+const LimitStreakLookbackDays = 90
+
+// Limits are the consumption goals evaluated by EvaluateLimits. Nil fields are not evaluated.
+type Limits struct {
+	DailyStandardDrinks    *float64
+	WeeklyStandardDrinks   *float64
+	AlcoholFreeDaysPerWeek *int
+}
+
+// LimitDrink is a single drink as seen by the limit evaluator.
+type LimitDrink struct {
+	StandardDrinks float64
+	ConsumedAt     time.Time
+}
+
+// AllowanceStatus compares a consumption limit against what has been consumed so far.
+type AllowanceStatus struct {
+	Limit     float64
+	Consumed  float64
+	Remaining float64
+	Exceeded  bool
+}
+
+// AlcoholFreeDaysStatus tracks the alcohol-free days goal for the current week.
+type AlcoholFreeDaysStatus struct {
+	Target                int // Alcohol-free days wanted per week.
+	DrinkingDays          int // Days with at least one drink so far this week.
+	RemainingDrinkingDays int // Drinking days left before the goal is missed.
+	Exceeded              bool
+}
+
+// LimitStatus is the result of EvaluateLimits.
+type LimitStatus struct {
+	Daily           *AllowanceStatus
+	Weekly          *AllowanceStatus
+	AlcoholFreeDays *AlcoholFreeDaysStatus
+	StreakDays      int // Consecutive days, up to today, kept within every configured limit.
+}
+
+// StartOfDay returns midnight of the day t falls on, in the given location.
+func StartOfDay(t time.Time, loc *time.Location) time.Time {
+	local := t.In(loc)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+}
+
+// StartOfWeek returns midnight of the Monday of the week t falls on, in the given location.
+func StartOfWeek(t time.Time, loc *time.Location) time.Time {
+	day := StartOfDay(t, loc)
+	offset := (int(day.Weekday()) + 6) % 7 // Monday = 0
+	return day.AddDate(0, 0, -offset)
+}
+
+// EvaluateLimits checks the drinks against the user's limits as of `now`.
+//
+// Days and weeks are calendar days and ISO weeks (starting on Monday) in `loc`, and the
+// drinks should cover at least the last LimitStreakLookbackDays days for the streak to be
+// complete. Drinks after `now` are ignored.
+//
+// Parameters:
+//   - limits: The user's consumption goals.
+//   - drinks: The drinks logged by the user, in any order.
+//   - now: The reference time of the evaluation.
+//   - loc: The location whose calendar defines days and weeks.
+//
+// Returns:
+//   - LimitStatus: The remaining allowances, exceeded flags and streak.
+func EvaluateLimits(limits Limits, drinks []LimitDrink, now time.Time, loc *time.Location) LimitStatus {
+	today := StartOfDay(now, loc)
+
+	// Totals are keyed by calendar date so that days compare equal regardless of how the
+	// time.Time values were built.
+	perDay := make(map[string]float64)
+	for _, drink := range drinks {
+		if drink.ConsumedAt.After(now) {
+			continue
+		}
+		perDay[drink.ConsumedAt.In(loc).Format(time.DateOnly)] += drink.StandardDrinks
+	}
+
+	status := LimitStatus{}
+
+	if limits.DailyStandardDrinks != nil {
+		status.Daily = newAllowanceStatus(*limits.DailyStandardDrinks, perDay[today.Format(time.DateOnly)])
+	}
+
+	if limits.DailyStandardDrinks != nil || limits.WeeklyStandardDrinks != nil || limits.AlcoholFreeDaysPerWeek != nil {
+		for day := today; status.StreakDays < LimitStreakLookbackDays; day = day.AddDate(0, 0, -1) {
+			if !withinLimits(limits, perDay, day, loc) {
+				break
+			}
+			status.StreakDays++
+		}
+	}
+
+	weekConsumed, drinkingDays := weekToDate(perDay, today, loc)
+
+	if limits.WeeklyStandardDrinks != nil {
+		status.Weekly = newAllowanceStatus(*limits.WeeklyStandardDrinks, weekConsumed)
+	}
+
+	if limits.AlcoholFreeDaysPerWeek != nil {
+		allowedDrinkingDays := 7 - *limits.AlcoholFreeDaysPerWeek
+		status.AlcoholFreeDays = &AlcoholFreeDaysStatus{
+			Target:                *limits.AlcoholFreeDaysPerWeek,
+			DrinkingDays:          drinkingDays,
+			RemainingDrinkingDays: max(0, allowedDrinkingDays-drinkingDays),
+			Exceeded:              drinkingDays > allowedDrinkingDays,
+		}
+	}
+
+	return status
+}
+
+// withinLimits reports whether the day kept every configured limit: its own total within the
+// daily limit, and its week up to and including it within the weekly limit and the allowed
+// drinking days.
+func withinLimits(limits Limits, perDay map[string]float64, day time.Time, loc *time.Location) bool {
+	if limits.DailyStandardDrinks != nil && perDay[day.Format(time.DateOnly)] > *limits.DailyStandardDrinks {
+		return false
+	}
+
+	weekConsumed, drinkingDays := weekToDate(perDay, day, loc)
+	if limits.WeeklyStandardDrinks != nil && weekConsumed > *limits.WeeklyStandardDrinks {
+		return false
+	}
+	if limits.AlcoholFreeDaysPerWeek != nil && drinkingDays > 7-*limits.AlcoholFreeDaysPerWeek {
+		return false
+	}
+	return true
+}
+
+// weekToDate returns the standard drinks consumed and the drinking days from the start of
+// day's week up to and including day.
+func weekToDate(perDay map[string]float64, day time.Time, loc *time.Location) (float64, int) {
+	consumed := 0.0
+	drinkingDays := 0
+	for d := StartOfWeek(day, loc); !d.After(day); d = d.AddDate(0, 0, 1) {
+		dayConsumed := perDay[d.Format(time.DateOnly)]
+		consumed += dayConsumed
+		if dayConsumed > 0 {
+			drinkingDays++
+		}
+	}
+	return consumed, drinkingDays
+}
+
+func newAllowanceStatus(limit float64, consumed float64) *AllowanceStatus {
+	consumed = math.Round(consumed*100) / 100
+	return &AllowanceStatus{
+		Limit:     limit,
+		Consumed:  consumed,
+		Remaining: math.Max(0, math.Round((limit-consumed)*100)/100),
+		Exceeded:  consumed > limit,
+	}
+}
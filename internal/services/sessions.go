@@ -0,0 +1,21 @@
+package services
+
+import "time"
+
+// SplitAtGaps splits the consumption times of an automatic drinking session, in ascending
+// order, into the runs that each form a session: a run ends where the next drink comes gap
+// or more after the previous one. It returns the index of the first time of every run, so
+// the first index is always 0 unless times is empty.
+func SplitAtGaps(times []time.Time, gap time.Duration) []int {
+	if len(times) == 0 {
+		return nil
+	}
+
+	starts := []int{0}
+	for i := 1; i < len(times); i++ {
+		if times[i].Sub(times[i-1]) >= gap {
+			starts = append(starts, i)
+		}
+	}
+	return starts
+}
@@ -0,0 +1,40 @@
+package services
+
+import "time"
+
+// Buckets accepted by the consumption statistics. They match PostgreSQL's date_trunc fields.
+const (
+	StatsBucketDay   = "day"
+	StatsBucketWeek  = "week"
+	StatsBucketMonth = "month"
+)
+
+// StartOfMonth returns midnight of the first day of the month t falls on, in the given location.
+func StartOfMonth(t time.Time, loc *time.Location) time.Time {
+	local := t.In(loc)
+	return time.Date(local.Year(), local.Month(), 1, 0, 0, 0, 0, loc)
+}
+
+// BucketStart returns the start of the day, week or month t falls on, in the given location.
+func BucketStart(t time.Time, bucket string, loc *time.Location) time.Time {
+	switch bucket {
+	case StatsBucketWeek:
+		return StartOfWeek(t, loc)
+	case StatsBucketMonth:
+		return StartOfMonth(t, loc)
+	default:
+		return StartOfDay(t, loc)
+	}
+}
+
+// NextBucketStart returns the start of the bucket following the one starting at start.
+func NextBucketStart(start time.Time, bucket string) time.Time {
+	switch bucket {
+	case StatsBucketWeek:
+		return start.AddDate(0, 0, 7)
+	case StatsBucketMonth:
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// TOTP parameters (RFC 6238). They are the defaults every authenticator app supports.
+const (
+	TotpDigits = 6
+	TotpPeriod = 30 * time.Second
+	// TotpSkew is how many periods before and after the current one are accepted, to
+	// tolerate clock drift between the server and the user's device.
+	TotpSkew = 1
+
+	totpSecretBytes = 20
+)
+
+// RecoveryCodeCount is how many one-time recovery codes are issued when 2FA is enabled.
+const RecoveryCodeCount = 10
+
+// recoveryCodeAlphabet leaves out characters that are easily confused when copied by hand.
+const recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateTotpSecret returns a new random 160-bit TOTP secret, base32 encoded.
+func GenerateTotpSecret() (string, error) {
+	secret := make([]byte, totpSecretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("error generating TOTP secret: %v", err)
+	}
+	return totpEncoding.EncodeToString(secret), nil
+}
+
+// TotpCode returns the code of the secret for the period containing t.
+//
+// Parameters:
+//   - secret: The base32 encoded shared secret.
+//   - t: The time to compute the code for.
+//
+// Returns:
+//   - string: The zero-padded code.
+//   - error: An error if the secret is not valid base32.
+func TotpCode(secret string, t time.Time) (string, error) {
+	return totpCodeAt(secret, TotpStep(t))
+}
+
+// TotpStep returns the RFC 6238 time step (counter) containing t.
+func TotpStep(t time.Time) int64 {
+	return t.Unix() / int64(TotpPeriod/time.Second)
+}
+
+// VerifyTotp checks a code against the secret, accepting TotpSkew periods of drift.
+//
+// Parameters:
+//   - secret: The base32 encoded shared secret.
+//   - code: The code entered by the user.
+//   - t: The time of the verification.
+//
+// Returns:
+//   - int64: The time step the code matched, so that callers can reject its reuse.
+//   - bool: Whether the code is valid.
+func VerifyTotp(secret string, code string, t time.Time) (int64, bool) {
+	code = strings.TrimSpace(code)
+	if len(code) != TotpDigits {
+		return 0, false
+	}
+
+	current := TotpStep(t)
+	for offset := int64(-TotpSkew); offset <= TotpSkew; offset++ {
+		expected, err := totpCodeAt(secret, current+offset)
+		if err != nil {
+			return 0, false
+		}
+		if hmac.Equal([]byte(expected), []byte(code)) {
+			return current + offset, true
+		}
+	}
+	return 0, false
+}
+
+// TotpURI returns the otpauth:// URI authenticator apps import, usually through a QR code.
+//
+// Parameters:
+//   - issuer: The name of the service shown in the app.
+//   - account: The account name shown in the app, such as the user's email.
+//   - secret: The base32 encoded shared secret.
+func TotpURI(issuer string, account string, secret string) string {
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(TotpDigits))
+	query.Set("period", fmt.Sprint(int(TotpPeriod/time.Second)))
+
+	label := url.PathEscape(issuer + ":" + account)
+	return "otpauth://totp/" + label + "?" + query.Encode()
+}
+
+// totpCodeAt computes the HOTP value (RFC 4226) of the secret for a counter.
+func totpCodeAt(secret string, counter int64) (string, error) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(strings.TrimRight(secret, "=")))
+	if err != nil {
+		return "", fmt.Errorf("invalid TOTP secret: %v", err)
+	}
+
+	var message [8]byte
+	binary.BigEndian.PutUint64(message[:], uint64(counter))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(message[:])
+	sum := mac.Sum(nil)
+
+	// Dynamic truncation.
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	modulo := uint32(1)
+	for i := 0; i < TotpDigits; i++ {
+		modulo *= 10
+	}
+	return fmt.Sprintf("%0*d", TotpDigits, value%modulo), nil
+}
+
+// GenerateRecoveryCodes returns RecoveryCodeCount random codes formatted as xxxxx-xxxxx.
+// They are shown to the user once; only their HashRecoveryCode is stored.
+func GenerateRecoveryCodes() ([]string, error) {
+	codes := make([]string, RecoveryCodeCount)
+	random := make([]byte, 10)
+
+	for i := range codes {
+		if _, err := rand.Read(random); err != nil {
+			return nil, fmt.Errorf("error generating recovery codes: %v", err)
+		}
+
+		code := make([]byte, 0, len(random)+1)
+		for j, b := range random {
+			if j == len(random)/2 {
+				code = append(code, '-')
+			}
+			// 256 is not a multiple of the alphabet size, a negligible bias for codes
+			// that cannot be brute forced online anyway.
+			code = append(code, recoveryCodeAlphabet[int(b)%len(recoveryCodeAlphabet)])
+		}
+		codes[i] = string(code)
+	}
+	return codes, nil
+}
+
+// HashRecoveryCode returns the SHA-256 hex digest of a recovery code. The code is
+// normalized first, so that case, spaces and dashes typed by the user do not matter.
+// Recovery codes are long and random, so a fast hash is enough to protect them at rest.
+func HashRecoveryCode(code string) string {
+	normalized := strings.NewReplacer("-", "", " ", "").Replace(strings.ToLower(strings.TrimSpace(code)))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
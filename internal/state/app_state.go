@@ -6,6 +6,7 @@ import (
 	"github.com/go-playground/validator/v10"
 	"github.com/redis/go-redis/v9"
 	"github.com/starks97/alcohol-tracker-api/config"
+	"github.com/starks97/alcohol-tracker-api/internal/mailer"
 	"gorm.io/gorm"
 )
 
@@ -17,4 +18,5 @@ type AppState struct {
 	Config     *config.Config // Application configuration.
 	HttpClient *http.Client   // HTTP client for making external API requests.
 	Validator  *validator.Validate
+	Mailer     mailer.Mailer // Sends verification and account emails.
 }
@@ -0,0 +1,91 @@
+package strategies
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+
+	"github.com/starks97/alcohol-tracker-api/internal/dtos"
+	"github.com/starks97/alcohol-tracker-api/internal/services"
+	"github.com/starks97/alcohol-tracker-api/internal/state"
+)
+
+const appleKeysURL = "https://appleid.apple.com/auth/keys"
+
+// appleUser is the "user" field Apple posts to the callback, only the first time the user
+// authorizes the app. It is the only source of the user's name.
+type appleUser struct {
+	Name struct {
+		FirstName string `json:"firstName"`
+		LastName  string `json:"lastName"`
+	} `json:"name"`
+}
+
+// AppleStrategy implements Sign in with Apple. Apple posts the callback as a form, expects
+// client secrets signed with the team's key, and identifies users by the ID token only.
+type AppleStrategy struct {
+	AppState *state.AppState
+	nonce    string
+	user     appleUser
+}
+
+func (a *AppleStrategy) UseNonce(nonce string) {
+	a.nonce = nonce
+}
+
+func (a *AppleStrategy) UseCallbackForm(formValue func(key string) string) {
+	a.user = appleUser{}
+	if user := formValue("user"); user != "" {
+		// The name is optional; a malformed field only loses it.
+		_ = json.Unmarshal([]byte(user), &a.user)
+	}
+}
+
+func (a *AppleStrategy) GenerateAuthURL(state string, opts ...oauth2.AuthCodeOption) string {
+	opts = append(opts,
+		oauth2.SetAuthURLParam("response_mode", "form_post"),
+		oauth2.SetAuthURLParam("nonce", a.nonce),
+	)
+	return a.AppState.Config.AppleLoginConfig.AuthCodeURL(state, opts...)
+}
+
+func (a *AppleStrategy) ExchangeCode(ctx context.Context, code string, opts ...oauth2.AuthCodeOption) (*oauth2.Token, error) {
+	cfg := a.AppState.Config
+	clientSecret, err := services.GenerateAppleClientSecret(cfg.AppleTeamID, cfg.AppleLoginConfig.ClientID, cfg.AppleKeyID, cfg.ApplePrivateKey, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	loginConfig := cfg.AppleLoginConfig
+	loginConfig.ClientSecret = clientSecret
+	return exchangeCode(ctx, a.AppState, &loginConfig, code, opts...)
+}
+
+// GetUserInfo verifies Apple's ID token and returns its claims, with the name posted to
+// the callback, as a dtos.OAuthDto.
+func (a *AppleStrategy) GetUserInfo(ctx context.Context, token *oauth2.Token) (dtos.OAuthDto, error) {
+	rawIDToken, _ := token.Extra("id_token").(string)
+	if rawIDToken == "" {
+		return dtos.OAuthDto{}, errors.New("the token response has no ID token")
+	}
+
+	verifier := idTokenVerifier{
+		Issuer:   services.AppleAudience,
+		JwksURI:  appleKeysURL,
+		ClientID: a.AppState.Config.AppleLoginConfig.ClientID,
+		Nonce:    a.nonce,
+	}
+	claims, err := verifier.verify(ctx, a.AppState.HttpClient, services.AppleAudience, rawIDToken)
+	if err != nil {
+		return dtos.OAuthDto{}, err
+	}
+
+	user := claims.toOAuthDto()
+	user.GivenName, user.FamilyName = a.user.Name.FirstName, a.user.Name.LastName
+	user.Name = strings.TrimSpace(user.GivenName + " " + user.FamilyName)
+	return user, nil
+}
@@ -6,14 +6,26 @@ import (
 
 	"golang.org/x/oauth2"
 
+	"github.com/starks97/alcohol-tracker-api/internal/dtos"
 	"github.com/starks97/alcohol-tracker-api/internal/state"
 )
 
 // strategy pattern design
+//
+// The options of GenerateAuthURL and ExchangeCode carry the PKCE code challenge and
+// verifier of the flow. GetUserInfo fetches the provider's profile of the user with the
+// access token, and normalizes it.
 type AuthStrategy interface {
-	GenerateAuthURL(state string) string
-	ExchangeCode(ctx context.Context, code string) (*oauth2.Token, error)
-	GetUserInfo(token *oauth2.Token) ([]byte, error)
+	GenerateAuthURL(state string, opts ...oauth2.AuthCodeOption) string
+	ExchangeCode(ctx context.Context, code string, opts ...oauth2.AuthCodeOption) (*oauth2.Token, error)
+	GetUserInfo(ctx context.Context, token *oauth2.Token) (dtos.OAuthDto, error)
+}
+
+// FormPostStrategy is implemented by strategies whose provider posts the callback to the
+// server (response_mode=form_post) instead of redirecting with a query string. The posted
+// fields are handed to UseCallbackForm before GetUserInfo.
+type FormPostStrategy interface {
+	UseCallbackForm(formValue func(key string) string)
 }
 
 // factory pattern design
@@ -23,7 +35,22 @@ func NewAuthStrategy(appState *state.AppState, provider string) (AuthStrategy, e
 		return &GitHubStrategy{AppState: appState}, nil
 	case "google":
 		return &GoogleStrategy{AppState: appState}, nil
+	case "apple":
+		if appState.Config.AppleLoginConfig.ClientID != "" {
+			return &AppleStrategy{AppState: appState}, nil
+		}
+	case "microsoft":
+		if appState.Config.MicrosoftLoginConfig.ClientID != "" {
+			return &MicrosoftStrategy{AppState: appState}, nil
+		}
+	case "discord":
+		if appState.Config.DiscordLoginConfig.ClientID != "" {
+			return &DiscordStrategy{AppState: appState}, nil
+		}
 	default:
-		return nil, fmt.Errorf("unknown provider: %s", provider)
+		if oidcProvider, ok := appState.Config.OIDCProviders[provider]; ok {
+			return NewOIDCStrategy(context.Background(), appState, oidcProvider)
+		}
 	}
+	return nil, fmt.Errorf("unknown provider: %s", provider)
 }
@@ -0,0 +1,68 @@
+package strategies
+
+import (
+	"context"
+
+	"golang.org/x/oauth2"
+
+	"github.com/starks97/alcohol-tracker-api/internal/dtos"
+	"github.com/starks97/alcohol-tracker-api/internal/state"
+)
+
+const discordAPIURL = "https://discord.com/api/v10"
+
+// discordUser is the user object returned by the Discord API.
+type discordUser struct {
+	ID         string `json:"id"`
+	Username   string `json:"username"`
+	GlobalName string `json:"global_name"`
+	Email      string `json:"email"`
+	Verified   bool   `json:"verified"`
+	Avatar     string `json:"avatar"`
+}
+
+// toOAuthDto names users without a display name after their username.
+func (u discordUser) toOAuthDto() dtos.OAuthDto {
+	name := u.GlobalName
+	if name == "" {
+		name = u.Username
+	}
+
+	var picture string
+	if u.Avatar != "" {
+		picture = "https://cdn.discordapp.com/avatars/" + u.ID + "/" + u.Avatar + ".png"
+	}
+
+	return dtos.OAuthDto{
+		ID:            u.ID,
+		Email:         u.Email,
+		VerifiedEmail: u.Verified,
+		Name:          name,
+		Picture:       picture,
+	}
+}
+
+// DiscordStrategy implements login with Discord accounts.
+type DiscordStrategy struct {
+	AppState *state.AppState
+	APIURL   string // The Discord API, unless set.
+}
+
+func (d *DiscordStrategy) GenerateAuthURL(state string, opts ...oauth2.AuthCodeOption) string {
+	return d.AppState.Config.DiscordLoginConfig.AuthCodeURL(state, opts...)
+}
+
+func (d *DiscordStrategy) ExchangeCode(ctx context.Context, code string, opts ...oauth2.AuthCodeOption) (*oauth2.Token, error) {
+	return exchangeCode(ctx, d.AppState, &d.AppState.Config.DiscordLoginConfig, code, opts...)
+}
+
+// GetUserInfo returns the Discord user.
+func (d *DiscordStrategy) GetUserInfo(ctx context.Context, token *oauth2.Token) (dtos.OAuthDto, error) {
+	client := userInfoClient(ctx, d.AppState, &d.AppState.Config.DiscordLoginConfig, token)
+
+	var user discordUser
+	if err := getJSON(ctx, client, apiURL(d.APIURL, discordAPIURL)+"/users/@me", &user); err != nil {
+		return dtos.OAuthDto{}, err
+	}
+	return user.toOAuthDto(), nil
+}
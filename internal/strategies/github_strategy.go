@@ -2,30 +2,83 @@ package strategies
 
 import (
 	"context"
-	"io"
+	"strconv"
 
 	"golang.org/x/oauth2"
 
+	"github.com/starks97/alcohol-tracker-api/internal/dtos"
 	"github.com/starks97/alcohol-tracker-api/internal/state"
 )
 
+const githubAPIURL = "https://api.github.com"
+
+// githubUser is the profile returned by GitHub's /user endpoint. Its email is the public
+// one, often null, and is not known to be verified.
+type githubUser struct {
+	ID        int64  `json:"id"`
+	Login     string `json:"login"`
+	Name      string `json:"name"`
+	Email     string `json:"email"`
+	AvatarURL string `json:"avatar_url"`
+}
+
+// githubEmail is an address returned by GitHub's /user/emails endpoint.
+type githubEmail struct {
+	Email    string `json:"email"`
+	Primary  bool   `json:"primary"`
+	Verified bool   `json:"verified"`
+}
+
+// toOAuthDto uses the primary address of emails, the public one otherwise, and names users
+// without a name after their login.
+func (u githubUser) toOAuthDto(emails []githubEmail) dtos.OAuthDto {
+	user := dtos.OAuthDto{
+		ID:      strconv.FormatInt(u.ID, 10),
+		Email:   u.Email,
+		Name:    u.Name,
+		Picture: u.AvatarURL,
+	}
+	if user.Name == "" {
+		user.Name = u.Login
+	}
+
+	for _, email := range emails {
+		if email.Primary {
+			user.Email = email.Email
+			user.VerifiedEmail = email.Verified
+			break
+		}
+	}
+	return user
+}
+
 type GitHubStrategy struct {
 	AppState *state.AppState
+	APIURL   string // The GitHub API, unless set.
 }
 
-func (git *GitHubStrategy) GenerateAuthURL(state string) string {
-	return git.AppState.Config.GithubLoginConfig.AuthCodeURL(state, oauth2.AccessTypeOffline)
+func (git *GitHubStrategy) GenerateAuthURL(state string, opts ...oauth2.AuthCodeOption) string {
+	return git.AppState.Config.GithubLoginConfig.AuthCodeURL(state, opts...)
 }
 
-func (git *GitHubStrategy) ExchangeCode(ctx context.Context, code string) (*oauth2.Token, error) {
-	return git.AppState.Config.GithubLoginConfig.Exchange(ctx, code)
+func (git *GitHubStrategy) ExchangeCode(ctx context.Context, code string, opts ...oauth2.AuthCodeOption) (*oauth2.Token, error) {
+	return exchangeCode(ctx, git.AppState, &git.AppState.Config.GithubLoginConfig, code, opts...)
 }
 
-func (git *GitHubStrategy) GetUserInfo(token *oauth2.Token) ([]byte, error) {
-	resp, err := git.AppState.HttpClient.Get("https://api.github.com/user")
-	if err != nil {
-		return nil, err
+// GetUserInfo returns the GitHub profile of the user, with the primary email address from
+// /user/emails, the only endpoint telling whether it is verified.
+func (git *GitHubStrategy) GetUserInfo(ctx context.Context, token *oauth2.Token) (dtos.OAuthDto, error) {
+	client := userInfoClient(ctx, git.AppState, &git.AppState.Config.GithubLoginConfig, token)
+	baseURL := apiURL(git.APIURL, githubAPIURL)
+
+	var user githubUser
+	if err := getJSON(ctx, client, baseURL+"/user", &user); err != nil {
+		return dtos.OAuthDto{}, err
+	}
+
+	var emails []githubEmail
+	if err := getJSON(ctx, client, baseURL+"/user/emails", &emails); err != nil {
+		return dtos.OAuthDto{}, err
 	}
-	defer resp.Body.Close()
-	return io.ReadAll(resp.Body)
+	return user.toOAuthDto(emails), nil
 }
@@ -2,37 +2,51 @@ package strategies
 
 import (
 	"context"
-	"io"
 
 	"golang.org/x/oauth2"
 
+	"github.com/starks97/alcohol-tracker-api/internal/dtos"
 	"github.com/starks97/alcohol-tracker-api/internal/state"
 )
 
-type GoogleStrategy struct {
-	AppState *state.AppState
+const googleAPIURL = "https://www.googleapis.com"
+
+// googleUser is the profile returned by Google's userinfo endpoint.
+type googleUser struct {
+	ID            string `json:"id"`
+	Email         string `json:"email"`
+	VerifiedEmail bool   `json:"verified_email"`
+	Name          string `json:"name"`
+	GivenName     string `json:"given_name"`
+	FamilyName    string `json:"family_name"`
+	Picture       string `json:"picture"`
 }
 
-func (g *GoogleStrategy) GenerateAuthURL(state string) string {
-	return g.AppState.Config.GoogleLoginConfig.AuthCodeURL(state)
+func (u googleUser) toOAuthDto() dtos.OAuthDto {
+	return dtos.OAuthDto(u)
 }
 
-func (g *GoogleStrategy) ExchangeCode(ctx context.Context, code string) (*oauth2.Token, error) {
-	return g.AppState.Config.GoogleLoginConfig.Exchange(ctx, code)
+type GoogleStrategy struct {
+	AppState *state.AppState
+	APIURL   string // Google's API, unless set.
 }
 
-func (g *GoogleStrategy) GetUserInfo(token *oauth2.Token) ([]byte, error) {
-	// Directly using HttpClient.Get instead of creating a new request
-	resp, err := g.AppState.HttpClient.Get("https://www.googleapis.com/oauth2/v2/userinfo")
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
+// GenerateAuthURL asks for offline access, for Google to issue a refresh token.
+func (g *GoogleStrategy) GenerateAuthURL(state string, opts ...oauth2.AuthCodeOption) string {
+	return g.AppState.Config.GoogleLoginConfig.AuthCodeURL(state, append(opts, oauth2.AccessTypeOffline)...)
+}
 
-	// Set the Authorization header for the request
-	resp.Header.Set("Authorization", "Bearer "+token.AccessToken)
+func (g *GoogleStrategy) ExchangeCode(ctx context.Context, code string, opts ...oauth2.AuthCodeOption) (*oauth2.Token, error) {
+	return exchangeCode(ctx, g.AppState, &g.AppState.Config.GoogleLoginConfig, code, opts...)
+}
 
-	// Read and return the response body
-	return io.ReadAll(resp.Body)
+// GetUserInfo returns the Google profile of the user.
+func (g *GoogleStrategy) GetUserInfo(ctx context.Context, token *oauth2.Token) (dtos.OAuthDto, error) {
+	client := userInfoClient(ctx, g.AppState, &g.AppState.Config.GoogleLoginConfig, token)
 
+	var user googleUser
+	if err := getJSON(ctx, client, apiURL(g.APIURL, googleAPIURL)+"/oauth2/v2/userinfo", &user); err != nil {
+		return dtos.OAuthDto{}, err
+	}
+	return user.toOAuthDto(), nil
 }
@@ -0,0 +1,65 @@
+package strategies
+
+import (
+	"context"
+	"strings"
+
+	"golang.org/x/oauth2"
+
+	"github.com/starks97/alcohol-tracker-api/internal/dtos"
+	"github.com/starks97/alcohol-tracker-api/internal/state"
+)
+
+const microsoftGraphURL = "https://graph.microsoft.com"
+
+// microsoftUser is the profile returned by Microsoft Graph.
+type microsoftUser struct {
+	ID                string `json:"id"`
+	DisplayName       string `json:"displayName"`
+	GivenName         string `json:"givenName"`
+	Surname           string `json:"surname"`
+	Mail              string `json:"mail"`
+	UserPrincipalName string `json:"userPrincipalName"`
+}
+
+// toOAuthDto reports the email address unverified: directory administrators can set it to
+// any value. Personal accounts often have no mail, but sign in with their email address.
+func (u microsoftUser) toOAuthDto() dtos.OAuthDto {
+	email := u.Mail
+	if email == "" && strings.Contains(u.UserPrincipalName, "@") {
+		email = u.UserPrincipalName
+	}
+
+	return dtos.OAuthDto{
+		ID:         u.ID,
+		Email:      email,
+		Name:       u.DisplayName,
+		GivenName:  u.GivenName,
+		FamilyName: u.Surname,
+	}
+}
+
+// MicrosoftStrategy implements login with Microsoft (Entra ID) accounts.
+type MicrosoftStrategy struct {
+	AppState *state.AppState
+	APIURL   string // Microsoft Graph, unless set.
+}
+
+func (m *MicrosoftStrategy) GenerateAuthURL(state string, opts ...oauth2.AuthCodeOption) string {
+	return m.AppState.Config.MicrosoftLoginConfig.AuthCodeURL(state, opts...)
+}
+
+func (m *MicrosoftStrategy) ExchangeCode(ctx context.Context, code string, opts ...oauth2.AuthCodeOption) (*oauth2.Token, error) {
+	return exchangeCode(ctx, m.AppState, &m.AppState.Config.MicrosoftLoginConfig, code, opts...)
+}
+
+// GetUserInfo returns the Microsoft Graph profile of the user.
+func (m *MicrosoftStrategy) GetUserInfo(ctx context.Context, token *oauth2.Token) (dtos.OAuthDto, error) {
+	client := userInfoClient(ctx, m.AppState, &m.AppState.Config.MicrosoftLoginConfig, token)
+
+	var user microsoftUser
+	if err := getJSON(ctx, client, apiURL(m.APIURL, microsoftGraphURL)+"/v1.0/me", &user); err != nil {
+		return dtos.OAuthDto{}, err
+	}
+	return user.toOAuthDto(), nil
+}
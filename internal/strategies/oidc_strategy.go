@@ -0,0 +1,340 @@
+package strategies
+
+import (
+	"context"
+	"crypto"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/oauth2"
+
+	"github.com/starks97/alcohol-tracker-api/config"
+	"github.com/starks97/alcohol-tracker-api/internal/dtos"
+	"github.com/starks97/alcohol-tracker-api/internal/state"
+)
+
+// NonceStrategy is implemented by strategies whose ID tokens carry a nonce. The nonce is
+// set before GenerateAuthURL, and set again from the stored OAuth flow before GetUserInfo,
+// which rejects ID tokens issued for another nonce.
+type NonceStrategy interface {
+	UseNonce(nonce string)
+}
+
+const (
+	oidcDiscoveryTTL = time.Hour
+	oidcKeysTTL      = time.Hour
+	// oidcKeysMinRefresh limits how often an unknown key ID can trigger a JWKS download.
+	oidcKeysMinRefresh = time.Minute
+	oidcClockSkew      = time.Minute
+)
+
+// oidcSigningMethods are the ID token algorithms accepted. Symmetric algorithms and "none"
+// are never accepted.
+var oidcSigningMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+// oidcDiscovery is the part of an OpenID Provider's configuration document that is used.
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+	JwksURI               string `json:"jwks_uri"`
+}
+
+// oidcIssuer caches the discovery document and signing keys of an issuer across requests.
+type oidcIssuer struct {
+	mu            sync.Mutex
+	discovery     *oidcDiscovery
+	discoveredAt  time.Time
+	keys          map[string]crypto.PublicKey
+	keysFetchedAt time.Time
+}
+
+var (
+	oidcIssuersMu sync.Mutex
+	oidcIssuers   = map[string]*oidcIssuer{}
+)
+
+// flexibleBool decodes JSON booleans as well as the "true" and "false" strings some
+// providers send for email_verified.
+type flexibleBool bool
+
+func (b *flexibleBool) UnmarshalJSON(data []byte) error {
+	switch strings.Trim(string(data), `"`) {
+	case "true":
+		*b = true
+	case "false", "null", "":
+		*b = false
+	default:
+		return fmt.Errorf("invalid boolean %s", data)
+	}
+	return nil
+}
+
+// oidcClaims are the claims read from ID tokens and the userinfo endpoint.
+type oidcClaims struct {
+	jwt.RegisteredClaims
+	Nonce           string       `json:"nonce"`
+	AuthorizedParty string       `json:"azp"`
+	Email           string       `json:"email"`
+	EmailVerified   flexibleBool `json:"email_verified"`
+	Name            string       `json:"name"`
+	GivenName       string       `json:"given_name"`
+	FamilyName      string       `json:"family_name"`
+	Picture         string       `json:"picture"`
+}
+
+func (c *oidcClaims) toOAuthDto() dtos.OAuthDto {
+	return dtos.OAuthDto{
+		ID:            c.Subject,
+		Email:         c.Email,
+		VerifiedEmail: bool(c.EmailVerified),
+		Name:          c.Name,
+		GivenName:     c.GivenName,
+		FamilyName:    c.FamilyName,
+		Picture:       c.Picture,
+	}
+}
+
+// OIDCStrategy logs users in with an OpenID Connect provider declared in the configuration.
+// Endpoints come from the issuer's discovery document, and users are identified by the
+// claims of the ID token, whose signature, issuer, audience, expiry and nonce are checked.
+type OIDCStrategy struct {
+	AppState  *state.AppState
+	Provider  config.OIDCProviderConfig
+	discovery *oidcDiscovery
+	nonce     string
+}
+
+// NewOIDCStrategy returns the strategy of a provider, discovering its endpoints if they are
+// not cached yet.
+func NewOIDCStrategy(ctx context.Context, appState *state.AppState, provider config.OIDCProviderConfig) (*OIDCStrategy, error) {
+	discovery, err := issuerFor(provider.Issuer).getDiscovery(ctx, appState.HttpClient, provider.Issuer)
+	if err != nil {
+		return nil, err
+	}
+	return &OIDCStrategy{AppState: appState, Provider: provider, discovery: discovery}, nil
+}
+
+func (o *OIDCStrategy) UseNonce(nonce string) {
+	o.nonce = nonce
+}
+
+func (o *OIDCStrategy) GenerateAuthURL(state string, opts ...oauth2.AuthCodeOption) string {
+	return o.oauthConfig().AuthCodeURL(state, append(opts, oauth2.SetAuthURLParam("nonce", o.nonce))...)
+}
+
+func (o *OIDCStrategy) ExchangeCode(ctx context.Context, code string, opts ...oauth2.AuthCodeOption) (*oauth2.Token, error) {
+	return exchangeCode(ctx, o.AppState, o.oauthConfig(), code, opts...)
+}
+
+// GetUserInfo verifies the ID token returned with the access token and returns its claims
+// as a dtos.OAuthDto. When the ID token has no email, it is read from the userinfo endpoint.
+func (o *OIDCStrategy) GetUserInfo(ctx context.Context, token *oauth2.Token) (dtos.OAuthDto, error) {
+	rawIDToken, _ := token.Extra("id_token").(string)
+	if rawIDToken == "" {
+		return dtos.OAuthDto{}, errors.New("the token response has no ID token")
+	}
+
+	claims, err := o.verifyIDToken(ctx, rawIDToken)
+	if err != nil {
+		return dtos.OAuthDto{}, err
+	}
+
+	if claims.Email == "" && o.discovery.UserinfoEndpoint != "" {
+		if err := o.fetchUserinfo(ctx, token, claims); err != nil {
+			return dtos.OAuthDto{}, err
+		}
+	}
+
+	return claims.toOAuthDto(), nil
+}
+
+func (o *OIDCStrategy) oauthConfig() *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     o.Provider.ClientID,
+		ClientSecret: o.Provider.ClientSecret,
+		RedirectURL:  o.Provider.RedirectURL,
+		Scopes:       o.Provider.Scopes,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  o.discovery.AuthorizationEndpoint,
+			TokenURL: o.discovery.TokenEndpoint,
+		},
+	}
+}
+
+func (o *OIDCStrategy) verifyIDToken(ctx context.Context, rawIDToken string) (*oidcClaims, error) {
+	verifier := idTokenVerifier{
+		Issuer:   o.discovery.Issuer,
+		JwksURI:  o.discovery.JwksURI,
+		ClientID: o.Provider.ClientID,
+		Nonce:    o.nonce,
+	}
+	return verifier.verify(ctx, o.AppState.HttpClient, o.Provider.Issuer, rawIDToken)
+}
+
+// idTokenVerifier checks ID tokens issued to ClientID by Issuer, signed with a key of
+// JwksURI and bound to Nonce.
+type idTokenVerifier struct {
+	Issuer   string
+	JwksURI  string
+	ClientID string
+	Nonce    string
+}
+
+// verify parses an ID token and checks its signature and claims. cacheKey identifies the
+// issuer whose cached signing keys are used.
+func (v idTokenVerifier) verify(ctx context.Context, client *http.Client, cacheKey string, rawIDToken string) (*oidcClaims, error) {
+	if v.Nonce == "" {
+		return nil, errors.New("no nonce to check the ID token against")
+	}
+
+	issuer := issuerFor(cacheKey)
+	parser := jwt.NewParser(
+		jwt.WithValidMethods(oidcSigningMethods),
+		jwt.WithIssuer(v.Issuer),
+		jwt.WithAudience(v.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(oidcClockSkew),
+	)
+
+	var claims oidcClaims
+	_, err := parser.ParseWithClaims(rawIDToken, &claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return issuer.getKey(ctx, client, v.JwksURI, kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid ID token: %w", err)
+	}
+
+	if claims.Subject == "" {
+		return nil, errors.New("invalid ID token: no subject")
+	}
+	if subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(v.Nonce)) != 1 {
+		return nil, errors.New("invalid ID token: nonce mismatch")
+	}
+	if (len(claims.Audience) > 1 || claims.AuthorizedParty != "") && claims.AuthorizedParty != v.ClientID {
+		return nil, errors.New("invalid ID token: authorized party mismatch")
+	}
+	return &claims, nil
+}
+
+// fetchUserinfo completes the claims with those of the userinfo endpoint, which must be
+// about the same subject.
+func (o *OIDCStrategy) fetchUserinfo(ctx context.Context, token *oauth2.Token, claims *oidcClaims) error {
+	var userinfo oidcClaims
+	client := userInfoClient(ctx, o.AppState, o.oauthConfig(), token)
+	if err := getJSON(ctx, client, o.discovery.UserinfoEndpoint, &userinfo); err != nil {
+		return err
+	}
+	if userinfo.Subject != claims.Subject {
+		return errors.New("userinfo is about another subject than the ID token")
+	}
+
+	claims.Email = userinfo.Email
+	claims.EmailVerified = userinfo.EmailVerified
+	if claims.Name == "" {
+		claims.Name = userinfo.Name
+	}
+	if claims.Picture == "" {
+		claims.Picture = userinfo.Picture
+	}
+	return nil
+}
+
+func issuerFor(issuerURL string) *oidcIssuer {
+	oidcIssuersMu.Lock()
+	defer oidcIssuersMu.Unlock()
+
+	issuer, ok := oidcIssuers[issuerURL]
+	if !ok {
+		issuer = &oidcIssuer{}
+		oidcIssuers[issuerURL] = issuer
+	}
+	return issuer
+}
+
+// getDiscovery returns the issuer's discovery document, downloading it when it is missing
+// or stale. A stale document is still used if the issuer cannot be reached.
+func (i *oidcIssuer) getDiscovery(ctx context.Context, client *http.Client, issuerURL string) (*oidcDiscovery, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.discovery != nil && time.Since(i.discoveredAt) < oidcDiscoveryTTL {
+		return i.discovery, nil
+	}
+
+	var discovery oidcDiscovery
+	err := getJSON(ctx, client, issuerURL+"/.well-known/openid-configuration", &discovery)
+	if err == nil && strings.TrimRight(discovery.Issuer, "/") != issuerURL {
+		err = fmt.Errorf("discovery document is for issuer %q, expected %q", discovery.Issuer, issuerURL)
+	}
+	if err == nil && (discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JwksURI == "") {
+		err = errors.New("discovery document is missing endpoints")
+	}
+	if err != nil {
+		if i.discovery != nil {
+			log.Println("Failed to refresh OIDC discovery, using the cached document:", err)
+			return i.discovery, nil
+		}
+		return nil, fmt.Errorf("OIDC discovery of %s failed: %w", issuerURL, err)
+	}
+
+	i.discovery = &discovery
+	i.discoveredAt = time.Now()
+	return i.discovery, nil
+}
+
+// getKey returns the signing key with the given ID, downloading the key set when it is
+// stale or, at most every oidcKeysMinRefresh, when the key is unknown because the issuer
+// rotated its keys. Tokens without a key ID are accepted only from single-key sets.
+func (i *oidcIssuer) getKey(ctx context.Context, client *http.Client, jwksURI string, kid string) (crypto.PublicKey, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	key, known := i.lookupKey(kid)
+	stale := time.Since(i.keysFetchedAt) >= oidcKeysTTL
+	if (known && !stale) || (!known && !stale && time.Since(i.keysFetchedAt) < oidcKeysMinRefresh) {
+		if !known {
+			return nil, fmt.Errorf("unknown signing key %q", kid)
+		}
+		return key, nil
+	}
+
+	var set jsonWebKeySet
+	if err := getJSON(ctx, client, jwksURI, &set); err != nil {
+		if known {
+			log.Println("Failed to refresh OIDC signing keys, using the cached keys:", err)
+			return key, nil
+		}
+		return nil, fmt.Errorf("failed to download signing keys: %w", err)
+	}
+	i.keys = set.signingKeys()
+	i.keysFetchedAt = time.Now()
+
+	if key, known = i.lookupKey(kid); !known {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	return key, nil
+}
+
+func (i *oidcIssuer) lookupKey(kid string) (crypto.PublicKey, bool) {
+	if kid == "" {
+		if len(i.keys) != 1 {
+			return nil, false
+		}
+		for _, key := range i.keys {
+			return key, true
+		}
+	}
+	key, ok := i.keys[kid]
+	return key, ok
+}
@@ -0,0 +1,68 @@
+package strategies
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"golang.org/x/oauth2"
+
+	"github.com/starks97/alcohol-tracker-api/internal/state"
+)
+
+// providerRequestTimeout bounds every request to a provider, including the code exchange.
+const providerRequestTimeout = 10 * time.Second
+
+// providerContext returns a context making the oauth2 package use the application's HTTP
+// client, so token requests share its transport and timeout.
+func providerContext(ctx context.Context, appState *state.AppState) context.Context {
+	return context.WithValue(ctx, oauth2.HTTPClient, appState.HttpClient)
+}
+
+// exchangeCode exchanges an authorization code with a time limit.
+func exchangeCode(ctx context.Context, appState *state.AppState, loginConfig *oauth2.Config, code string, opts ...oauth2.AuthCodeOption) (*oauth2.Token, error) {
+	ctx, cancel := context.WithTimeout(providerContext(ctx, appState), providerRequestTimeout)
+	defer cancel()
+	return loginConfig.Exchange(ctx, code, opts...)
+}
+
+// userInfoClient returns an HTTP client sending the access token of token with every
+// request, refreshing it when it expires.
+func userInfoClient(ctx context.Context, appState *state.AppState, loginConfig *oauth2.Config, token *oauth2.Token) *http.Client {
+	return loginConfig.Client(providerContext(ctx, appState), token)
+}
+
+// getJSON downloads and decodes a JSON document of at most 1 MiB.
+func getJSON(ctx context.Context, client *http.Client, url string, target interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, providerRequestTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s", url, resp.Status)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(target)
+}
+
+// apiURL returns override if set, for tests against a stub provider, or the provider's
+// API URL.
+func apiURL(override string, defaultURL string) string {
+	if override != "" {
+		return override
+	}
+	return defaultURL
+}
@@ -0,0 +1,75 @@
+package units
+
+import "math"
+
+// Unit systems a user can pick for body measurements.
+const (
+	UnitSystemMetric   = "metric"
+	UnitSystemImperial = "imperial"
+)
+
+const (
+	kgPerPound = 0.45359237
+	cmPerInch  = 2.54
+)
+
+// Upper bounds of the body measurements a user can store, in the stored units.
+const (
+	MaxWeightKg = 500
+	MaxHeightCm = 300
+)
+
+// UnitSystemOrDefault returns system, or the metric system when it is empty.
+func UnitSystemOrDefault(system string) string {
+	if system == "" {
+		return UnitSystemMetric
+	}
+	return system
+}
+
+// WeightToKg converts a weight expressed in the given unit system (kg or lb) to kilograms.
+func WeightToKg(value float64, system string) float64 {
+	if system == UnitSystemImperial {
+		return value * kgPerPound
+	}
+	return value
+}
+
+// WeightFromKg converts a weight in kilograms to the given unit system, rounded to one decimal.
+func WeightFromKg(kg float64, system string) float64 {
+	if system == UnitSystemImperial {
+		return roundTo(kg/kgPerPound, 1)
+	}
+	return roundTo(kg, 1)
+}
+
+// HeightToCm converts a height expressed in the given unit system (cm or in) to centimetres.
+func HeightToCm(value float64, system string) float64 {
+	if system == UnitSystemImperial {
+		return value * cmPerInch
+	}
+	return value
+}
+
+// HeightFromCm converts a height in centimetres to the given unit system, rounded to one decimal.
+func HeightFromCm(cm float64, system string) float64 {
+	if system == UnitSystemImperial {
+		return roundTo(cm/cmPerInch, 1)
+	}
+	return roundTo(cm, 1)
+}
+
+// ValidWeightKg reports whether a weight in kilograms is positive and at most MaxWeightKg.
+func ValidWeightKg(kg float64) bool {
+	return kg > 0 && kg <= MaxWeightKg
+}
+
+// ValidHeightCm reports whether a height in centimetres is positive and at most MaxHeightCm.
+func ValidHeightCm(cm float64) bool {
+	return cm > 0 && cm <= MaxHeightCm
+}
+
+func roundTo(value float64, decimals int) float64 {
+	factor := math.Pow(10, float64(decimals))
+	return math.Round(value*factor) / factor
+}
@@ -0,0 +1,47 @@
+package units
+
+import "math"
+
+// EthanolKcalPerGram is the energy content of pure ethanol in kilocalories per gram.
+const EthanolKcalPerGram = 7.0
+
+// CarbohydrateKcalPerGram is the energy content of carbohydrates in kilocalories per gram.
+const CarbohydrateKcalPerGram = 4.0
+
+// carbohydratesPer100Ml is a typical carbohydrate content, in grams per 100 ml, of each
+// drink category. Spirits are served neat; cocktails assume sugary mixers.
+var carbohydratesPer100Ml = map[string]float64{
+	"beer":     3.6,
+	"wine":     2.6,
+	"spirits":  0,
+	"cider":    4.5,
+	"cocktail": 8,
+	"other":    2,
+}
+
+// EthanolCalories returns the kilocalories provided by the given grams of ethanol alone.
+func EthanolCalories(ethanolGrams float64) float64 {
+	return ethanolGrams * EthanolKcalPerGram
+}
+
+// EstimateCalories estimates the kilocalories in a drink from its ethanol content plus the
+// typical carbohydrates of its category. Unknown categories count ethanol only.
+//
+// Parameters:
+//   - volumeMl: The volume of the drink in millilitres.
+//   - abv: The alcohol by volume as a percentage (0-100).
+//   - category: The drink category, such as "beer" or "wine".
+func EstimateCalories(volumeMl float64, abv float64, category string) float64 {
+	carbohydrateGrams := volumeMl / 100 * carbohydratesPer100Ml[category]
+	return EthanolCalories(EthanolGrams(volumeMl, abv)) + carbohydrateGrams*CarbohydrateKcalPerGram
+}
+
+// RoundCalories rounds a calorie amount to whole kilocalories, as shown in responses.
+func RoundCalories(kcal float64) float64 {
+	return math.Round(kcal)
+}
+
+// RoundMoney rounds an amount of money to cents, as shown in responses.
+func RoundMoney(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,88 @@
+package units
+
+import "sort"
+
+// EthanolDensity is the density of ethanol in grams per millilitre.
+const EthanolDensity = 0.789
+
+// DefaultDrinkLocale is used when neither the user nor the configuration picks a locale.
+const DefaultDrinkLocale = "US"
+
+// standardDrinkGrams maps a locale to the grams of pure ethanol in one of its standard drinks.
+// "UK" uses a 10 g standard drink, like most of Europe, while "UK_UNIT" counts in the
+// British unit of alcohol of 8 g (10 ml) that UK guidelines and labels use. "WHO" is the
+// 10 g reference used in WHO publications.
+var standardDrinkGrams = map[string]float64{
+	"AT":      20,
+	"AU":      10,
+	"CA":      13.45,
+	"ES":      10,
+	"FR":      10,
+	"IE":      10,
+	"IT":      12,
+	"JP":      20,
+	"NL":      10,
+	"NZ":      10,
+	"UK":      10,
+	"UK_UNIT": 8,
+	"US":      14,
+	"WHO":     10,
+}
+
+// EthanolGrams returns the grams of pure ethanol in a drink of the given volume and ABV.
+//
+// Parameters:
+//   - volumeMl: The volume of the drink in millilitres.
+//   - abv: The alcohol by volume as a percentage (0-100).
+func EthanolGrams(volumeMl float64, abv float64) float64 {
+	return volumeMl * (abv / 100) * EthanolDensity
+}
+
+// StandardDrinks converts grams of ethanol into standard drinks as defined by the locale.
+// Unknown locales fall back to DefaultDrinkLocale.
+func StandardDrinks(ethanolGrams float64, locale string) float64 {
+	return ethanolGrams / GramsPerStandardDrink(locale)
+}
+
+// GramsPerStandardDrink returns the grams of ethanol in one standard drink of the locale.
+// Unknown locales fall back to DefaultDrinkLocale.
+func GramsPerStandardDrink(locale string) float64 {
+	if grams, ok := standardDrinkGrams[locale]; ok {
+		return grams
+	}
+	return standardDrinkGrams[DefaultDrinkLocale]
+}
+
+// IsSupportedLocale reports whether a standard drink definition exists for the locale.
+func IsSupportedLocale(locale string) bool {
+	_, ok := standardDrinkGrams[locale]
+	return ok
+}
+
+// SupportedLocales returns the locales with a standard drink definition, sorted alphabetically.
+func SupportedLocales() []string {
+	locales := make([]string, 0, len(standardDrinkGrams))
+	for locale := range standardDrinkGrams {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// ResolveLocale returns the user's locale when set, or the fallback otherwise.
+func ResolveLocale(userLocale *string, fallback string) string {
+	if userLocale != nil && *userLocale != "" {
+		return *userLocale
+	}
+	return fallback
+}
+
+// RoundGrams rounds a quantity of ethanol to one decimal place.
+func RoundGrams(grams float64) float64 {
+	return roundTo(grams, 1)
+}
+
+// RoundStandardDrinks rounds a number of standard drinks to two decimal places.
+func RoundStandardDrinks(drinks float64) float64 {
+	return roundTo(drinks, 2)
+}
@@ -1,9 +1,14 @@
 package utils
 
 import (
+	"crypto/aes"
+	"crypto/cipher"
 	"crypto/rand"
 	"encoding/base64"
+	"errors"
 	"fmt"
+	"regexp"
+	"strings"
 )
 
 func GenerateRandomString(length int) (string, error) {
@@ -13,3 +18,158 @@ func GenerateRandomString(length int) (string, error) {
 	}
 	return base64.RawURLEncoding.EncodeToString(b), nil
 }
+
+// encryptedPrefix starts every value encrypted by a KeyRing. A value is
+// "enc:<key ID>:<wrapped data key>:<ciphertext>", both base64url-encoded.
+const encryptedPrefix = "enc:"
+
+// encryptionKeyIDPattern limits key IDs to characters that cannot be mistaken for the
+// separators of encrypted values.
+var encryptionKeyIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,32}$`)
+
+// KeyRing encrypts values with envelope encryption: each value is encrypted with AES-256-GCM
+// under a fresh data key, and the data key is encrypted (wrapped) under the active key
+// encryption key. The ID of that key is kept with the value, so values encrypted under a
+// previous key can still be decrypted after a rotation, and re-encrypted.
+type KeyRing struct {
+	keys     map[string]cipher.AEAD
+	activeID string
+}
+
+// NewKeyRing returns a key ring encrypting with the key activeID.
+//
+// Parameters:
+//   - keys: The 32-byte key encryption keys, by key ID.
+//   - activeID: The ID of the key new values are encrypted with.
+//
+// Returns:
+//   - *KeyRing: The key ring.
+//   - error: An error if a key ID or key is invalid, or activeID is not one of the keys.
+func NewKeyRing(keys map[string][]byte, activeID string) (*KeyRing, error) {
+	ring := &KeyRing{keys: make(map[string]cipher.AEAD, len(keys)), activeID: activeID}
+	for id, key := range keys {
+		if !encryptionKeyIDPattern.MatchString(id) {
+			return nil, fmt.Errorf("invalid encryption key ID %q", id)
+		}
+		if len(key) != 32 {
+			return nil, fmt.Errorf("encryption key %q must be 32 bytes, got %d", id, len(key))
+		}
+		aead, err := newGCM(key)
+		if err != nil {
+			return nil, err
+		}
+		ring.keys[id] = aead
+	}
+
+	if _, ok := ring.keys[activeID]; !ok {
+		return nil, fmt.Errorf("active encryption key %q is not in the key ring", activeID)
+	}
+	return ring, nil
+}
+
+// ActiveKeyID returns the ID of the key new values are encrypted with.
+func (r *KeyRing) ActiveKeyID() string {
+	return r.activeID
+}
+
+// Encrypt encrypts plaintext under a new data key wrapped by the active key.
+func (r *KeyRing) Encrypt(plaintext string) (string, error) {
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", fmt.Errorf("Encrypt: %w", err)
+	}
+	dataAEAD, err := newGCM(dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	// The key ID is authenticated with both layers, so it cannot be swapped.
+	aad := []byte(r.activeID)
+	wrappedKey, err := seal(r.keys[r.activeID], dataKey, aad)
+	if err != nil {
+		return "", err
+	}
+	ciphertext, err := seal(dataAEAD, []byte(plaintext), aad)
+	if err != nil {
+		return "", err
+	}
+
+	return encryptedPrefix + r.activeID + ":" +
+		base64.RawURLEncoding.EncodeToString(wrappedKey) + ":" +
+		base64.RawURLEncoding.EncodeToString(ciphertext), nil
+}
+
+// Decrypt decrypts a value returned by Encrypt, with whichever key of the ring it names.
+func (r *KeyRing) Decrypt(value string) (string, error) {
+	keyID, wrappedKey, ciphertext, err := parseEncrypted(value)
+	if err != nil {
+		return "", err
+	}
+	keyAEAD, ok := r.keys[keyID]
+	if !ok {
+		return "", fmt.Errorf("unknown encryption key %q", keyID)
+	}
+
+	aad := []byte(keyID)
+	dataKey, err := open(keyAEAD, wrappedKey, aad)
+	if err != nil {
+		return "", err
+	}
+	dataAEAD, err := newGCM(dataKey)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := open(dataAEAD, ciphertext, aad)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// KeyID returns the ID of the key an encrypted value was encrypted with.
+func (r *KeyRing) KeyID(value string) (string, error) {
+	keyID, _, _, err := parseEncrypted(value)
+	return keyID, err
+}
+
+func parseEncrypted(value string) (string, []byte, []byte, error) {
+	parts := strings.Split(strings.TrimPrefix(value, encryptedPrefix), ":")
+	if !strings.HasPrefix(value, encryptedPrefix) || len(parts) != 3 {
+		return "", nil, nil, errors.New("malformed encrypted value")
+	}
+
+	wrappedKey, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", nil, nil, errors.New("malformed encrypted value")
+	}
+	ciphertext, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", nil, nil, errors.New("malformed encrypted value")
+	}
+	return parts[0], wrappedKey, ciphertext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts plaintext under a random nonce, which it prepends to the ciphertext.
+func seal(aead cipher.AEAD, plaintext []byte, aad []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("seal: %w", err)
+	}
+	return aead.Seal(nonce, nonce, plaintext, aad), nil
+}
+
+func open(aead cipher.AEAD, sealed []byte, aad []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("malformed encrypted value")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, aad)
+}
@@ -0,0 +1,106 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/starks97/alcohol-tracker-api/internal/entities"
+	"github.com/starks97/alcohol-tracker-api/internal/exceptions"
+	"github.com/starks97/alcohol-tracker-api/internal/mailer"
+	"github.com/starks97/alcohol-tracker-api/internal/state"
+)
+
+// Email verification tokens are signed one-time tokens standing for "<user ID> <email>",
+// so that a token stops working if the address it was sent to is no longer the user's.
+const (
+	EmailVerificationPurpose = "email_verification"
+	EmailVerificationTTL     = 24 * time.Hour
+	// EmailVerificationCooldown is the minimum time between two emails sent on request.
+	EmailVerificationCooldown = time.Minute
+)
+
+// emailVerificationSentKeyPrefix prefixes the Redis keys enforcing the resend cooldown,
+// email_verification_sent:<user>.
+const emailVerificationSentKeyPrefix = "email_verification_sent:"
+
+// backgroundMailTimeout bounds emails sent after the response has been returned.
+const backgroundMailTimeout = 30 * time.Second
+
+// NewEmailVerificationStore returns the store of email verification tokens.
+func NewEmailVerificationStore(appState *state.AppState) *OneTimeTokenStore {
+	return NewSignedOneTimeTokenStore(appState, EmailVerificationPurpose, EmailVerificationTTL)
+}
+
+// SendEmailVerification issues a verification token for the user's email address and mails
+// them the link to the client's verification page.
+//
+// Returns:
+//   - error: The error of the token store, or exceptions.ErrEmailNotSent if mailing failed.
+func SendEmailVerification(ctx context.Context, appState *state.AppState, user *entities.User) error {
+	token, err := NewEmailVerificationStore(appState).Issue(ctx, user.ID.String()+" "+user.Email)
+	if err != nil {
+		return err
+	}
+
+	message := mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease confirm your email address by opening the link below:\n\n%s\n\nThe link expires in %d hours. If you did not create an account, you can ignore this email.\n",
+			user.Name, ClientURL(appState, "/verify-email", token), int(EmailVerificationTTL/time.Hour)),
+	}
+	if err := appState.Mailer.Send(ctx, message); err != nil {
+		log.Println("Failed to send verification email:", err)
+		return exceptions.ErrEmailNotSent
+	}
+	return nil
+}
+
+// ParseEmailVerification splits the value of an email verification token.
+func ParseEmailVerification(value string) (uuid.UUID, string, bool) {
+	rawID, email, found := strings.Cut(value, " ")
+	if !found {
+		return uuid.Nil, "", false
+	}
+	userID, err := uuid.Parse(rawID)
+	if err != nil {
+		return uuid.Nil, "", false
+	}
+	return userID, email, true
+}
+
+// ReserveEmailVerification starts the resend cooldown of a user, reporting false when an
+// email was already sent to them less than EmailVerificationCooldown ago.
+func ReserveEmailVerification(ctx context.Context, appState *state.AppState, userID uuid.UUID) (bool, error) {
+	reserved, err := appState.Redis.SetNX(ctx, emailVerificationSentKeyPrefix+userID.String(), 1, EmailVerificationCooldown).Result()
+	if err != nil {
+		log.Println("Failed to reserve verification email:", err)
+		return false, exceptions.ErrRedisSet
+	}
+	return reserved, nil
+}
+
+// ClientURL returns the link to a page of the client application carrying a token. When
+// several client origins are configured, the first one is used.
+func ClientURL(appState *state.AppState, path string, token string) string {
+	origin, _, _ := strings.Cut(appState.Config.ClientOrigin, ",")
+	return strings.TrimRight(strings.TrimSpace(origin), "/") + path + "?token=" + url.QueryEscape(token)
+}
+
+// RunInBackground runs a task, such as sending an email, after the handler has returned,
+// so that a slow mail server does not delay the response. Errors are only logged.
+func RunInBackground(task string, fn func(ctx context.Context) error) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), backgroundMailTimeout)
+		defer cancel()
+
+		if err := fn(ctx); err != nil {
+			log.Printf("Failed to %s: %v", task, err)
+		}
+	}()
+}
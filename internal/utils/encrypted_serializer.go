@@ -0,0 +1,89 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm/schema"
+)
+
+// encryptionKeyRing encrypts the fields of the "encrypted" serializer. GORM serializers are
+// registered globally, so the key ring is too.
+var encryptionKeyRing *KeyRing
+
+func init() {
+	schema.RegisterSerializer("encrypted", EncryptedSerializer{})
+}
+
+// SetEncryptionKeyRing sets the key ring of the "encrypted" serializer. It must be called
+// before any encrypted field is read or written.
+func SetEncryptionKeyRing(ring *KeyRing) {
+	encryptionKeyRing = ring
+}
+
+// EncryptedSerializer encrypts string and *string fields tagged `gorm:"serializer:encrypted"`
+// when they are written, and decrypts them when they are read. A nil *string stays NULL.
+//
+// GORM applies serializers to creates and struct updates only: encrypted fields must not be
+// written with map updates.
+type EncryptedSerializer struct{}
+
+// Scan decrypts the column value into the field.
+func (EncryptedSerializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	fieldValue := field.ReflectValueOf(ctx, dst)
+	if dbValue == nil {
+		fieldValue.Set(reflect.Zero(field.FieldType))
+		return nil
+	}
+	if encryptionKeyRing == nil {
+		return errors.New("no encryption key ring set")
+	}
+
+	var value string
+	switch v := dbValue.(type) {
+	case string:
+		value = v
+	case []byte:
+		value = string(v)
+	default:
+		return fmt.Errorf("cannot decrypt %T into %s", dbValue, field.Name)
+	}
+
+	plaintext, err := encryptionKeyRing.Decrypt(value)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt %s: %w", field.Name, err)
+	}
+
+	switch field.FieldType.Kind() {
+	case reflect.String:
+		fieldValue.SetString(plaintext)
+	case reflect.Ptr:
+		fieldValue.Set(reflect.ValueOf(&plaintext))
+	default:
+		return fmt.Errorf("cannot decrypt into %s of type %s", field.Name, field.FieldType)
+	}
+	return nil
+}
+
+// Value encrypts the field value with the active key.
+func (EncryptedSerializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	var plaintext string
+	switch v := fieldValue.(type) {
+	case string:
+		plaintext = v
+	case *string:
+		if v == nil {
+			return nil, nil
+		}
+		plaintext = *v
+	default:
+		return nil, fmt.Errorf("cannot encrypt %s of type %T", field.Name, fieldValue)
+	}
+
+	if encryptionKeyRing == nil {
+		return nil, errors.New("no encryption key ring set")
+	}
+	return encryptionKeyRing.Encrypt(plaintext)
+}
@@ -0,0 +1,200 @@
+package utils
+
+import (
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/starks97/alcohol-tracker-api/internal/dtos"
+	"github.com/starks97/alcohol-tracker-api/internal/entities"
+	"github.com/starks97/alcohol-tracker-api/internal/exceptions"
+	"github.com/starks97/alcohol-tracker-api/internal/repositories"
+	"github.com/starks97/alcohol-tracker-api/internal/state"
+)
+
+// ResolveOAuthUser returns the user an OAuth login is for.
+//
+// Users are found by their identity at the provider. A provider account that is not linked
+// yet signs up a new user, or is linked to the existing account with the same email when
+// both the provider and the account have verified that address. Otherwise whoever
+// registered the email first could take over the provider user's account, so the login is
+// refused and the user has to link the provider while logged in.
+//
+// Parameters:
+//   - appState: The application state holding the database connection.
+//   - provider: The name of the provider.
+//   - oauthUser: The user information returned by the provider.
+//   - refreshToken: The provider's refresh token, stored encrypted on the identity; empty
+//     if it issued none.
+//
+// Returns:
+//   - *entities.User: The user to log in.
+//   - error: exceptions.ErrIdentityEmailInUse when the email belongs to an account that
+//     cannot be linked automatically, or another sentinel error of the exceptions package.
+func ResolveOAuthUser(appState *state.AppState, provider string, oauthUser dtos.OAuthDto, refreshToken string) (*entities.User, error) {
+	userRepo := repositories.NewUserRepository(appState.DB)
+	identityRepo := repositories.NewIdentityRepository(appState.DB)
+
+	identity, err := identityRepo.GetIdentity(provider, oauthUser.ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Println("Failed to get identity:", err)
+		return nil, exceptions.ErrDatabase
+	}
+
+	var user *entities.User
+	if identity != nil {
+		user, err = userRepo.GetUserByID(identity.UserID)
+		if err != nil {
+			log.Println("Failed to get user:", err)
+			return nil, exceptions.ErrDatabase
+		}
+	} else {
+		if oauthUser.Email == "" {
+			return nil, exceptions.ErrToReadUserInfo
+		}
+
+		user, err = userRepo.GetUserByEmail(oauthUser.Email)
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return createOAuthUser(appState, provider, oauthUser, refreshToken)
+		case err != nil:
+			log.Println("Failed to get user:", err)
+			return nil, exceptions.ErrDatabase
+		case !oauthUser.VerifiedEmail || !user.EmailVerified:
+			return nil, exceptions.ErrIdentityEmailInUse
+		}
+
+		if _, err := identityRepo.CreateIdentity(newIdentity(user.ID, provider, oauthUser, refreshToken)); err != nil {
+			log.Println("Failed to link identity:", err)
+			return nil, exceptions.ErrUserNotUpdated
+		}
+	}
+
+	// Apple sends the name only on the first authorization, and some providers never send
+	// a picture; keep the stored values rather than blanking them.
+	if oauthUser.Picture != "" {
+		user.ProfilePicture = &oauthUser.Picture
+	}
+	if oauthUser.Name != "" {
+		user.Name = oauthUser.Name
+	}
+
+	if _, err := userRepo.UpdateUser(user); err != nil {
+		log.Println("Failed to update user:", err)
+		return nil, exceptions.ErrUserNotUpdated
+	}
+
+	// Providers issue refresh tokens only on some logins; keep the last one otherwise. A
+	// newly linked identity was created with it.
+	if identity != nil && refreshToken != "" {
+		if err := identityRepo.UpdateRefreshToken(identity.ID, &refreshToken); err != nil {
+			log.Println("Failed to store provider refresh token:", err)
+			return nil, exceptions.ErrUserNotUpdated
+		}
+	}
+
+	markProviderEmailVerified(appState, user, oauthUser)
+	return user, nil
+}
+
+// LinkOAuthIdentity links a provider account to a logged-in user, keeping the provider's
+// refresh token, if it issued one, on the new identity.
+//
+// Returns:
+//   - error: exceptions.ErrIdentityLinked when the provider account belongs to another
+//     user, exceptions.ErrProviderLinked when the user is linked to another account at the
+//     provider, or another sentinel error of the exceptions package.
+func LinkOAuthIdentity(appState *state.AppState, userID uuid.UUID, provider string, oauthUser dtos.OAuthDto, refreshToken string) error {
+	userRepo := repositories.NewUserRepository(appState.DB)
+	identityRepo := repositories.NewIdentityRepository(appState.DB)
+
+	identity, err := identityRepo.GetIdentity(provider, oauthUser.ID)
+	if err == nil {
+		if identity.UserID != userID {
+			return exceptions.ErrIdentityLinked
+		}
+		return nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Println("Failed to get identity:", err)
+		return exceptions.ErrDatabase
+	}
+
+	identities, err := identityRepo.ListIdentities(userID)
+	if err != nil {
+		log.Println("Failed to list identities:", err)
+		return exceptions.ErrDatabase
+	}
+	for _, linked := range identities {
+		if linked.Provider == provider {
+			return exceptions.ErrProviderLinked
+		}
+	}
+
+	if _, err := identityRepo.CreateIdentity(newIdentity(userID, provider, oauthUser, refreshToken)); err != nil {
+		log.Println("Failed to link identity:", err)
+		return exceptions.ErrUserNotUpdated
+	}
+
+	user, err := userRepo.GetUserByID(userID)
+	if err != nil {
+		log.Println("Failed to get user:", err)
+		return nil
+	}
+	markProviderEmailVerified(appState, user, oauthUser)
+	return nil
+}
+
+// createOAuthUser signs up a user with their first identity.
+func createOAuthUser(appState *state.AppState, provider string, oauthUser dtos.OAuthDto, refreshToken string) (*entities.User, error) {
+	user := &entities.User{
+		Name:          oauthUser.Name,
+		Email:         oauthUser.Email,
+		EmailVerified: oauthUser.VerifiedEmail,
+	}
+	if oauthUser.Picture != "" {
+		user.ProfilePicture = &oauthUser.Picture
+	}
+
+	err := appState.DB.Transaction(func(tx *gorm.DB) error {
+		if _, err := repositories.NewUserRepository(tx).CreateUser(user); err != nil {
+			return err
+		}
+		_, err := repositories.NewIdentityRepository(tx).CreateIdentity(newIdentity(user.ID, provider, oauthUser, refreshToken))
+		return err
+	})
+	if err != nil {
+		log.Println("Failed to create user:", err)
+		return nil, exceptions.ErrUserNotCreated
+	}
+	return user, nil
+}
+
+func newIdentity(userID uuid.UUID, provider string, oauthUser dtos.OAuthDto, refreshToken string) *entities.UserIdentity {
+	identity := &entities.UserIdentity{
+		UserID:         userID,
+		Provider:       provider,
+		ProviderUserID: oauthUser.ID,
+		Email:          oauthUser.Email,
+	}
+	if refreshToken != "" {
+		identity.RefreshToken = &refreshToken
+	}
+	return identity
+}
+
+// markProviderEmailVerified marks the user's email address verified when the provider
+// vouches for the same address. Failures are only logged.
+func markProviderEmailVerified(appState *state.AppState, user *entities.User, oauthUser dtos.OAuthDto) {
+	if user.EmailVerified || !oauthUser.VerifiedEmail || !strings.EqualFold(user.Email, oauthUser.Email) {
+		return
+	}
+	if _, err := repositories.NewUserRepository(appState.DB).MarkEmailVerified(user.ID, user.Email); err != nil {
+		log.Println("Failed to verify email:", err)
+		return
+	}
+	user.EmailVerified = true
+}
@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/starks97/alcohol-tracker-api/internal/entities"
+	"github.com/starks97/alcohol-tracker-api/internal/repositories"
+	"github.com/starks97/alcohol-tracker-api/internal/services"
+	"github.com/starks97/alcohol-tracker-api/internal/state"
+	"github.com/starks97/alcohol-tracker-api/internal/units"
+)
+
+// EvaluateUserLimits loads the user's limits and checks their drink log against them.
+// Days and weeks follow the user's time zone.
+//
+// Parameters:
+//   - appState: The application state holding the database and configuration.
+//   - user: The user whose limits are evaluated.
+//   - now: The reference time of the evaluation.
+//
+// Returns:
+//   - *entities.DrinkingLimit: The user's limits, or nil when none have been set.
+//   - *services.LimitStatus: The evaluation result, or nil when no limits have been set.
+//   - error: An error if the limits or drinks could not be loaded.
+func EvaluateUserLimits(appState *state.AppState, user *entities.User, now time.Time) (*entities.DrinkingLimit, *services.LimitStatus, error) {
+	limit, err := repositories.NewLimitRepository(appState.DB).GetLimit(user.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+
+	loc := UserLocation(user)
+
+	drinks, err := limitDrinks(appState, user, now, loc)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	status := services.EvaluateLimits(services.Limits{
+		DailyStandardDrinks:    limit.DailyStandardDrinks,
+		WeeklyStandardDrinks:   limit.WeeklyStandardDrinks,
+		AlcoholFreeDaysPerWeek: limit.AlcoholFreeDaysPerWeek,
+	}, drinks, now, loc)
+
+	return limit, &status, nil
+}
+
+// limitDrinks returns the user's drinks over the streak lookback window, expressed in
+// standard drinks of the user's locale.
+func limitDrinks(appState *state.AppState, user *entities.User, now time.Time, loc *time.Location) ([]services.LimitDrink, error) {
+	from := services.StartOfDay(now, loc).AddDate(0, 0, -services.LimitStreakLookbackDays)
+
+	drinks, _, err := repositories.NewDrinkRepository(appState.DB).ListDrinks(user.ID, repositories.DrinkFilter{From: &from, To: &now})
+	if err != nil {
+		return nil, err
+	}
+
+	locale := units.ResolveLocale(user.DrinkLocale, appState.Config.DefaultDrinkLocale)
+	limitDrinks := make([]services.LimitDrink, 0, len(drinks))
+	for _, drink := range drinks {
+		limitDrinks = append(limitDrinks, services.LimitDrink{
+			StandardDrinks: units.StandardDrinks(units.EthanolGrams(drink.VolumeMl, drink.ABV), locale),
+			ConsumedAt:     drink.ConsumedAt,
+		})
+	}
+	return limitDrinks, nil
+}
@@ -0,0 +1,180 @@
+package utils
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/starks97/alcohol-tracker-api/internal/state"
+)
+
+// Redis key prefixes of the login guard. login_failures:<scope>:<id> counts the recent
+// failed logins of an email address or IP address, and login_lock:<scope>:<id> exists
+// while further attempts are refused. Email addresses are hashed so that they do not
+// appear in key names.
+const (
+	loginFailuresKeyPrefix = "login_failures:"
+	loginLockKeyPrefix     = "login_lock:"
+)
+
+// LoginPolicy is how many failures a scope tolerates before locking, and how the lock grows.
+type LoginPolicy struct {
+	FreeAttempts int64         // Failures allowed before the first lock.
+	BaseLock     time.Duration // Lock after the first failure beyond FreeAttempts; doubles with each further one.
+	MaxLock      time.Duration
+	Window       time.Duration // Failures are forgotten after this long without a new one.
+}
+
+// Login guard policies. IP addresses are shared behind NATs and proxies, so they tolerate
+// more failures than a single email address does.
+var (
+	EmailLoginPolicy = LoginPolicy{FreeAttempts: 5, BaseLock: 30 * time.Second, MaxLock: 15 * time.Minute, Window: time.Hour}
+	IPLoginPolicy    = LoginPolicy{FreeAttempts: 20, BaseLock: 30 * time.Second, MaxLock: 15 * time.Minute, Window: time.Hour}
+)
+
+// LockAfter returns how long a scope is locked after its failures-th recent failure: not
+// at all within FreeAttempts, then BaseLock doubled with each further failure and capped
+// at MaxLock.
+func (p LoginPolicy) LockAfter(failures int64) time.Duration {
+	beyond := failures - p.FreeAttempts
+	if beyond <= 0 {
+		return 0
+	}
+
+	lock := p.BaseLock
+	for i := int64(1); i < beyond && lock < p.MaxLock; i++ {
+		lock *= 2
+	}
+	return min(lock, p.MaxLock)
+}
+
+// RetryAfterSeconds returns the Retry-After header value for a lock, in whole seconds
+// rounded up so that a client retrying on time is not refused again.
+func RetryAfterSeconds(lock time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(lock.Seconds())))
+}
+
+// countLoginFailureScript counts a failure and restarts the window forgetting them.
+//
+// KEYS: failures key.
+// ARGV: window in ms.
+// Returns the failures counted in the window.
+var countLoginFailureScript = redis.NewScript(`
+local failures = redis.call('INCR', KEYS[1])
+redis.call('PEXPIRE', KEYS[1], ARGV[1])
+return failures
+`)
+
+// extendLoginLockScript locks a scope, unless a longer lock is already set by a concurrent
+// failure.
+//
+// KEYS: lock key.
+// ARGV: lock in ms.
+var extendLoginLockScript = redis.NewScript(`
+if redis.call('PTTL', KEYS[1]) < tonumber(ARGV[1]) then
+	redis.call('SET', KEYS[1], 1, 'PX', ARGV[1])
+end
+return 0
+`)
+
+// LoginGuard slows down password guessing with failed-attempt counters per email address
+// and per IP address, locking them for exponentially longer after too many failures.
+//
+// Redis failures are logged and let logins through: the guard is a brake, and an outage
+// of it should not lock everyone out.
+type LoginGuard struct {
+	redis *redis.Client
+}
+
+func NewLoginGuard(appState *state.AppState) *LoginGuard {
+	return &LoginGuard{redis: appState.Redis}
+}
+
+// Locked returns how long logins for the email address or from the IP address are still
+// refused, or zero when they are allowed.
+func (g *LoginGuard) Locked(ctx context.Context, email string, ip string) time.Duration {
+	var longest time.Duration
+	for _, key := range []string{g.lockKey("email", emailDigest(email)), g.lockKey("ip", ip)} {
+		ttl, err := g.redis.PTTL(ctx, key).Result()
+		if err != nil {
+			log.Println("Failed to check login lock:", err)
+			continue
+		}
+		if ttl > longest {
+			longest = ttl
+		}
+	}
+	return longest
+}
+
+// RecordFailure counts a failed login for the email address and the IP address.
+func (g *LoginGuard) RecordFailure(ctx context.Context, email string, ip string) {
+	g.recordFailure(ctx, "email", emailDigest(email), EmailLoginPolicy)
+	g.recordFailure(ctx, "ip", ip, IPLoginPolicy)
+}
+
+// RecordSuccess forgets the failures of the email address. Those of the IP address are
+// kept, so that logging into one account does not reset guessing at others.
+func (g *LoginGuard) RecordSuccess(ctx context.Context, email string) {
+	digest := emailDigest(email)
+	if err := g.redis.Del(ctx, g.failuresKey("email", digest), g.lockKey("email", digest)).Err(); err != nil {
+		log.Println("Failed to reset login failures:", err)
+	}
+}
+
+func (g *LoginGuard) recordFailure(ctx context.Context, scope string, id string, policy LoginPolicy) {
+	failures, err := countLoginFailureScript.Run(ctx, g.redis, []string{g.failuresKey(scope, id)}, policy.Window.Milliseconds()).Int64()
+	if err != nil {
+		log.Println("Failed to record login failure:", err)
+		return
+	}
+
+	lock := policy.LockAfter(failures)
+	if lock == 0 {
+		return
+	}
+	if err := extendLoginLockScript.Run(ctx, g.redis, []string{g.lockKey(scope, id)}, lock.Milliseconds()).Err(); err != nil {
+		log.Println("Failed to lock login:", err)
+	}
+}
+
+func (g *LoginGuard) failuresKey(scope string, id string) string {
+	return loginFailuresKeyPrefix + scope + ":" + id
+}
+
+func (g *LoginGuard) lockKey(scope string, id string) string {
+	return loginLockKeyPrefix + scope + ":" + id
+}
+
+func emailDigest(email string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(email))))
+	return hex.EncodeToString(sum[:])
+}
+
+var (
+	dummyPasswordHash     []byte
+	dummyPasswordHashOnce sync.Once
+)
+
+// CompareDummyPassword spends the time of a bcrypt comparison without a real hash to
+// compare against, so that logins for unknown emails take as long as wrong passwords.
+func CompareDummyPassword(password string) {
+	dummyPasswordHashOnce.Do(func() {
+		hash, err := bcrypt.GenerateFromPassword([]byte("dummy password"), bcrypt.DefaultCost)
+		if err != nil {
+			log.Println("Failed to generate dummy password hash:", err)
+			return
+		}
+		dummyPasswordHash = hash
+	})
+	_ = bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+}
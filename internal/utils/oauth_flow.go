@@ -0,0 +1,89 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/oauth2"
+
+	"github.com/starks97/alcohol-tracker-api/internal/exceptions"
+	"github.com/starks97/alcohol-tracker-api/internal/state"
+)
+
+// Modes of an OAuth flow: logging in (or signing up), or linking the provider account to
+// the user who started the flow.
+const (
+	OAuthFlowLogin = "login"
+	OAuthFlowLink  = "link"
+)
+
+// OAuthFlowTTL is how long the user has to complete the provider's login page.
+const OAuthFlowTTL = 10 * time.Minute
+
+const oauthFlowPurpose = "oauth_flow"
+
+// OAuthFlow is what the server remembers about an OAuth authorization between the
+// redirect to the provider and the callback.
+type OAuthFlow struct {
+	Mode     string    `json:"mode"`
+	Provider string    `json:"provider"`
+	UserID   uuid.UUID `json:"user_id,omitempty"` // The user linking the provider, in link mode.
+	Nonce    string    `json:"nonce,omitempty"`   // The nonce the ID token must carry, for OpenID Connect providers.
+	Verifier string    `json:"verifier"`          // The PKCE code verifier, sent only with the code exchange.
+}
+
+// OAuthFlowStore keeps OAuth flows in Redis. The one-time token standing for a flow is
+// used as the OAuth state parameter, so each callback can be completed only once. Keeping
+// the PKCE verifier server-side means an intercepted code cannot be exchanged by anyone
+// but this server, for the flow that requested it.
+type OAuthFlowStore struct {
+	tokens *OneTimeTokenStore
+}
+
+func NewOAuthFlowStore(appState *state.AppState) *OAuthFlowStore {
+	return &OAuthFlowStore{tokens: NewOneTimeTokenStore(appState, oauthFlowPurpose, OAuthFlowTTL)}
+}
+
+// Start stores a flow with a new PKCE code verifier.
+//
+// Returns:
+//   - string: The state parameter identifying the flow.
+//   - oauth2.AuthCodeOption: The S256 code challenge to add to the authorization URL.
+//   - error: exceptions.ErrRedisSet if the flow could not be stored.
+func (s *OAuthFlowStore) Start(ctx context.Context, flow OAuthFlow) (string, oauth2.AuthCodeOption, error) {
+	flow.Verifier = oauth2.GenerateVerifier()
+
+	encoded, err := json.Marshal(flow)
+	if err != nil {
+		return "", nil, exceptions.ErrRedisSet
+	}
+
+	state, err := s.tokens.Issue(ctx, string(encoded))
+	if err != nil {
+		return "", nil, err
+	}
+	return state, oauth2.S256ChallengeOption(flow.Verifier), nil
+}
+
+// Finish redeems the flow of a state parameter.
+//
+// Returns:
+//   - error: exceptions.ErrOAuthState when the state is unknown, expired or already used.
+func (s *OAuthFlowStore) Finish(ctx context.Context, state string) (OAuthFlow, error) {
+	value, err := s.tokens.Consume(ctx, state)
+	if err != nil {
+		if errors.Is(err, exceptions.ErrRedisNotFound) {
+			return OAuthFlow{}, exceptions.ErrOAuthState
+		}
+		return OAuthFlow{}, err
+	}
+
+	var flow OAuthFlow
+	if err := json.Unmarshal([]byte(value), &flow); err != nil || flow.Verifier == "" {
+		return OAuthFlow{}, exceptions.ErrOAuthState
+	}
+	return flow, nil
+}
@@ -0,0 +1,186 @@
+package utils
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/starks97/alcohol-tracker-api/internal/exceptions"
+	"github.com/starks97/alcohol-tracker-api/internal/state"
+)
+
+// oneTimeTokenKeyPrefix prefixes the Redis keys of one-time tokens. The full key is
+// one_time_token:<purpose>:<SHA-256 of the token>, a hash holding the value the token
+// stands for and the number of failed attempts made with it. Only the hash of the token
+// is stored, so reading Redis does not reveal usable tokens.
+const oneTimeTokenKeyPrefix = "one_time_token:"
+
+// oneTimeTokenBytes is the amount of randomness in a token.
+const oneTimeTokenBytes = 32
+
+// failOneTimeTokenScript counts a failed attempt on an existing token, and deletes the
+// token once KEYS[1] has ARGV[1] failed attempts. Missing tokens are left alone rather
+// than recreated without an expiry. Returns the attempts so far, or -1 if there is no token.
+var failOneTimeTokenScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return -1
+end
+local attempts = redis.call('HINCRBY', KEYS[1], 'attempts', 1)
+if attempts >= tonumber(ARGV[1]) then
+	redis.call('DEL', KEYS[1])
+end
+return attempts
+`)
+
+// OneTimeTokenStore issues short-lived random tokens that stand for a value, such as the
+// user a login challenge belongs to, and can be redeemed only once. Like TokenFamilyStore,
+// it reports failures with the sentinel errors of the exceptions package.
+//
+// Tokens of a signed store carry an HMAC of the token and its purpose, so that forged or
+// mistyped tokens, such as ones copied out of an email, are rejected without a Redis lookup.
+type OneTimeTokenStore struct {
+	redis   *redis.Client
+	purpose string
+	ttl     time.Duration
+	secret  []byte // Nil for unsigned tokens.
+}
+
+// NewOneTimeTokenStore returns a store for one kind of token. Tokens of different purposes
+// never redeem each other.
+func NewOneTimeTokenStore(appState *state.AppState, purpose string, ttl time.Duration) *OneTimeTokenStore {
+	return &OneTimeTokenStore{redis: appState.Redis, purpose: purpose, ttl: ttl}
+}
+
+// NewSignedOneTimeTokenStore returns a store whose tokens are signed with the configured
+// TokenSigningSecret.
+func NewSignedOneTimeTokenStore(appState *state.AppState, purpose string, ttl time.Duration) *OneTimeTokenStore {
+	store := NewOneTimeTokenStore(appState, purpose, ttl)
+	store.secret = []byte(appState.Config.TokenSigningSecret)
+	return store
+}
+
+// Issue creates a token standing for value.
+//
+// Returns:
+//   - string: The token, to hand to the client.
+//   - error: exceptions.ErrRedisSet if the token could not be stored.
+func (s *OneTimeTokenStore) Issue(ctx context.Context, value string) (string, error) {
+	token, err := GenerateRandomString(oneTimeTokenBytes)
+	if err != nil {
+		log.Println("Failed to generate one-time token:", err)
+		return "", exceptions.ErrRedisSet
+	}
+
+	key := s.key(token)
+	_, err = s.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, key, "value", value, "attempts", 0)
+		pipe.Expire(ctx, key, s.ttl)
+		return nil
+	})
+	if err != nil {
+		log.Println("Failed to store one-time token:", err)
+		return "", exceptions.ErrRedisSet
+	}
+
+	if s.secret != nil {
+		token += "." + s.signature(token)
+	}
+	return token, nil
+}
+
+// Lookup returns the value of a token without redeeming it.
+//
+// Returns:
+//   - error: exceptions.ErrRedisNotFound when the token is unknown, expired or redeemed.
+func (s *OneTimeTokenStore) Lookup(ctx context.Context, token string) (string, error) {
+	token, ok := s.unsign(token)
+	if !ok {
+		return "", exceptions.ErrRedisNotFound
+	}
+
+	value, err := s.redis.HGet(ctx, s.key(token), "value").Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return "", exceptions.ErrRedisNotFound
+		}
+		log.Println("Failed to get one-time token:", err)
+		return "", exceptions.ErrRedisGet
+	}
+	return value, nil
+}
+
+// Consume redeems a token and returns its value. Only one of several concurrent calls
+// with the same token succeeds.
+//
+// Returns:
+//   - error: exceptions.ErrRedisNotFound when the token is unknown, expired or redeemed.
+func (s *OneTimeTokenStore) Consume(ctx context.Context, token string) (string, error) {
+	token, ok := s.unsign(token)
+	if !ok {
+		return "", exceptions.ErrRedisNotFound
+	}
+	key := s.key(token)
+
+	var get *redis.StringCmd
+	_, err := s.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		get = pipe.HGet(ctx, key, "value")
+		pipe.Del(ctx, key)
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		log.Println("Failed to consume one-time token:", err)
+		return "", exceptions.ErrRedisGet
+	}
+
+	value, err := get.Result()
+	if err != nil {
+		return "", exceptions.ErrRedisNotFound
+	}
+	return value, nil
+}
+
+// Fail records a failed attempt made with a token, and discards the token once
+// maxAttempts attempts have failed. Failures are only logged.
+func (s *OneTimeTokenStore) Fail(ctx context.Context, token string, maxAttempts int64) {
+	token, ok := s.unsign(token)
+	if !ok {
+		return
+	}
+	if err := failOneTimeTokenScript.Run(ctx, s.redis, []string{s.key(token)}, maxAttempts).Err(); err != nil {
+		log.Println("Failed to record one-time token attempt:", err)
+	}
+}
+
+func (s *OneTimeTokenStore) key(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return oneTimeTokenKeyPrefix + s.purpose + ":" + hex.EncodeToString(sum[:])
+}
+
+// signature returns the HMAC of a token of this store's purpose.
+func (s *OneTimeTokenStore) signature(token string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(s.purpose + ":" + token))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// unsign checks and strips the signature of a token. Tokens of unsigned stores are
+// returned unchanged.
+func (s *OneTimeTokenStore) unsign(token string) (string, bool) {
+	if s.secret == nil {
+		return token, true
+	}
+
+	raw, signature, found := strings.Cut(token, ".")
+	if !found || !hmac.Equal([]byte(signature), []byte(s.signature(raw))) {
+		return "", false
+	}
+	return raw, true
+}
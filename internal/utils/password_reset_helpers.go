@@ -0,0 +1,137 @@
+package utils
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/starks97/alcohol-tracker-api/internal/entities"
+	"github.com/starks97/alcohol-tracker-api/internal/exceptions"
+	"github.com/starks97/alcohol-tracker-api/internal/mailer"
+	"github.com/starks97/alcohol-tracker-api/internal/repositories"
+	"github.com/starks97/alcohol-tracker-api/internal/state"
+)
+
+// Password reset tokens are signed one-time tokens standing for "<user ID> <fingerprint>",
+// where the fingerprint is derived from the password hash at the time of the request.
+// Any password change therefore invalidates the other outstanding reset links.
+const (
+	PasswordResetPurpose = "password_reset"
+	PasswordResetTTL     = 30 * time.Minute
+	// PasswordResetCooldown is the minimum time between two reset emails to one address.
+	PasswordResetCooldown = time.Minute
+)
+
+// passwordResetSentKeyPrefix prefixes the Redis keys enforcing the cooldown,
+// password_reset_sent:<SHA-256 of the email>.
+const passwordResetSentKeyPrefix = "password_reset_sent:"
+
+// NewPasswordResetStore returns the store of password reset tokens.
+func NewPasswordResetStore(appState *state.AppState) *OneTimeTokenStore {
+	return NewSignedOneTimeTokenStore(appState, PasswordResetPurpose, PasswordResetTTL)
+}
+
+// SendPasswordReset mails a password reset link to the user with the given email, if there
+// is one. Unknown addresses and requests within the cooldown are silently ignored, so the
+// caller can answer the same way whatever happens here.
+//
+// Returns:
+//   - error: An error if the user could not be read or the email could not be sent.
+func SendPasswordReset(ctx context.Context, appState *state.AppState, email string) error {
+	user, err := repositories.NewUserRepository(appState.DB).GetUserByEmail(email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	emailSum := sha256.Sum256([]byte(strings.ToLower(email)))
+	reserved, err := appState.Redis.SetNX(ctx, passwordResetSentKeyPrefix+hex.EncodeToString(emailSum[:]), 1, PasswordResetCooldown).Result()
+	if err != nil {
+		return err
+	}
+	if !reserved {
+		return nil
+	}
+
+	token, err := NewPasswordResetStore(appState).Issue(ctx, user.ID.String()+" "+PasswordFingerprint(user))
+	if err != nil {
+		return err
+	}
+
+	message := mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nSomeone asked to reset the password of your account. To choose a new password, open the link below:\n\n%s\n\nThe link expires in %d minutes and works once. If you did not ask for it, you can ignore this email; your password has not changed.\n",
+			user.Name, ClientURL(appState, "/reset-password", token), int(PasswordResetTTL/time.Minute)),
+	}
+	return appState.Mailer.Send(ctx, message)
+}
+
+// PasswordFingerprint returns a short digest of the user's password hash, or of the
+// absence of a password.
+func PasswordFingerprint(user *entities.User) string {
+	password := ""
+	if user.Password != nil {
+		password = *user.Password
+	}
+	sum := sha256.Sum256([]byte(password))
+	return hex.EncodeToString(sum[:8])
+}
+
+// ParsePasswordReset splits the value of a password reset token.
+func ParsePasswordReset(value string) (uuid.UUID, string, bool) {
+	rawID, fingerprint, found := strings.Cut(value, " ")
+	if !found {
+		return uuid.Nil, "", false
+	}
+	userID, err := uuid.Parse(rawID)
+	if err != nil {
+		return uuid.Nil, "", false
+	}
+	return userID, fingerprint, true
+}
+
+// RedeemPasswordReset consumes a password reset token and returns the user it was issued
+// for, provided their password has not changed since.
+//
+// Returns:
+//   - error: exceptions.ErrPasswordResetToken when the token is invalid, expired, used or
+//     outdated, or the error of the store that failed.
+func RedeemPasswordReset(ctx context.Context, appState *state.AppState, token string) (*entities.User, error) {
+	value, err := NewPasswordResetStore(appState).Consume(ctx, token)
+	if err != nil {
+		if errors.Is(err, exceptions.ErrRedisNotFound) {
+			return nil, exceptions.ErrPasswordResetToken
+		}
+		return nil, err
+	}
+
+	userID, fingerprint, ok := ParsePasswordReset(value)
+	if !ok {
+		return nil, exceptions.ErrPasswordResetToken
+	}
+
+	user, err := repositories.NewUserRepository(appState.DB).GetUserByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, exceptions.ErrPasswordResetToken
+		}
+		log.Println("Failed to get user:", err)
+		return nil, exceptions.ErrDatabase
+	}
+
+	if PasswordFingerprint(user) != fingerprint {
+		return nil, exceptions.ErrPasswordResetToken
+	}
+	return user, nil
+}
@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/starks97/alcohol-tracker-api/internal/exceptions"
+)
+
+// ListQuery holds the date range and pagination shared by the list endpoints. It has the
+// same fields as the repository filters, so it converts to them directly.
+type ListQuery struct {
+	From   *time.Time
+	To     *time.Time
+	Limit  int
+	Offset int
+}
+
+// ParseListQuery reads the from and to (RFC 3339), limit and offset query parameters.
+//
+// Parameters:
+//   - c: The request context.
+//   - defaultLimit: The limit used when none is given.
+//   - maxLimit: The largest limit allowed; larger ones are capped to it.
+//
+// Returns:
+//   - ListQuery: The parsed parameters.
+//   - error: exceptions.ErrInvalidQuery if a parameter is malformed.
+func ParseListQuery(c *fiber.Ctx, defaultLimit int, maxLimit int) (ListQuery, error) {
+	query := ListQuery{Limit: defaultLimit}
+
+	if from := c.Query("from"); from != "" {
+		parsed, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			return query, exceptions.ErrInvalidQuery
+		}
+		query.From = &parsed
+	}
+
+	if to := c.Query("to"); to != "" {
+		parsed, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			return query, exceptions.ErrInvalidQuery
+		}
+		query.To = &parsed
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		parsed, err := strconv.Atoi(limit)
+		if err != nil || parsed <= 0 {
+			return query, exceptions.ErrInvalidQuery
+		}
+		query.Limit = min(parsed, maxLimit)
+	}
+
+	if offset := c.Query("offset"); offset != "" {
+		parsed, err := strconv.Atoi(offset)
+		if err != nil || parsed < 0 {
+			return query, exceptions.ErrInvalidQuery
+		}
+		query.Offset = parsed
+	}
+
+	return query, nil
+}
@@ -0,0 +1,126 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/starks97/alcohol-tracker-api/config"
+	"github.com/starks97/alcohol-tracker-api/internal/state"
+)
+
+// rateLimitKeyPrefix prefixes the Redis counters of the rate limiter,
+// rate_limit:<policy>:<key>:<window number>.
+const rateLimitKeyPrefix = "rate_limit:"
+
+// slidingWindowScript counts a request in the current fixed window unless the sliding
+// window estimate, the previous window's count weighted by how much of it still overlaps
+// the sliding window plus the current count, has reached the limit.
+//
+// KEYS: current window counter, previous window counter.
+// ARGV: limit, window in ms, elapsed time of the current window in ms.
+// Returns {allowed (0 or 1), current count, previous count}.
+var slidingWindowScript = redis.NewScript(`
+local current = tonumber(redis.call('GET', KEYS[1]) or '0')
+local previous = tonumber(redis.call('GET', KEYS[2]) or '0')
+local window = tonumber(ARGV[2])
+local estimate = previous * (window - tonumber(ARGV[3])) / window + current
+if estimate >= tonumber(ARGV[1]) then
+	return {0, current, previous}
+end
+current = redis.call('INCR', KEYS[1])
+redis.call('PEXPIRE', KEYS[1], window * 2)
+return {1, current, previous}
+`)
+
+// RateLimitResult is the outcome of a rate limited request, as reported in the
+// RateLimit-* headers.
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration // Until the current window ends.
+	RetryAfter time.Duration // Until a rejected request would be allowed; zero when allowed.
+}
+
+// RateLimiter counts requests with sliding window counters in Redis.
+type RateLimiter struct {
+	redis *redis.Client
+}
+
+func NewRateLimiter(appState *state.AppState) *RateLimiter {
+	return &RateLimiter{redis: appState.Redis}
+}
+
+// Allow counts a request for the key under a policy, unless the policy's limit has been
+// reached.
+//
+// Parameters:
+//   - ctx: The request context.
+//   - name: The name of the policy, keeping the counters of different policies apart.
+//   - key: The client the request is counted for, such as its IP address.
+//   - policy: The limit and window.
+//   - now: The time of the request.
+//
+// Returns:
+//   - RateLimitResult: Whether the request is allowed, with the limit's state.
+//   - error: An error if Redis failed.
+func (l *RateLimiter) Allow(ctx context.Context, name string, key string, policy config.RateLimitPolicy, now time.Time) (RateLimitResult, error) {
+	windowMs := policy.Window.Milliseconds()
+	windowNumber := now.UnixMilli() / windowMs
+	elapsed := now.UnixMilli() % windowMs
+
+	prefix := rateLimitKeyPrefix + name + ":" + key + ":"
+	keys := []string{prefix + fmt.Sprint(windowNumber), prefix + fmt.Sprint(windowNumber-1)}
+
+	result, err := slidingWindowScript.Run(ctx, l.redis, keys, policy.Limit, windowMs, elapsed).Int64Slice()
+	if err != nil {
+		log.Println("Failed to check rate limit:", err)
+		return RateLimitResult{}, err
+	}
+
+	return SlidingWindowResult(policy, time.Duration(elapsed)*time.Millisecond, result[1], result[2], result[0] == 1), nil
+}
+
+// SlidingWindowResult derives the state of a sliding window limit from the counts of the
+// current and previous fixed windows.
+//
+// Parameters:
+//   - policy: The limit and window.
+//   - elapsed: How much of the current fixed window has passed.
+//   - current: The requests counted in the current window, including an allowed one.
+//   - previous: The requests counted in the previous window.
+//   - allowed: Whether the request was allowed.
+func SlidingWindowResult(policy config.RateLimitPolicy, elapsed time.Duration, current int64, previous int64, allowed bool) RateLimitResult {
+	window := policy.Window.Seconds()
+	left := window - elapsed.Seconds()
+	limit := float64(policy.Limit)
+
+	estimate := float64(previous)*left/window + float64(current)
+	remaining := int(math.Max(0, math.Floor(limit-estimate)))
+
+	result := RateLimitResult{
+		Allowed:   allowed,
+		Limit:     policy.Limit,
+		Remaining: remaining,
+		Reset:     time.Duration(left * float64(time.Second)),
+	}
+	if allowed {
+		return result
+	}
+
+	// Wait until the weighted previous count has shrunk enough, possibly into the next
+	// window, where the current count becomes the previous one.
+	var wait float64
+	if float64(current) < limit && previous > 0 {
+		wait = left - (limit-float64(current))*window/float64(previous)
+	} else {
+		wait = left + window*(1-limit/float64(current))
+	}
+	result.RetryAfter = time.Duration(math.Max(1, math.Ceil(wait)) * float64(time.Second))
+	return result
+}
@@ -18,6 +18,7 @@ import (
 
 type RedisCmdMethos interface {
 	StoreToken(c *fiber.Ctx, ctx context.Context, userID uuid.UUID, tokenMethodKey string) (dtos.TokenDetailsDto, error)
+	RotateTokens(c *fiber.Ctx, ctx context.Context, presented dtos.TokenDetailsDto) (dtos.TokenDetailsDto, error)
 	GetRedisValue(c *fiber.Ctx, redisClient *redis.Client, ctx context.Context, expectedValue string) (string, error)
 	SetRedisValue(c *fiber.Ctx, redisClient *redis.Client, ctx context.Context, key string, value string, expiration time.Duration) error
 	RemoveRedisKeys(c *fiber.Ctx, redisClient *redis.Client, ctx context.Context, keys ...string) error
@@ -37,23 +38,20 @@ func (ts *TokenService) StoreToken(c *fiber.Ctx, ctx context.Context, userID uui
 	var accessToken, refreshToken string
 	var accessUUID, refreshUUID uuid.UUID
 	var accessMaxAge, refreshMaxAge time.Duration
-	var accessPrivateKey, refreshPrivateKey string
 	var accessMaxAgeInt64, refreshMaxAgeInt64 int64
 
 	// Fetch configurations
 	accessMaxAge = time.Duration(ts.AppState.Config.AccessTokenMaxAge) * time.Minute
-	accessPrivateKey = ts.AppState.Config.AccessTokenPrivateKey
 	accessMaxAgeInt64 = ts.AppState.Config.AccessTokenMaxAge
 
 	refreshMaxAge = time.Duration(ts.AppState.Config.RefreshTokenMaxAge) * time.Minute
-	refreshPrivateKey = ts.AppState.Config.RefreshTokenPrivateKey
 	refreshMaxAgeInt64 = ts.AppState.Config.RefreshTokenMaxAge
 
 	fiberHelperCookie := NewFiberHelper(ts.AppState)
 
 	if tokenMethodKey == "access" || tokenMethodKey == "both" {
 		// Generate Access Token
-		generatedAccessToken, err := services.GenerateJwtToken(userID, accessMaxAgeInt64, accessPrivateKey)
+		generatedAccessToken, err := SignJwtToken(ts.AppState, services.TokenTypeAccess, userID, accessMaxAgeInt64, ts.AppState.Config.TokenScopes)
 		if err != nil {
 			log.Println("Failed to generate access token:", err)
 			return dtos.TokenDetailsDto{}, fmt.Errorf("StoreTokens: %w", exceptions.HandlerErrorResponse(c, exceptions.ErrTokenNotGenerated))
@@ -71,7 +69,7 @@ func (ts *TokenService) StoreToken(c *fiber.Ctx, ctx context.Context, userID uui
 
 	if tokenMethodKey == "refresh" || tokenMethodKey == "both" {
 		// Generate Refresh Token
-		generatedRefreshToken, err := services.GenerateJwtToken(userID, refreshMaxAgeInt64, refreshPrivateKey)
+		generatedRefreshToken, err := SignJwtToken(ts.AppState, services.TokenTypeRefresh, userID, refreshMaxAgeInt64, ts.AppState.Config.TokenScopes)
 		if err != nil {
 			log.Println("Failed to generate refresh token:", err)
 			return dtos.TokenDetailsDto{}, fmt.Errorf("StoreTokens: %w", exceptions.HandlerErrorResponse(c, exceptions.ErrTokenNotGenerated))
@@ -80,10 +78,9 @@ func (ts *TokenService) StoreToken(c *fiber.Ctx, ctx context.Context, userID uui
 		refreshToken = *generatedRefreshToken.Token
 		refreshUUID = generatedRefreshToken.TokenUUID
 
-		// Store Refresh Token in Redis
-		err = ts.SetRedisValue(c, ts.AppState.Redis, ctx, refreshUUID.String(), userID.String(), refreshMaxAge)
-		if err != nil {
-			return dtos.TokenDetailsDto{}, fmt.Errorf("StoreTokens: %w", err)
+		// Start a new refresh token family in Redis
+		if _, err := NewTokenFamilyStore(ts.AppState).Create(ctx, userID, refreshUUID, accessUUID, NewClientInfo(c)); err != nil {
+			return dtos.TokenDetailsDto{}, fmt.Errorf("StoreTokens: %w", exceptions.HandlerErrorResponse(c, err))
 		}
 
 		// Set refresh token as a cookie
@@ -95,6 +92,59 @@ func (ts *TokenService) StoreToken(c *fiber.Ctx, ctx context.Context, userID uui
 	}, nil
 }
 
+// RotateTokens exchanges a verified refresh token for a new access and refresh token pair
+// of the same family, granting the scopes of the refresh token, and sets the new refresh
+// token cookie. The presented refresh token and the access token issued with it stop
+// working.
+//
+// Unlike StoreToken it does not write error responses; failures are returned as the
+// sentinel errors of the exceptions package, including exceptions.ErrRefreshTokenReused
+// when the presented token had already been rotated and its family was revoked.
+func (ts *TokenService) RotateTokens(c *fiber.Ctx, ctx context.Context, presented dtos.TokenDetailsDto) (dtos.TokenDetailsDto, error) {
+	accessMaxAge := time.Duration(ts.AppState.Config.AccessTokenMaxAge) * time.Minute
+	refreshMaxAge := time.Duration(ts.AppState.Config.RefreshTokenMaxAge) * time.Minute
+	familyStore := NewTokenFamilyStore(ts.AppState)
+
+	generatedAccessToken, err := SignJwtToken(ts.AppState, services.TokenTypeAccess, presented.UserID, ts.AppState.Config.AccessTokenMaxAge, presented.Scopes)
+	if err != nil {
+		log.Println("Failed to generate access token:", err)
+		return dtos.TokenDetailsDto{}, exceptions.ErrTokenNotGenerated
+	}
+
+	generatedRefreshToken, err := SignJwtToken(ts.AppState, services.TokenTypeRefresh, presented.UserID, ts.AppState.Config.RefreshTokenMaxAge, presented.Scopes)
+	if err != nil {
+		log.Println("Failed to generate refresh token:", err)
+		return dtos.TokenDetailsDto{}, exceptions.ErrTokenNotGenerated
+	}
+
+	rotated, err := familyStore.Rotate(ctx, presented.TokenUUID, generatedRefreshToken.TokenUUID, generatedAccessToken.TokenUUID, NewClientInfo(c))
+	if err != nil {
+		return dtos.TokenDetailsDto{}, err
+	}
+	if rotated.UserID != presented.UserID {
+		if err := familyStore.Revoke(ctx, rotated.FamilyID); err != nil {
+			log.Println("Failed to revoke refresh token family:", err)
+		}
+		return dtos.TokenDetailsDto{}, exceptions.ErrUserIDMismatch
+	}
+
+	if err := ts.AppState.Redis.Set(ctx, generatedAccessToken.TokenUUID.String(), presented.UserID.String(), accessMaxAge).Err(); err != nil {
+		log.Println("Failed to store access token:", err)
+		return dtos.TokenDetailsDto{}, exceptions.ErrRedisSet
+	}
+	if rotated.PreviousAccess != "" {
+		if err := ts.AppState.Redis.Del(ctx, rotated.PreviousAccess).Err(); err != nil {
+			log.Println("Failed to remove previous access token:", err)
+		}
+	}
+
+	NewFiberHelper(ts.AppState).SetCookie(c, "refresh_token", *generatedRefreshToken.Token, refreshMaxAge)
+
+	return dtos.TokenDetailsDto{
+		Token: generatedAccessToken.Token,
+	}, nil
+}
+
 func (ts *TokenService) GetRedisValue(c *fiber.Ctx, redisClient *redis.Client, ctx context.Context, expectedValue string) (string, error) {
 	// Retrieve the value from Redis using the provided key.
 	cmd := ts.AppState.Redis.Get(ctx, expectedValue)
@@ -0,0 +1,203 @@
+package utils
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/starks97/alcohol-tracker-api/internal/entities"
+	"github.com/starks97/alcohol-tracker-api/internal/repositories"
+	"github.com/starks97/alcohol-tracker-api/internal/services"
+	"github.com/starks97/alcohol-tracker-api/internal/state"
+	"github.com/starks97/alcohol-tracker-api/internal/units"
+)
+
+// AssignDrinkSession attaches a saved drink to the drinking session it belongs to.
+//
+// A manual session running when the drink was consumed takes precedence. Otherwise the
+// drink joins the automatic session whose drinks are less than the configured gap away,
+// merging sessions the drink bridges, or starts a new automatic session. The session and
+// the one the drink previously belonged to are then refreshed, which splits them where
+// moving the drink left a gap.
+//
+// Parameters:
+//   - appState: The application state holding the configuration.
+//   - tx: The transaction the drink was saved in; the sessions are written in it too, so
+//     that a failure leaves no drink pointing at a deleted session.
+//   - user: The owner of the drink.
+//   - drink: The drink to assign; its SessionID is updated in place.
+//
+// Returns:
+//   - error: An error if the sessions could not be read or written.
+func AssignDrinkSession(appState *state.AppState, tx *gorm.DB, user *entities.User, drink *entities.DrinkEntry) error {
+	sessionRepo := repositories.NewSessionRepository(tx)
+	previousSessionID := drink.SessionID
+
+	sessions, err := sessionRepo.FindSessionsAt(user.ID, drink.ConsumedAt, appState.Config.SessionGap)
+	if err != nil {
+		return err
+	}
+
+	var target *entities.DrinkingSession
+	switch {
+	case len(sessions) > 0 && sessions[0].Manual:
+		target = &sessions[0]
+	case len(sessions) > 0:
+		target = &sessions[0]
+
+		merged := make([]uuid.UUID, 0, len(sessions)-1)
+		for _, session := range sessions[1:] {
+			if !session.Manual {
+				merged = append(merged, session.ID)
+			}
+		}
+		if len(merged) > 0 {
+			if err := sessionRepo.MoveSessionDrinks(merged, target.ID); err != nil {
+				return err
+			}
+			for _, id := range merged {
+				if err := sessionRepo.DeleteSession(user.ID, id); err != nil {
+					return err
+				}
+			}
+		}
+	default:
+		target, err = createAutomaticSession(sessionRepo, user.ID, drink.ConsumedAt, drink.ConsumedAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := sessionRepo.SetDrinkSession(drink.ID, target.ID); err != nil {
+		return err
+	}
+
+	if err := RefreshSession(appState, tx, user, target.ID); err != nil {
+		return err
+	}
+	if previousSessionID != nil && *previousSessionID != target.ID {
+		if err := RefreshSession(appState, tx, user, *previousSessionID); err != nil {
+			return err
+		}
+	}
+
+	// Refreshing the target may have split the drink off into a session of its own.
+	saved, err := repositories.NewDrinkRepository(tx).GetDrinkByID(user.ID, drink.ID)
+	if err != nil {
+		return err
+	}
+	drink.SessionID = saved.SessionID
+	return nil
+}
+
+// RefreshSession recomputes a drinking session's bounds, totals and peak BAC from its
+// drinks. An automatic session whose drinks are no longer all within the configured gap
+// of each other is split, the later runs of drinks moving to new sessions. Automatic
+// sessions left without drinks are deleted, and sessions that no longer exist are ignored.
+//
+// Parameters:
+//   - appState: The application state holding the configuration.
+//   - tx: The database connection or transaction to read and write the sessions with.
+//   - user: The owner of the session.
+//   - sessionID: The session to refresh.
+//
+// Returns:
+//   - error: An error if the session or its drinks could not be read or written.
+func RefreshSession(appState *state.AppState, tx *gorm.DB, user *entities.User, sessionID uuid.UUID) error {
+	sessionRepo := repositories.NewSessionRepository(tx)
+
+	session, err := sessionRepo.GetSessionByID(user.ID, sessionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	drinks, err := sessionRepo.ListSessionDrinks(user.ID, sessionID)
+	if err != nil {
+		return err
+	}
+
+	if len(drinks) == 0 && !session.Manual {
+		return sessionRepo.DeleteSession(user.ID, sessionID)
+	}
+
+	if !session.Manual {
+		// Drinks are ordered by consumed_at.
+		consumedAt := make([]time.Time, len(drinks))
+		for i, drink := range drinks {
+			consumedAt[i] = drink.ConsumedAt
+		}
+		runs := services.SplitAtGaps(consumedAt, appState.Config.SessionGap)
+
+		for i := len(runs) - 1; i > 0; i-- {
+			run := drinks[runs[i]:]
+			split, err := createAutomaticSession(sessionRepo, user.ID, run[0].ConsumedAt, run[len(run)-1].ConsumedAt)
+			if err != nil {
+				return err
+			}
+			for _, drink := range run {
+				if err := sessionRepo.SetDrinkSession(drink.ID, split.ID); err != nil {
+					return err
+				}
+			}
+			if err := RefreshSession(appState, tx, user, split.ID); err != nil {
+				return err
+			}
+			drinks = drinks[:runs[i]]
+		}
+
+		endedAt := drinks[len(drinks)-1].ConsumedAt
+		session.StartedAt = drinks[0].ConsumedAt
+		session.EndedAt = &endedAt
+	}
+
+	session.DrinkCount = len(drinks)
+	session.EthanolGrams = 0
+	bacDrinks := make([]services.BacDrink, 0, len(drinks))
+	for _, drink := range drinks {
+		ethanolGrams := units.EthanolGrams(drink.VolumeMl, drink.ABV)
+		session.EthanolGrams += ethanolGrams
+		bacDrinks = append(bacDrinks, services.BacDrink{EthanolGrams: ethanolGrams, ConsumedAt: drink.ConsumedAt})
+	}
+
+	session.PeakBAC, session.PeakBACAt = nil, nil
+	if user.WeightKg != nil && user.Sex != nil && len(bacDrinks) > 0 {
+		peak, err := services.PeakBac(bacDrinks, services.BacProfile{
+			WeightKg:        *user.WeightKg,
+			Sex:             *user.Sex,
+			EliminationRate: appState.Config.BacEliminationRate,
+		})
+		if err == nil {
+			session.PeakBAC, session.PeakBACAt = &peak.BAC, &peak.Time
+		}
+	}
+
+	_, err = sessionRepo.UpdateSession(session)
+	return err
+}
+
+// createAutomaticSession starts an automatic session spanning from the first to the last
+// of its drinks; its totals are filled in by RefreshSession.
+func createAutomaticSession(sessionRepo repositories.SessionRepository, userID uuid.UUID, startedAt time.Time, endedAt time.Time) (*entities.DrinkingSession, error) {
+	return sessionRepo.CreateSession(&entities.DrinkingSession{
+		UserID:    userID,
+		StartedAt: startedAt,
+		EndedAt:   &endedAt,
+	})
+}
+
+// SessionActive reports whether a drinking session is still going on: a manual session
+// until it is ended, an automatic one until the gap after its last drink has passed.
+func SessionActive(session *entities.DrinkingSession, gap time.Duration, now time.Time) bool {
+	if session.EndedAt == nil {
+		return true
+	}
+	if session.Manual {
+		return false
+	}
+	return now.Before(session.EndedAt.Add(gap))
+}
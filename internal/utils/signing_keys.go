@@ -0,0 +1,213 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/starks97/alcohol-tracker-api/internal/dtos"
+	"github.com/starks97/alcohol-tracker-api/internal/entities"
+	"github.com/starks97/alcohol-tracker-api/internal/repositories"
+	"github.com/starks97/alcohol-tracker-api/internal/services"
+	"github.com/starks97/alcohol-tracker-api/internal/state"
+)
+
+// SigningKeysCacheTTL is how long the signing keys read from the database are used before
+// being read again.
+const SigningKeysCacheTTL = time.Minute
+
+// JWKSCacheMaxAge is how long clients may cache the published keys.
+const JWKSCacheMaxAge = 5 * time.Minute
+
+// MinKeyPublishDelay is how long a rotated key must be published before it signs tokens,
+// for every instance and every JWKS client to know it by then.
+const MinKeyPublishDelay = SigningKeysCacheTTL + JWKSCacheMaxAge
+
+// signingKeyRing is the parsed, cached keys of one purpose.
+type signingKeyRing struct {
+	mu       sync.Mutex
+	keys     []signingKeyEntry
+	loadedAt time.Time
+}
+
+type signingKeyEntry struct {
+	key         services.SigningKey
+	activatesAt time.Time
+	retiresAt   *time.Time
+}
+
+var (
+	signingKeyRingsMu sync.Mutex
+	signingKeyRings   = map[string]*signingKeyRing{}
+)
+
+// SignJwtToken issues a token of the given type for the user, granting the given scopes,
+// signed by the current key of that type.
+func SignJwtToken(appState *state.AppState, tokenType string, userID uuid.UUID, ttl int64, scopes []string) (dtos.TokenDetailsDto, error) {
+	key, err := CurrentSigningKey(appState, tokenType, time.Now())
+	if err != nil {
+		return dtos.TokenDetailsDto{}, err
+	}
+	return services.GenerateJwtToken(userID, ttl, key, TokenClaims(appState, tokenType, scopes))
+}
+
+// VerifyJwtToken verifies a token of the given type with the key named by its kid, and
+// checks that it carries the configured issuer and audience and only configured scopes.
+func VerifyJwtToken(appState *state.AppState, tokenType string, token string) (dtos.TokenDetailsDto, error) {
+	keys, err := VerificationKeys(appState, tokenType)
+	if err != nil {
+		return dtos.TokenDetailsDto{}, err
+	}
+	return services.VerifyJwtToken(keys, token, TokenClaims(appState, tokenType, appState.Config.TokenScopes))
+}
+
+// TokenClaims returns the configured claims of a token type with the given scopes.
+func TokenClaims(appState *state.AppState, tokenType string, scopes []string) services.TokenClaims {
+	return services.TokenClaims{
+		Issuer:   appState.Config.TokenIssuer,
+		Audience: appState.Config.TokenAudience,
+		Type:     tokenType,
+		Scopes:   scopes,
+	}
+}
+
+// CurrentSigningKey returns the key signing tokens of the given type at now: the most
+// recently activated key that has not retired.
+func CurrentSigningKey(appState *state.AppState, tokenType string, now time.Time) (services.SigningKey, error) {
+	ring, err := loadSigningKeyRing(appState, tokenType)
+	if err != nil {
+		return services.SigningKey{}, err
+	}
+
+	ring.mu.Lock()
+	defer ring.mu.Unlock()
+	for i := len(ring.keys) - 1; i >= 0; i-- {
+		entry := ring.keys[i]
+		if !entry.activatesAt.After(now) && (entry.retiresAt == nil || entry.retiresAt.After(now)) {
+			return entry.key, nil
+		}
+	}
+	return services.SigningKey{}, fmt.Errorf("no active %s token signing key", tokenType)
+}
+
+// VerificationKeys returns the public keys verifying tokens of the given type: every key
+// that has not expired, including those not yet active.
+func VerificationKeys(appState *state.AppState, tokenType string) (services.VerificationKeys, error) {
+	ring, err := loadSigningKeyRing(appState, tokenType)
+	if err != nil {
+		return nil, err
+	}
+
+	ring.mu.Lock()
+	defer ring.mu.Unlock()
+	keys := make(services.VerificationKeys, len(ring.keys))
+	for _, entry := range ring.keys {
+		keys[entry.key.ID] = &entry.key.PrivateKey.PublicKey
+	}
+	return keys, nil
+}
+
+// SortedKeyIDs returns the IDs of verification keys in a stable order.
+func SortedKeyIDs(keys services.VerificationKeys) []string {
+	ids := make([]string, 0, len(keys))
+	for id := range keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// ConfiguredSigningKey returns the private key configured in the environment for a token
+// type. It signs tokens until the first rotation.
+func ConfiguredSigningKey(appState *state.AppState, tokenType string) (services.SigningKey, string, error) {
+	var privateKey string
+	switch tokenType {
+	case services.TokenTypeAccess:
+		privateKey = appState.Config.AccessTokenPrivateKey
+	case services.TokenTypeRefresh:
+		privateKey = appState.Config.RefreshTokenPrivateKey
+	default:
+		return services.SigningKey{}, "", fmt.Errorf("unknown token type %q", tokenType)
+	}
+
+	key, err := services.ParseSigningKey(privateKey)
+	return key, privateKey, err
+}
+
+// loadSigningKeyRing returns the keys of a token type, reading them from the database when
+// the cache is stale. When the database has none, the key configured in the environment is
+// stored as the first one. A stale cache is still used if the database cannot be read.
+func loadSigningKeyRing(appState *state.AppState, tokenType string) (*signingKeyRing, error) {
+	signingKeyRingsMu.Lock()
+	ring, ok := signingKeyRings[tokenType]
+	if !ok {
+		ring = &signingKeyRing{}
+		signingKeyRings[tokenType] = ring
+	}
+	signingKeyRingsMu.Unlock()
+
+	ring.mu.Lock()
+	defer ring.mu.Unlock()
+	if ring.keys != nil && time.Since(ring.loadedAt) < SigningKeysCacheTTL {
+		return ring, nil
+	}
+
+	keys, err := readSigningKeys(appState, tokenType)
+	if err != nil {
+		if ring.keys != nil {
+			log.Println("Failed to refresh signing keys, using the cached keys:", err)
+			return ring, nil
+		}
+		return nil, err
+	}
+
+	ring.keys, ring.loadedAt = keys, time.Now()
+	return ring, nil
+}
+
+func readSigningKeys(appState *state.AppState, tokenType string) ([]signingKeyEntry, error) {
+	repo := repositories.NewSigningKeyRepository(appState.DB)
+
+	configured, configuredPEM, err := ConfiguredSigningKey(appState, tokenType)
+	if err != nil {
+		return nil, fmt.Errorf("invalid configured %s token key: %w", tokenType, err)
+	}
+
+	stored, err := repo.ListSigningKeys(tokenType, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if len(stored) == 0 {
+		first := &entities.SigningKey{
+			ID:          configured.ID,
+			Purpose:     tokenType,
+			PrivateKey:  configuredPEM,
+			ActivatesAt: time.Unix(0, 0),
+		}
+		if err := repo.CreateSigningKey(first); err != nil {
+			return nil, err
+		}
+		if stored, err = repo.ListSigningKeys(tokenType, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+
+	entries := make([]signingKeyEntry, 0, len(stored))
+	for _, storedKey := range stored {
+		key, err := services.ParseSigningKey(storedKey.PrivateKey)
+		if err != nil {
+			log.Printf("Failed to parse signing key %s: %v", storedKey.ID, err)
+			continue
+		}
+		entries = append(entries, signingKeyEntry{key: key, activatesAt: storedKey.ActivatesAt, retiresAt: storedKey.RetiresAt})
+	}
+	if len(entries) == 0 {
+		return nil, errors.New("no valid signing key")
+	}
+	return entries, nil
+}
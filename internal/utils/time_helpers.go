@@ -0,0 +1,23 @@
+package utils
+
+import (
+	"log"
+	"time"
+
+	"github.com/starks97/alcohol-tracker-api/internal/entities"
+)
+
+// UserLocation returns the location whose calendar defines the user's days, weeks and
+// months. Users without a time zone, or with one that can no longer be loaded, use UTC.
+func UserLocation(user *entities.User) *time.Location {
+	if user.TimeZone == nil || *user.TimeZone == "" {
+		return time.UTC
+	}
+
+	loc, err := time.LoadLocation(*user.TimeZone)
+	if err != nil {
+		log.Printf("Failed to load time zone %q for user %s: %v", *user.TimeZone, user.ID, err)
+		return time.UTC
+	}
+	return loc
+}
@@ -0,0 +1,392 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/starks97/alcohol-tracker-api/internal/exceptions"
+	"github.com/starks97/alcohol-tracker-api/internal/state"
+)
+
+// Redis key prefixes of the refresh token families.
+//
+// A family is every refresh token descending from one login. refresh_family:<family> is a
+// hash holding the user, the only refresh token of the family that may still be used and
+// the access token issued with it. refresh_token:<token> maps each refresh token ever
+// issued to its family, and is kept after rotation so that reuse can be detected.
+//
+// Each family is also a session the user can see and revoke: user_sessions:<user> is a
+// hash from family ID to the JSON encoded SessionInfo of that login.
+const (
+	refreshFamilyKeyPrefix = "refresh_family:"
+	refreshTokenKeyPrefix  = "refresh_token:"
+	userSessionsKeyPrefix  = "user_sessions:"
+)
+
+// maxUserAgentLength bounds the user agent stored for a session.
+const maxUserAgentLength = 255
+
+// rotateRefreshScript swaps the family's current refresh token for a new one, but only
+// when the presented token is the current one.
+//
+// KEYS: family key, new refresh token key.
+// ARGV: presented refresh token, new refresh token, new access token, family ID, TTL in ms.
+// Returns {1, user ID, previous access token} on success, {0} when the presented token
+// was already rotated and {-1} when the family no longer exists.
+var rotateRefreshScript = redis.NewScript(`
+local current = redis.call('HGET', KEYS[1], 'refresh')
+if not current then
+	return {-1}
+end
+if current ~= ARGV[1] then
+	return {0}
+end
+local userID = redis.call('HGET', KEYS[1], 'user_id')
+local previousAccess = redis.call('HGET', KEYS[1], 'access') or ''
+redis.call('HSET', KEYS[1], 'refresh', ARGV[2], 'access', ARGV[3])
+redis.call('PEXPIRE', KEYS[1], ARGV[5])
+redis.call('SET', KEYS[2], ARGV[4], 'PX', ARGV[5])
+return {1, userID, previousAccess}
+`)
+
+// ClientInfo describes the client a token was issued to or used by.
+type ClientInfo struct {
+	UserAgent string
+	IP        string
+}
+
+// SessionInfo is what the session index stores about a login.
+type SessionInfo struct {
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+}
+
+// AuthSession is an active login of a user, identified by its token family.
+type AuthSession struct {
+	ID uuid.UUID
+	SessionInfo
+}
+
+// NewClientInfo reads the user agent and IP address of the request.
+func NewClientInfo(c *fiber.Ctx) ClientInfo {
+	userAgent := c.Get(fiber.HeaderUserAgent)
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+	return ClientInfo{UserAgent: userAgent, IP: c.IP()}
+}
+
+// RotatedFamily is the result of a successful TokenFamilyStore.Rotate.
+type RotatedFamily struct {
+	FamilyID       uuid.UUID
+	UserID         uuid.UUID
+	PreviousAccess string // Access token issued with the rotated refresh token, if any.
+}
+
+// TokenFamilyStore tracks refresh token families in Redis so that every refresh token can
+// be used only once. Unlike TokenService, its methods never write HTTP responses and
+// report failures with the sentinel errors of the exceptions package.
+type TokenFamilyStore struct {
+	redis *redis.Client
+	ttl   time.Duration
+}
+
+// NewTokenFamilyStore returns a store whose families live as long as a refresh token.
+func NewTokenFamilyStore(appState *state.AppState) *TokenFamilyStore {
+	return &TokenFamilyStore{
+		redis: appState.Redis,
+		ttl:   time.Duration(appState.Config.RefreshTokenMaxAge) * time.Minute,
+	}
+}
+
+// Create starts a new family for a login, with its first refresh and access tokens.
+//
+// Parameters:
+//   - ctx: The request context.
+//   - userID: The user who logged in.
+//   - refreshUUID: The UUID of the first refresh token of the family.
+//   - accessUUID: The UUID of the access token issued with it, or uuid.Nil.
+//   - client: The client that logged in, recorded in the session index.
+//
+// Returns:
+//   - uuid.UUID: The ID of the new family.
+//   - error: exceptions.ErrRedisSet if the family could not be stored.
+func (s *TokenFamilyStore) Create(ctx context.Context, userID uuid.UUID, refreshUUID uuid.UUID, accessUUID uuid.UUID, client ClientInfo) (uuid.UUID, error) {
+	familyID := uuid.New()
+	familyKey := refreshFamilyKeyPrefix + familyID.String()
+	sessionsKey := userSessionsKeyPrefix + userID.String()
+
+	now := time.Now().UTC()
+	info, err := json.Marshal(SessionInfo{UserAgent: client.UserAgent, IP: client.IP, CreatedAt: now, LastUsedAt: now})
+	if err != nil {
+		return uuid.Nil, exceptions.ErrRedisSet
+	}
+
+	fields := map[string]interface{}{
+		"user_id": userID.String(),
+		"refresh": refreshUUID.String(),
+	}
+	if accessUUID != uuid.Nil {
+		fields["access"] = accessUUID.String()
+	}
+
+	_, err = s.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, familyKey, fields)
+		pipe.Expire(ctx, familyKey, s.ttl)
+		pipe.Set(ctx, refreshTokenKeyPrefix+refreshUUID.String(), familyID.String(), s.ttl)
+		pipe.HSet(ctx, sessionsKey, familyID.String(), info)
+		pipe.Expire(ctx, sessionsKey, s.ttl)
+		return nil
+	})
+	if err != nil {
+		log.Println("Failed to create refresh token family:", err)
+		return uuid.Nil, exceptions.ErrRedisSet
+	}
+	return familyID, nil
+}
+
+// Rotate replaces the presented refresh token with a new one. Presenting a token that was
+// already rotated means it leaked, so the whole family is revoked. There is no grace period:
+// a client refreshing twice in parallel with the same token is treated the same way.
+//
+// Parameters:
+//   - ctx: The request context.
+//   - presentedUUID: The UUID of the refresh token sent by the client.
+//   - newRefreshUUID: The UUID of the refresh token replacing it.
+//   - newAccessUUID: The UUID of the access token issued with the new refresh token.
+//   - client: The client refreshing the tokens, recorded as the session's last use.
+//
+// Returns:
+//   - RotatedFamily: The family and user the tokens belong to.
+//   - error: exceptions.ErrRefreshTokenReused when the token was already rotated,
+//     exceptions.ErrRedisNotFound when the token or its family is unknown or revoked, and
+//     exceptions.ErrRedisGet when Redis fails.
+func (s *TokenFamilyStore) Rotate(ctx context.Context, presentedUUID uuid.UUID, newRefreshUUID uuid.UUID, newAccessUUID uuid.UUID, client ClientInfo) (RotatedFamily, error) {
+	familyID, err := s.FamilyOf(ctx, presentedUUID)
+	if err != nil {
+		return RotatedFamily{}, err
+	}
+
+	keys := []string{refreshFamilyKeyPrefix + familyID.String(), refreshTokenKeyPrefix + newRefreshUUID.String()}
+	result, err := rotateRefreshScript.Run(ctx, s.redis, keys,
+		presentedUUID.String(), newRefreshUUID.String(), newAccessUUID.String(), familyID.String(), s.ttl.Milliseconds(),
+	).Slice()
+	if err != nil {
+		log.Println("Failed to rotate refresh token:", err)
+		return RotatedFamily{}, exceptions.ErrRedisGet
+	}
+
+	switch result[0].(int64) {
+	case -1:
+		return RotatedFamily{}, exceptions.ErrRedisNotFound
+	case 0:
+		if err := s.Revoke(ctx, familyID); err != nil {
+			return RotatedFamily{}, err
+		}
+		return RotatedFamily{}, exceptions.ErrRefreshTokenReused
+	}
+
+	userID, err := uuid.Parse(result[1].(string))
+	if err != nil {
+		return RotatedFamily{}, exceptions.ErrUserIDParse
+	}
+
+	s.touchSession(ctx, userID, familyID, client)
+
+	return RotatedFamily{
+		FamilyID:       familyID,
+		UserID:         userID,
+		PreviousAccess: result[2].(string),
+	}, nil
+}
+
+// FamilyOf returns the family a refresh token was issued in.
+func (s *TokenFamilyStore) FamilyOf(ctx context.Context, refreshUUID uuid.UUID) (uuid.UUID, error) {
+	value, err := s.redis.Get(ctx, refreshTokenKeyPrefix+refreshUUID.String()).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return uuid.Nil, exceptions.ErrRedisNotFound
+		}
+		log.Println("Failed to get refresh token family:", err)
+		return uuid.Nil, exceptions.ErrRedisGet
+	}
+
+	familyID, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, exceptions.ErrRedisNotFound
+	}
+	return familyID, nil
+}
+
+// Revoke ends a family: none of its refresh tokens can be used anymore and its current
+// access token stops being accepted. Revoking an unknown family is not an error.
+func (s *TokenFamilyStore) Revoke(ctx context.Context, familyID uuid.UUID) error {
+	familyKey := refreshFamilyKeyPrefix + familyID.String()
+
+	family, err := s.redis.HGetAll(ctx, familyKey).Result()
+	if err != nil {
+		log.Println("Failed to read refresh token family:", err)
+		return exceptions.ErrRedisDel
+	}
+
+	keys := []string{familyKey}
+	if access := family["access"]; access != "" {
+		keys = append(keys, access)
+	}
+	if refresh := family["refresh"]; refresh != "" {
+		keys = append(keys, refreshTokenKeyPrefix+refresh)
+	}
+
+	_, err = s.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, keys...)
+		if userID := family["user_id"]; userID != "" {
+			pipe.HDel(ctx, userSessionsKeyPrefix+userID, familyID.String())
+		}
+		return nil
+	})
+	if err != nil {
+		log.Println("Failed to revoke refresh token family:", err)
+		return exceptions.ErrRedisDel
+	}
+	return nil
+}
+
+// RevokeByToken revokes the family the given refresh token belongs to, if it is known.
+func (s *TokenFamilyStore) RevokeByToken(ctx context.Context, refreshUUID uuid.UUID) error {
+	familyID, err := s.FamilyOf(ctx, refreshUUID)
+	if err != nil {
+		if errors.Is(err, exceptions.ErrRedisNotFound) {
+			return nil
+		}
+		return err
+	}
+	return s.Revoke(ctx, familyID)
+}
+
+// ListSessions returns the user's active sessions, most recently used first. Sessions
+// whose family has expired are dropped from the index along the way.
+func (s *TokenFamilyStore) ListSessions(ctx context.Context, userID uuid.UUID) ([]AuthSession, error) {
+	sessionsKey := userSessionsKeyPrefix + userID.String()
+
+	entries, err := s.redis.HGetAll(ctx, sessionsKey).Result()
+	if err != nil {
+		log.Println("Failed to list sessions:", err)
+		return nil, exceptions.ErrRedisGet
+	}
+
+	sessions := make([]AuthSession, 0, len(entries))
+	for field, value := range entries {
+		familyID, err := uuid.Parse(field)
+		if err != nil {
+			continue
+		}
+
+		exists, err := s.redis.Exists(ctx, refreshFamilyKeyPrefix+field).Result()
+		if err != nil {
+			log.Println("Failed to check session:", err)
+			return nil, exceptions.ErrRedisGet
+		}
+		if exists == 0 {
+			if err := s.redis.HDel(ctx, sessionsKey, field).Err(); err != nil {
+				log.Println("Failed to prune expired session:", err)
+			}
+			continue
+		}
+
+		var info SessionInfo
+		if err := json.Unmarshal([]byte(value), &info); err != nil {
+			log.Println("Failed to decode session:", err)
+		}
+		sessions = append(sessions, AuthSession{ID: familyID, SessionInfo: info})
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastUsedAt.After(sessions[j].LastUsedAt)
+	})
+	return sessions, nil
+}
+
+// RevokeSession revokes one of the user's sessions.
+//
+// Returns:
+//   - error: exceptions.ErrAuthSessionNotFound when the session is not one of the user's.
+func (s *TokenFamilyStore) RevokeSession(ctx context.Context, userID uuid.UUID, familyID uuid.UUID) error {
+	exists, err := s.redis.HExists(ctx, userSessionsKeyPrefix+userID.String(), familyID.String()).Result()
+	if err != nil {
+		log.Println("Failed to get session:", err)
+		return exceptions.ErrRedisGet
+	}
+	if !exists {
+		return exceptions.ErrAuthSessionNotFound
+	}
+	return s.Revoke(ctx, familyID)
+}
+
+// RevokeAll revokes every session of the user, signing them out on all devices.
+func (s *TokenFamilyStore) RevokeAll(ctx context.Context, userID uuid.UUID) error {
+	familyIDs, err := s.redis.HKeys(ctx, userSessionsKeyPrefix+userID.String()).Result()
+	if err != nil {
+		log.Println("Failed to list sessions:", err)
+		return exceptions.ErrRedisGet
+	}
+
+	for _, field := range familyIDs {
+		familyID, err := uuid.Parse(field)
+		if err != nil {
+			continue
+		}
+		if err := s.Revoke(ctx, familyID); err != nil {
+			return err
+		}
+	}
+
+	if err := s.redis.Del(ctx, userSessionsKeyPrefix+userID.String()).Err(); err != nil {
+		log.Println("Failed to remove session index:", err)
+		return exceptions.ErrRedisDel
+	}
+	return nil
+}
+
+// touchSession records a use of the session in the index. Failures are only logged, as
+// they do not affect the tokens themselves.
+func (s *TokenFamilyStore) touchSession(ctx context.Context, userID uuid.UUID, familyID uuid.UUID, client ClientInfo) {
+	sessionsKey := userSessionsKeyPrefix + userID.String()
+
+	var info SessionInfo
+	if value, err := s.redis.HGet(ctx, sessionsKey, familyID.String()).Result(); err == nil {
+		if err := json.Unmarshal([]byte(value), &info); err != nil {
+			log.Println("Failed to decode session:", err)
+		}
+	}
+
+	info.UserAgent = client.UserAgent
+	info.IP = client.IP
+	info.LastUsedAt = time.Now().UTC()
+	if info.CreatedAt.IsZero() {
+		info.CreatedAt = info.LastUsedAt
+	}
+
+	encoded, err := json.Marshal(info)
+	if err != nil {
+		return
+	}
+
+	_, err = s.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, sessionsKey, familyID.String(), encoded)
+		pipe.Expire(ctx, sessionsKey, s.ttl)
+		return nil
+	})
+	if err != nil {
+		log.Println("Failed to update session:", err)
+	}
+}
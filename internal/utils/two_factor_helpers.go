@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/starks97/alcohol-tracker-api/internal/entities"
+	"github.com/starks97/alcohol-tracker-api/internal/exceptions"
+	"github.com/starks97/alcohol-tracker-api/internal/repositories"
+	"github.com/starks97/alcohol-tracker-api/internal/services"
+	"github.com/starks97/alcohol-tracker-api/internal/state"
+)
+
+// Login challenges are issued by LoginHandler to users with 2FA enabled, and redeemed
+// with a TOTP or recovery code at POST /auth/2fa/verify.
+const (
+	TwoFactorChallengePurpose     = "two_factor_challenge"
+	TwoFactorChallengeTTL         = 5 * time.Minute
+	TwoFactorChallengeMaxAttempts = 5
+)
+
+// totpUsedKeyPrefix prefixes the Redis keys recording the TOTP time steps already used by
+// a user, totp_used:<user>:<step>, so that a code cannot be replayed while it is valid.
+const totpUsedKeyPrefix = "totp_used:"
+
+// NewTwoFactorChallengeStore returns the store of login challenges.
+func NewTwoFactorChallengeStore(appState *state.AppState) *OneTimeTokenStore {
+	return NewOneTimeTokenStore(appState, TwoFactorChallengePurpose, TwoFactorChallengeTTL)
+}
+
+// VerifySecondFactor checks a code entered by the user against their TOTP secret. Once 2FA
+// is enabled, an unused recovery code is accepted too and is used up by the check.
+//
+// Parameters:
+//   - ctx: The request context.
+//   - appState: The application state holding the database and Redis connections.
+//   - user: The user the code belongs to.
+//   - code: The TOTP code or recovery code entered.
+//   - now: The time of the check.
+//
+// Returns:
+//   - error: exceptions.ErrTwoFactorCode when the code is wrong or was already used,
+//     exceptions.ErrTwoFactorNotSetUp when the user has no TOTP secret, or the error of
+//     the store that failed.
+func VerifySecondFactor(ctx context.Context, appState *state.AppState, user *entities.User, code string, now time.Time) error {
+	if user.TotpSecret == nil {
+		return exceptions.ErrTwoFactorNotSetUp
+	}
+
+	code = strings.TrimSpace(code)
+	if isTotpCode(code) {
+		step, ok := services.VerifyTotp(*user.TotpSecret, code, now)
+		if !ok {
+			return exceptions.ErrTwoFactorCode
+		}
+
+		// A code stays valid for the whole skew window, so remember it until then.
+		key := fmt.Sprintf("%s%s:%d", totpUsedKeyPrefix, user.ID, step)
+		window := time.Duration(2*services.TotpSkew+1) * services.TotpPeriod
+		fresh, err := appState.Redis.SetNX(ctx, key, 1, window).Result()
+		if err != nil {
+			log.Println("Failed to record TOTP code use:", err)
+			return exceptions.ErrRedisSet
+		}
+		if !fresh {
+			return exceptions.ErrTwoFactorCode
+		}
+		return nil
+	}
+
+	if !user.TwoFactorEnabled {
+		return exceptions.ErrTwoFactorCode
+	}
+
+	used, err := repositories.NewRecoveryCodeRepository(appState.DB).UseRecoveryCode(user.ID, services.HashRecoveryCode(code))
+	if err != nil {
+		log.Println("Failed to use recovery code:", err)
+		return exceptions.ErrDatabase
+	}
+	if !used {
+		return exceptions.ErrTwoFactorCode
+	}
+	return nil
+}
+
+// isTotpCode reports whether code has the shape of a TOTP code rather than a recovery code.
+func isTotpCode(code string) bool {
+	if len(code) != services.TotpDigits {
+		return false
+	}
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
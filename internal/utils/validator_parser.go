@@ -33,12 +33,26 @@ func (ve *ValidationError) Error() string {
 // errorMessages maps validation tags to human-readable error messages.
 // The messages can include placeholders like "{0}" for the field name and "{1}" for parameters.
 var errorMessages = map[string]string{
-	"required": "Please provide a value for {0}.",
-	"name":     "Please enter a valid name for {0}.",
-	"email":    "Please enter a valid email address for {0}.",
-	"min":      "{0} must be at least {1} characters.",
-	"max":      "{0} cannot exceed {1} characters.",
-	"password": "{0} error in password.",
+	"required":         "Please provide a value for {0}.",
+	"required_without": "Please provide a value for {0} or for {1}.",
+	"required_with":    "Please provide a value for {0} together with {1}.",
+	"numeric":          "{0} must contain only digits.",
+	"name":             "Please enter a valid name for {0}.",
+	"email":            "Please enter a valid email address for {0}.",
+	"url":              "Please enter a valid URL for {0}.",
+	"min":              "{0} must be at least {1} characters.",
+	"max":              "{0} cannot exceed {1} characters.",
+	"len":              "{0} must be exactly {1} characters.",
+	"password":         "{0} error in password.",
+	"oneof":            "{0} must be one of: {1}.",
+	"gt":               "{0} must be greater than {1}.",
+	"gte":              "{0} must be greater than or equal to {1}.",
+	"lt":               "{0} must be less than {1}.",
+	"lte":              "{0} must be less than or equal to {1}.",
+	"datetime":         "{0} must be a date in the format {1}.",
+	"drink_locale":     "{0} must be a supported standard drink locale, such as US, UK or AU.",
+	"timezone":         "{0} must be an IANA time zone, such as Europe/Madrid.",
+	"iso4217":          "{0} must be an ISO 4217 currency code, such as USD or EUR.",
 }
 
 // ParseValidatorMessage validates a model using the provided validator client and parses the errors.
@@ -6,6 +6,8 @@ import (
 	"log"
 	"net/http"
 	"strings"
+	"time"
+	_ "time/tzdata" // Embedded so user time zones resolve on hosts without a zoneinfo database.
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
@@ -13,8 +15,10 @@ import (
 	"github.com/starks97/alcohol-tracker-api/config"
 	"github.com/starks97/alcohol-tracker-api/internal/database"
 	"github.com/starks97/alcohol-tracker-api/internal/exceptions"
+	"github.com/starks97/alcohol-tracker-api/internal/mailer"
 	"github.com/starks97/alcohol-tracker-api/internal/routes"
 	"github.com/starks97/alcohol-tracker-api/internal/state"
+	"github.com/starks97/alcohol-tracker-api/internal/utils"
 )
 
 func main() {
@@ -28,7 +32,7 @@ func main() {
 	ctx := context.Background()
 
 	//http
-	httpClient := &http.Client{}
+	httpClient := &http.Client{Timeout: 15 * time.Second}
 
 	//load config
 	cfg, err := config.LoadConfig()
@@ -42,12 +46,26 @@ func main() {
 		log.Fatalf("Error initializing Redis client: %v", err)
 	}
 
+	//encryption of provider tokens at rest
+	keyRing, err := utils.NewKeyRing(cfg.TokenEncryptionKeys, cfg.TokenEncryptionKeyID)
+	if err != nil {
+		log.Fatalf("Error initializing encryption keys: %v", err)
+	}
+	utils.SetEncryptionKeyRing(keyRing)
+
 	//database connection
 	db := database.ConnectDB(cfg)
+	database.MigrateData(db)
 
 	//validator
 	validator := exceptions.Init()
 
+	//mailer
+	mail, err := mailer.NewMailer(cfg)
+	if err != nil {
+		log.Fatalf("Error initializing mailer: %v", err)
+	}
+
 	//initialize state
 	appState := &state.AppState{
 		DB:         db,
@@ -55,6 +73,7 @@ func main() {
 		Config:     cfg,
 		HttpClient: httpClient,
 		Validator:  validator,
+		Mailer:     mail,
 	}
 
 	//set interfaces available to routes
@@ -66,7 +85,7 @@ func main() {
 		AllowOriginsFunc: func(origin string) bool {
 			return strings.Contains(cfg.ClientOrigin, origin)
 		},
-		AllowMethods:     "GET,POST,PUT,DELETE,OPTIONS",
+		AllowMethods:     "GET,POST,PUT,PATCH,DELETE,OPTIONS",
 		AllowHeaders:     "Origin, Content-Type, Authorization",
 		AllowCredentials: true,
 	}))
@@ -0,0 +1,43 @@
+package tests
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/starks97/alcohol-tracker-api/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateAppleClientSecret(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	assert.NoError(t, err)
+	p8 := base64.StdEncoding.EncodeToString(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+
+	now := time.Now()
+	secret, err := services.GenerateAppleClientSecret("TEAM123456", "com.example.web", "KEY1234567", p8, now)
+	assert.NoError(t, err)
+
+	claims := jwt.MapClaims{}
+	token, err := jwt.NewParser(jwt.WithValidMethods([]string{"ES256"})).ParseWithClaims(secret, claims, func(*jwt.Token) (interface{}, error) {
+		return &key.PublicKey, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "KEY1234567", token.Header["kid"])
+	assert.Equal(t, "TEAM123456", claims["iss"])
+	assert.Equal(t, "com.example.web", claims["sub"])
+	assert.Equal(t, "https://appleid.apple.com", claims["aud"])
+	assert.Equal(t, float64(now.Add(services.AppleClientSecretTTL).Unix()), claims["exp"])
+
+	_, err = services.GenerateAppleClientSecret("TEAM123456", "com.example.web", "KEY1234567", "not base64!", now)
+	assert.Error(t, err)
+}
@@ -0,0 +1,110 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/starks97/alcohol-tracker-api/internal/services"
+	"github.com/starks97/alcohol-tracker-api/internal/units"
+	"github.com/stretchr/testify/assert"
+)
+
+var testBacProfile = services.BacProfile{
+	WeightKg:        80,
+	Sex:             "male",
+	EliminationRate: 0.015,
+}
+
+func TestEthanolGrams(t *testing.T) {
+	// 330 ml of 5% beer holds 16.5 ml of ethanol.
+	assert.InDelta(t, 13.02, units.EthanolGrams(330, 5), 0.01)
+	assert.Equal(t, 0.0, units.EthanolGrams(330, 0))
+}
+
+func TestStandardDrinks(t *testing.T) {
+	grams := units.EthanolGrams(330, 5)
+
+	assert.InDelta(t, 0.93, units.StandardDrinks(grams, "US"), 0.01)
+	assert.InDelta(t, 1.30, units.StandardDrinks(grams, "AU"), 0.01)
+	assert.InDelta(t, 1.30, units.StandardDrinks(grams, "UK"), 0.01)
+	assert.InDelta(t, 1.63, units.StandardDrinks(grams, "UK_UNIT"), 0.01)
+	assert.Equal(t, units.StandardDrinks(grams, "US"), units.StandardDrinks(grams, "XX"))
+	assert.True(t, units.IsSupportedLocale("UK"))
+	assert.True(t, units.IsSupportedLocale("UK_UNIT"))
+	assert.False(t, units.IsSupportedLocale("XX"))
+}
+
+func TestEstimateBac_NoDrinks(t *testing.T) {
+	now := time.Date(2025, 1, 1, 22, 0, 0, 0, time.UTC)
+
+	estimate, err := services.EstimateBac(nil, testBacProfile, now, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.0, estimate.Current)
+	assert.Nil(t, estimate.SoberAt)
+	assert.Equal(t, 0, estimate.MinutesUntilSober)
+	assert.Len(t, estimate.Curve, 2*60+1)
+}
+
+func TestEstimateBac_SingleDrink(t *testing.T) {
+	now := time.Date(2025, 1, 1, 22, 0, 0, 0, time.UTC)
+	drinks := []services.BacDrink{{EthanolGrams: 14, ConsumedAt: now}}
+
+	estimate, err := services.EstimateBac(drinks, testBacProfile, now, 6)
+	assert.NoError(t, err)
+
+	// 14 / (0.68 * 80000) * 100
+	assert.InDelta(t, 0.0257, estimate.Current, 0.0001)
+	assert.NotNil(t, estimate.SoberAt)
+	assert.InDelta(t, 103, estimate.MinutesUntilSober, 1)
+	assert.Equal(t, 0.0, estimate.Curve[len(estimate.Curve)-1].BAC)
+}
+
+func TestEstimateBac_EliminatesPastDrinks(t *testing.T) {
+	now := time.Date(2025, 1, 1, 22, 0, 0, 0, time.UTC)
+	drinks := []services.BacDrink{{EthanolGrams: 14, ConsumedAt: now.Add(-time.Hour)}}
+
+	estimate, err := services.EstimateBac(drinks, testBacProfile, now, 1)
+	assert.NoError(t, err)
+	assert.InDelta(t, 0.0257-0.015, estimate.Current, 0.0001)
+}
+
+func TestEstimateBac_ExtrapolatesBeyondCurve(t *testing.T) {
+	now := time.Date(2025, 1, 1, 22, 0, 0, 0, time.UTC)
+	drinks := []services.BacDrink{{EthanolGrams: 100, ConsumedAt: now}}
+
+	estimate, err := services.EstimateBac(drinks, testBacProfile, now, 1)
+	assert.NoError(t, err)
+	assert.NotNil(t, estimate.SoberAt)
+	assert.True(t, estimate.SoberAt.After(now.Add(time.Hour)))
+}
+
+func TestEstimateBac_InvalidProfile(t *testing.T) {
+	now := time.Now()
+
+	_, err := services.EstimateBac(nil, services.BacProfile{WeightKg: 80, Sex: "unknown", EliminationRate: 0.015}, now, 1)
+	assert.Error(t, err)
+
+	_, err = services.EstimateBac(nil, services.BacProfile{WeightKg: 0, Sex: "female", EliminationRate: 0.015}, now, 1)
+	assert.Error(t, err)
+}
+
+func TestPeakBac(t *testing.T) {
+	start := time.Date(2024, 3, 9, 21, 0, 0, 0, time.UTC)
+	beer := units.EthanolGrams(500, 5)
+	drinks := []services.BacDrink{
+		{EthanolGrams: beer, ConsumedAt: start.Add(2 * time.Hour)},
+		{EthanolGrams: beer, ConsumedAt: start},
+		{EthanolGrams: beer, ConsumedAt: start.Add(time.Hour)},
+	}
+
+	peak, err := services.PeakBac(drinks, testBacProfile)
+	assert.NoError(t, err)
+	assert.Equal(t, start.Add(2*time.Hour), peak.Time)
+
+	// Three drinks raise the BAC by 3 x 0.0363 while two hours eliminate 0.03.
+	assert.InDelta(t, 0.0788, peak.BAC, 0.0005)
+
+	empty, err := services.PeakBac(nil, testBacProfile)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.0, empty.BAC)
+}
@@ -0,0 +1,51 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/starks97/alcohol-tracker-api/internal/entities"
+	"github.com/starks97/alcohol-tracker-api/internal/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaveBeverageUpdatesTheMatchingItem(t *testing.T) {
+	existingID := uuid.NewString()
+	db, fake := newFakeDB(t, func(query fakeQuery) fakeResult {
+		switch {
+		case strings.HasPrefix(query.SQL, `SELECT * FROM "beverages" WHERE barcode IN`):
+			// The catalog holds the EAN-13 spelling of the UPC-A code.
+			if query.Args[1] == "0012345678905" {
+				return fakeResult{
+					Columns: []string{"id", "brand", "name", "barcode"},
+					Rows:    [][]interface{}{{existingID, "Old", "Name", "0012345678905"}},
+				}
+			}
+			return fakeResult{Columns: []string{"id"}}
+		case strings.HasPrefix(query.SQL, `INSERT INTO "beverages"`):
+			return fakeResult{Columns: []string{"id"}, Rows: [][]interface{}{{uuid.NewString()}}}
+		}
+		return fakeResult{RowsAffected: 1}
+	})
+	beverageRepo := repositories.NewBeverageRepository(db)
+
+	upc := "012345678905"
+	updated := &entities.Beverage{Brand: "Brand", Name: "Lager", Category: "beer", DefaultABV: 5, Barcode: &upc}
+	isNew, err := beverageRepo.SaveBeverage(updated)
+	assert.NoError(t, err)
+	assert.False(t, isNew)
+	assert.Equal(t, existingID, updated.ID.String())
+	assert.Equal(t, "0012345678905", *updated.Barcode, "the stored barcode is kept")
+
+	other := "4006381333931"
+	isNew, err = beverageRepo.SaveBeverage(&entities.Beverage{Brand: "Brand", Name: "Pils", Category: "beer", DefaultABV: 4.8, Barcode: &other})
+	assert.NoError(t, err)
+	assert.True(t, isNew)
+
+	var statements []string
+	for _, query := range fake.Queries() {
+		statements = append(statements, strings.Fields(query)[0])
+	}
+	assert.Equal(t, []string{"SELECT", "UPDATE", "SELECT", "INSERT"}, statements)
+}
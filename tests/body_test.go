@@ -0,0 +1,38 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/starks97/alcohol-tracker-api/internal/units"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnitSystemOrDefault(t *testing.T) {
+	assert.Equal(t, units.UnitSystemMetric, units.UnitSystemOrDefault(""))
+	assert.Equal(t, units.UnitSystemImperial, units.UnitSystemOrDefault(units.UnitSystemImperial))
+}
+
+func TestBodyMeasurementConversions(t *testing.T) {
+	assert.InDelta(t, 68.04, units.WeightToKg(150, units.UnitSystemImperial), 0.01)
+	assert.Equal(t, 70.0, units.WeightToKg(70, units.UnitSystemMetric))
+	assert.Equal(t, 150.0, units.WeightFromKg(units.WeightToKg(150, units.UnitSystemImperial), units.UnitSystemImperial))
+	assert.Equal(t, 70.1, units.WeightFromKg(70.06, units.UnitSystemMetric))
+
+	assert.InDelta(t, 177.8, units.HeightToCm(70, units.UnitSystemImperial), 0.01)
+	assert.Equal(t, 180.0, units.HeightToCm(180, units.UnitSystemMetric))
+	assert.Equal(t, 70.0, units.HeightFromCm(units.HeightToCm(70, units.UnitSystemImperial), units.UnitSystemImperial))
+	assert.Equal(t, 180.3, units.HeightFromCm(180.25, units.UnitSystemMetric))
+}
+
+func TestBodyMeasurementRanges(t *testing.T) {
+	assert.True(t, units.ValidWeightKg(units.WeightToKg(1100, units.UnitSystemImperial)))
+	// The same number is in range in pounds but not in kilograms.
+	assert.False(t, units.ValidWeightKg(units.WeightToKg(1100, units.UnitSystemMetric)))
+	assert.True(t, units.ValidWeightKg(units.MaxWeightKg))
+	assert.False(t, units.ValidWeightKg(0))
+
+	assert.True(t, units.ValidHeightCm(units.HeightToCm(110, units.UnitSystemImperial)))
+	assert.False(t, units.ValidHeightCm(units.HeightToCm(300, units.UnitSystemImperial)))
+	assert.True(t, units.ValidHeightCm(units.MaxHeightCm))
+	assert.False(t, units.ValidHeightCm(-1))
+}
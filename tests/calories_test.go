@@ -0,0 +1,16 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/starks97/alcohol-tracker-api/internal/units"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEstimateCalories(t *testing.T) {
+	// 13.02 g of ethanol (91 kcal) plus 11.9 g of carbohydrates (48 kcal).
+	assert.InDelta(t, 139, units.EstimateCalories(330, 5, "beer"), 0.5)
+	// Spirits carry no carbohydrates, so only the ethanol counts.
+	assert.Equal(t, units.EthanolCalories(units.EthanolGrams(44, 40)), units.EstimateCalories(44, 40, "spirits"))
+	assert.Equal(t, 0.0, units.EstimateCalories(330, 0, "unknown"))
+}
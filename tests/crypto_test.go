@@ -0,0 +1,123 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"gorm.io/gorm/schema"
+
+	"github.com/starks97/alcohol-tracker-api/internal/entities"
+	"github.com/starks97/alcohol-tracker-api/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	oldEncryptionKey = bytes.Repeat([]byte{1}, 32)
+	newEncryptionKey = bytes.Repeat([]byte{2}, 32)
+)
+
+func TestKeyRingEncryptsAndRotates(t *testing.T) {
+	oldRing, err := utils.NewKeyRing(map[string][]byte{"2025": oldEncryptionKey}, "2025")
+	assert.NoError(t, err)
+
+	encrypted, err := oldRing.Encrypt("refresh-token")
+	assert.NoError(t, err)
+	assert.NotContains(t, encrypted, "refresh-token")
+	assert.True(t, strings.HasPrefix(encrypted, "enc:2025:"))
+
+	other, err := oldRing.Encrypt("refresh-token")
+	assert.NoError(t, err)
+	assert.NotEqual(t, encrypted, other, "every value has its own data key and nonce")
+
+	// After a rotation, values under the previous key still decrypt.
+	newRing, err := utils.NewKeyRing(map[string][]byte{"2025": oldEncryptionKey, "2026": newEncryptionKey}, "2026")
+	assert.NoError(t, err)
+
+	plaintext, err := newRing.Decrypt(encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, "refresh-token", plaintext)
+
+	keyID, err := newRing.KeyID(encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, "2025", keyID)
+
+	reencrypted, err := newRing.Encrypt(plaintext)
+	assert.NoError(t, err)
+	keyID, _ = newRing.KeyID(reencrypted)
+	assert.Equal(t, "2026", keyID)
+
+	// Without the old key, its values are lost.
+	newOnly, err := utils.NewKeyRing(map[string][]byte{"2026": newEncryptionKey}, "2026")
+	assert.NoError(t, err)
+	_, err = newOnly.Decrypt(encrypted)
+	assert.Error(t, err)
+}
+
+func TestKeyRingRejectsTamperedValues(t *testing.T) {
+	ring, err := utils.NewKeyRing(map[string][]byte{"a": oldEncryptionKey, "b": newEncryptionKey}, "a")
+	assert.NoError(t, err)
+
+	encrypted, err := ring.Encrypt("refresh-token")
+	assert.NoError(t, err)
+
+	parts := strings.Split(encrypted, ":")
+	last := []byte(parts[3])
+	last[len(last)-2] ^= 'A' ^ 'B'
+
+	tampered := []string{
+		"plaintext-token",
+		strings.Join(append(parts[:3:3], string(last)), ":"),
+		strings.Replace(encrypted, "enc:a:", "enc:b:", 1), // The key ID is authenticated.
+		strings.Replace(encrypted, "enc:a:", "enc:c:", 1),
+	}
+	for _, value := range tampered {
+		_, err := ring.Decrypt(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestNewKeyRingValidatesKeys(t *testing.T) {
+	_, err := utils.NewKeyRing(map[string][]byte{"a": oldEncryptionKey}, "b")
+	assert.Error(t, err)
+
+	_, err = utils.NewKeyRing(map[string][]byte{"a": []byte("short")}, "a")
+	assert.Error(t, err)
+
+	_, err = utils.NewKeyRing(map[string][]byte{"a:b": oldEncryptionKey}, "a:b")
+	assert.Error(t, err)
+}
+
+func TestIdentityRefreshTokenIsEncryptedAndHidden(t *testing.T) {
+	ring, err := utils.NewKeyRing(map[string][]byte{"a": oldEncryptionKey}, "a")
+	assert.NoError(t, err)
+	utils.SetEncryptionKeyRing(ring)
+
+	identitySchema, err := schema.Parse(&entities.UserIdentity{}, &sync.Map{}, schema.NamingStrategy{})
+	assert.NoError(t, err)
+	field := identitySchema.LookUpField("RefreshToken")
+
+	token := "refresh-token"
+	identity := entities.UserIdentity{Provider: "google", RefreshToken: &token}
+	ctx := context.Background()
+
+	stored, err := field.Serializer.Value(ctx, field, reflect.ValueOf(&identity), identity.RefreshToken)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(stored.(string), "enc:a:"))
+
+	var loaded entities.UserIdentity
+	assert.NoError(t, field.Serializer.Scan(ctx, field, reflect.ValueOf(&loaded), stored))
+	assert.Equal(t, "refresh-token", *loaded.RefreshToken)
+
+	stored, err = field.Serializer.Value(ctx, field, reflect.ValueOf(&identity), (*string)(nil))
+	assert.NoError(t, err)
+	assert.Nil(t, stored)
+
+	encoded, err := json.Marshal(identity)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(encoded), "refresh-token")
+}
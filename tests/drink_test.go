@@ -0,0 +1,37 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/starks97/alcohol-tracker-api/internal/entities"
+	"github.com/starks97/alcohol-tracker-api/internal/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateDrinkReturnsItsUpdateTime(t *testing.T) {
+	var written interface{}
+	db, _ := newFakeDB(t, func(query fakeQuery) fakeResult {
+		if strings.HasPrefix(query.SQL, `UPDATE "drink_entries"`) {
+			for i, column := range strings.Split(query.SQL[strings.Index(query.SQL, "SET"):], ",") {
+				if strings.Contains(column, `"updated_at"`) {
+					written = query.Args[i]
+				}
+			}
+			return fakeResult{RowsAffected: 1}
+		}
+		return fakeResult{}
+	})
+
+	before := time.Now()
+	drink := &entities.DrinkEntry{ID: uuid.New(), UserID: uuid.New(), UpdatedAt: before.Add(-time.Hour)}
+	updated, err := repositories.NewDrinkRepository(db).UpdateDrink(drink)
+	assert.NoError(t, err)
+
+	assert.False(t, updated.UpdatedAt.Before(before), "the update time is not the stale one read before")
+	if assert.IsType(t, time.Time{}, written) {
+		assert.True(t, updated.UpdatedAt.Equal(written.(time.Time)), "the update time returned is the one stored")
+	}
+}
@@ -0,0 +1,357 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// fakeQuery is a statement run against a fakeDB, with its arguments.
+type fakeQuery struct {
+	SQL  string
+	Args []interface{}
+}
+
+// fakeResult is what a fakeDB answers a statement with: rows for queries, the number of
+// affected rows for the others.
+type fakeResult struct {
+	Columns      []string
+	Rows         [][]interface{}
+	RowsAffected int64
+	Err          error
+}
+
+// fakeDB is a Postgres stand-in for GORM. It records every statement and answers it with
+// its handler, so repositories can be tested without a database.
+type fakeDB struct {
+	mu      sync.Mutex
+	queries []fakeQuery
+	handle  func(query fakeQuery) fakeResult
+}
+
+// newFakeDB returns a GORM connection answered by handle, and the fakeDB recording it.
+func newFakeDB(t *testing.T, handle func(query fakeQuery) fakeResult) (*gorm.DB, *fakeDB) {
+	fake := &fakeDB{handle: handle}
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sql.OpenDB(fake)}), &gorm.Config{
+		Logger:                 logger.Discard,
+		SkipDefaultTransaction: true,
+	})
+	assert.NoError(t, err)
+	return db, fake
+}
+
+// Queries returns the statements run so far, including BEGIN, COMMIT and ROLLBACK.
+func (f *fakeDB) Queries() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	queries := make([]string, len(f.queries))
+	for i, query := range f.queries {
+		queries[i] = query.SQL
+	}
+	return queries
+}
+
+func (f *fakeDB) run(query string, args []driver.NamedValue) fakeResult {
+	recorded := fakeQuery{SQL: query, Args: make([]interface{}, len(args))}
+	for i, arg := range args {
+		recorded.Args[i] = arg.Value
+	}
+
+	f.mu.Lock()
+	f.queries = append(f.queries, recorded)
+	f.mu.Unlock()
+
+	if f.handle == nil || strings.HasPrefix(query, "BEGIN") || query == "COMMIT" || query == "ROLLBACK" {
+		return fakeResult{}
+	}
+	return f.handle(recorded)
+}
+
+func (f *fakeDB) Connect(context.Context) (driver.Conn, error) { return &fakeConn{db: f}, nil }
+func (f *fakeDB) Driver() driver.Driver                        { return fakeDriver{} }
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) { return nil, errors.New("use the connector") }
+
+type fakeConn struct{ db *fakeDB }
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (c *fakeConn) Close() error                        { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *fakeConn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	c.db.run("BEGIN", nil)
+	return fakeTx{db: c.db}, nil
+}
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	result := c.db.run(query, args)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	return &fakeRows{columns: result.Columns, rows: result.Rows}, nil
+}
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	result := c.db.run(query, args)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	return driver.RowsAffected(result.RowsAffected), nil
+}
+
+type fakeTx struct{ db *fakeDB }
+
+func (tx fakeTx) Commit() error   { tx.db.run("COMMIT", nil); return nil }
+func (tx fakeTx) Rollback() error { tx.db.run("ROLLBACK", nil); return nil }
+
+type fakeRows struct {
+	columns []string
+	rows    [][]interface{}
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	for i, value := range r.rows[0] {
+		dest[i] = value
+	}
+	r.rows = r.rows[1:]
+	return nil
+}
+
+// newFakeRedis returns a Redis client answering GET, SET and DEL from values, without a
+// server. Other commands fail.
+func newFakeRedis(values map[string]string) *redis.Client {
+	return newFakeRedisClient(&fakeRedisHook{values: values})
+}
+
+// newFakeRedisClient returns a Redis client answered by hook, without a server.
+func newFakeRedisClient(hook *fakeRedisHook) *redis.Client {
+	if hook.values == nil {
+		hook.values = map[string]string{}
+	}
+	if hook.hashes == nil {
+		hook.hashes = map[string]map[string]string{}
+	}
+	client := redis.NewClient(&redis.Options{Addr: "fake:6379"})
+	client.AddHook(hook)
+	return client
+}
+
+// fakeRedisHook answers the string, hash, key and transaction commands of the app from
+// memory. Keys never expire; tests delete them to expire them. Scripts are answered by
+// eval, given the script's keys and arguments, with the hook already locked.
+type fakeRedisHook struct {
+	mu     sync.Mutex
+	values map[string]string
+	hashes map[string]map[string]string
+	eval   func(h *fakeRedisHook, keys []string, args []string) []interface{}
+}
+
+func (h *fakeRedisHook) DialHook(next redis.DialHook) redis.DialHook { return next }
+
+func (h *fakeRedisHook) ProcessHook(redis.ProcessHook) redis.ProcessHook {
+	return func(_ context.Context, cmd redis.Cmder) error {
+		h.process(cmd)
+		return cmd.Err()
+	}
+}
+
+func (h *fakeRedisHook) ProcessPipelineHook(redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(_ context.Context, cmds []redis.Cmder) error {
+		for _, cmd := range cmds {
+			h.process(cmd)
+		}
+		return nil
+	}
+}
+
+// Exists reports whether the key holds a string or a hash.
+func (h *fakeRedisHook) Exists(key string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, isValue := h.values[key]
+	_, isHash := h.hashes[key]
+	return isValue || isHash
+}
+
+// Hash returns a copy of the hash at key, or nil if there is none.
+func (h *fakeRedisHook) Hash(key string) map[string]string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	hash, ok := h.hashes[key]
+	if !ok {
+		return nil
+	}
+	copied := make(map[string]string, len(hash))
+	for field, value := range hash {
+		copied[field] = value
+	}
+	return copied
+}
+
+// Delete removes keys, as if they expired.
+func (h *fakeRedisHook) Delete(keys ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, key := range keys {
+		delete(h.values, key)
+		delete(h.hashes, key)
+	}
+}
+
+func (h *fakeRedisHook) process(cmd redis.Cmder) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	args := make([]string, len(cmd.Args()))
+	for i, arg := range cmd.Args() {
+		switch v := arg.(type) {
+		case string:
+			args[i] = v
+		case []byte:
+			args[i] = string(v)
+		default:
+			args[i] = fmt.Sprint(v)
+		}
+	}
+	exists := func(key string) bool {
+		_, isValue := h.values[key]
+		_, isHash := h.hashes[key]
+		return isValue || isHash
+	}
+
+	switch c := cmd.(type) {
+	case *redis.StringCmd:
+		switch cmd.Name() {
+		case "get":
+			if value, ok := h.values[args[1]]; ok {
+				c.SetVal(value)
+			} else {
+				c.SetErr(redis.Nil)
+			}
+			return
+		case "hget":
+			if value, ok := h.hashes[args[1]][args[2]]; ok {
+				c.SetVal(value)
+			} else {
+				c.SetErr(redis.Nil)
+			}
+			return
+		}
+	case *redis.StatusCmd:
+		switch cmd.Name() {
+		case "set":
+			h.values[args[1]] = args[2]
+			c.SetVal("OK")
+			return
+		case "multi":
+			c.SetVal("OK")
+			return
+		}
+	case *redis.SliceCmd:
+		if cmd.Name() == "exec" {
+			return
+		}
+	case *redis.IntCmd:
+		switch cmd.Name() {
+		case "del", "exists":
+			var count int64
+			for _, key := range args[1:] {
+				if exists(key) {
+					count++
+					if cmd.Name() == "del" {
+						delete(h.values, key)
+						delete(h.hashes, key)
+					}
+				}
+			}
+			c.SetVal(count)
+			return
+		case "hset":
+			hash := h.hashes[args[1]]
+			if hash == nil {
+				hash = map[string]string{}
+				h.hashes[args[1]] = hash
+			}
+			var added int64
+			for i := 2; i+1 < len(args); i += 2 {
+				if _, ok := hash[args[i]]; !ok {
+					added++
+				}
+				hash[args[i]] = args[i+1]
+			}
+			c.SetVal(added)
+			return
+		case "hdel":
+			var deleted int64
+			for _, field := range args[2:] {
+				if _, ok := h.hashes[args[1]][field]; ok {
+					delete(h.hashes[args[1]], field)
+					deleted++
+				}
+			}
+			if len(h.hashes[args[1]]) == 0 {
+				delete(h.hashes, args[1])
+			}
+			c.SetVal(deleted)
+			return
+		}
+	case *redis.BoolCmd:
+		switch cmd.Name() {
+		case "expire", "pexpire":
+			c.SetVal(exists(args[1]))
+			return
+		case "hexists":
+			_, ok := h.hashes[args[1]][args[2]]
+			c.SetVal(ok)
+			return
+		}
+	case *redis.MapStringStringCmd:
+		if cmd.Name() == "hgetall" {
+			hash := make(map[string]string, len(h.hashes[args[1]]))
+			for field, value := range h.hashes[args[1]] {
+				hash[field] = value
+			}
+			c.SetVal(hash)
+			return
+		}
+	case *redis.StringSliceCmd:
+		if cmd.Name() == "hkeys" {
+			fields := make([]string, 0, len(h.hashes[args[1]]))
+			for field := range h.hashes[args[1]] {
+				fields = append(fields, field)
+			}
+			c.SetVal(fields)
+			return
+		}
+	case *redis.Cmd:
+		if (cmd.Name() == "evalsha" || cmd.Name() == "eval") && h.eval != nil {
+			numKeys, _ := strconv.Atoi(args[2])
+			c.SetVal(h.eval(h, args[3:3+numKeys], args[3+numKeys:]))
+			return
+		}
+	}
+	cmd.SetErr(errors.New("fake redis: unsupported command " + cmd.Name()))
+}
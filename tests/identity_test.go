@@ -0,0 +1,206 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/starks97/alcohol-tracker-api/config"
+	"github.com/starks97/alcohol-tracker-api/internal/dtos"
+	"github.com/starks97/alcohol-tracker-api/internal/exceptions"
+	"github.com/starks97/alcohol-tracker-api/internal/repositories"
+	"github.com/starks97/alcohol-tracker-api/internal/state"
+	"github.com/starks97/alcohol-tracker-api/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// identityRow is a user_identities row of an identityStore.
+type identityRow struct {
+	UserID         string
+	Provider       string
+	ProviderUserID string
+}
+
+// identityStore answers the identity and user queries of a fakeDB from memory.
+type identityStore struct {
+	userID      string
+	name        string
+	picture     *string
+	password    *string
+	identities  []identityRow
+	created     []identityRow
+	deleteCount int64
+}
+
+func (s *identityStore) handle(query fakeQuery) fakeResult {
+	identityColumns := []string{"id", "user_id", "provider", "provider_user_id", "email", "linked_at"}
+	identityRows := func(match func(identityRow) bool) fakeResult {
+		result := fakeResult{Columns: identityColumns}
+		for _, row := range s.identities {
+			if match(row) {
+				result.Rows = append(result.Rows, []interface{}{uuid.NewString(), row.UserID, row.Provider, row.ProviderUserID, "", time.Now()})
+			}
+		}
+		return result
+	}
+
+	switch sql := query.SQL; {
+	case strings.HasPrefix(sql, `SELECT * FROM "users"`):
+		return fakeResult{
+			Columns: []string{"id", "email", "name", "profile_picture", "email_verified", "password"},
+			Rows:    [][]interface{}{{s.userID, "user@example.com", s.name, s.picture, true, s.password}},
+		}
+	case strings.HasPrefix(sql, `SELECT count(*) FROM "user_identities"`):
+		return fakeResult{Columns: []string{"count"}, Rows: [][]interface{}{{int64(len(s.identities))}}}
+	case strings.HasPrefix(sql, `SELECT * FROM "user_identities" WHERE provider = $1 AND provider_user_id = $2`):
+		return identityRows(func(row identityRow) bool {
+			return row.Provider == query.Args[0] && row.ProviderUserID == query.Args[1]
+		})
+	case strings.HasPrefix(sql, `SELECT * FROM "user_identities" WHERE user_id = $1`):
+		return identityRows(func(row identityRow) bool { return row.UserID == query.Args[0] })
+	case strings.HasPrefix(sql, `INSERT INTO "user_identities"`):
+		s.created = append(s.created, identityRow{UserID: query.Args[0].(string), Provider: query.Args[1].(string), ProviderUserID: query.Args[2].(string)})
+		return fakeResult{Columns: []string{"id"}, Rows: [][]interface{}{{uuid.NewString()}}}
+	case strings.HasPrefix(sql, `DELETE FROM "user_identities"`):
+		return fakeResult{RowsAffected: s.deleteCount}
+	}
+	return fakeResult{RowsAffected: 1}
+}
+
+func TestLinkOAuthIdentity(t *testing.T) {
+	userID := uuid.NewString()
+	oauthUser := dtos.OAuthDto{ID: "gh-1", Email: "user@example.com"}
+
+	for name, test := range map[string]struct {
+		identities []identityRow
+		err        error
+		linked     bool
+	}{
+		"new provider":           {identities: []identityRow{{userID, "google", "g-1"}}, linked: true},
+		"already linked":         {identities: []identityRow{{userID, "github", "gh-1"}}},
+		"linked to other user":   {identities: []identityRow{{uuid.NewString(), "github", "gh-1"}}, err: exceptions.ErrIdentityLinked},
+		"other provider account": {identities: []identityRow{{userID, "github", "gh-2"}}, err: exceptions.ErrProviderLinked},
+	} {
+		store := &identityStore{userID: userID, identities: test.identities}
+		db, _ := newFakeDB(t, store.handle)
+		appState := &state.AppState{DB: db, Config: &config.Config{}}
+
+		err := utils.LinkOAuthIdentity(appState, uuid.MustParse(userID), "github", oauthUser, "")
+		assert.Equal(t, test.err, err, name)
+		if test.linked {
+			assert.Equal(t, []identityRow{{userID, "github", "gh-1"}}, store.created, name)
+		} else {
+			assert.Empty(t, store.created, name)
+		}
+	}
+}
+
+func TestUnlinkIdentity(t *testing.T) {
+	userID := uuid.NewString()
+	password := "hashed"
+
+	for name, test := range map[string]struct {
+		password    *string
+		identities  []identityRow
+		deleteCount int64
+		err         error
+	}{
+		"other identity left": {identities: []identityRow{{userID, "github", "gh-1"}, {userID, "google", "g-1"}}, deleteCount: 1},
+		"password left":       {password: &password, identities: []identityRow{{userID, "github", "gh-1"}}, deleteCount: 1},
+		"last login method":   {identities: []identityRow{{userID, "github", "gh-1"}}, deleteCount: 1, err: repositories.ErrLastLoginMethod},
+		"not linked":          {identities: []identityRow{{userID, "google", "g-1"}}, err: gorm.ErrRecordNotFound},
+	} {
+		store := &identityStore{userID: userID, password: test.password, identities: test.identities, deleteCount: test.deleteCount}
+		db, fake := newFakeDB(t, store.handle)
+
+		err := repositories.NewIdentityRepository(db).UnlinkIdentity(uuid.MustParse(userID), "github")
+		assert.Equal(t, test.err, err, name)
+
+		queries := fake.Queries()
+		last := queries[len(queries)-1]
+		if test.err != nil {
+			// The delete is rolled back, and the legacy provider columns are left alone.
+			assert.Equal(t, "ROLLBACK", last, name)
+			continue
+		}
+		assert.Equal(t, "COMMIT", last, name)
+		assert.True(t, strings.HasPrefix(queries[len(queries)-2], `UPDATE "users" SET "provider"=$1,"provider_id"=$2`), name)
+	}
+}
+
+func TestResolveOAuthUserKeepsProfileWithoutProviderValues(t *testing.T) {
+	userID := uuid.NewString()
+	picture := "https://example.com/me.png"
+	store := &identityStore{userID: userID, name: "Stored Name", picture: &picture, identities: []identityRow{{userID, "apple", "a-1"}}}
+	db, fake := newFakeDB(t, store.handle)
+	appState := &state.AppState{DB: db, Config: &config.Config{}}
+
+	// Apple sends no name after the first authorization, and never a picture.
+	user, err := utils.ResolveOAuthUser(appState, "apple", dtos.OAuthDto{ID: "a-1", Email: "user@example.com"}, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "Stored Name", user.Name)
+	assert.Equal(t, &picture, user.ProfilePicture)
+
+	var update fakeQuery
+	for _, query := range fake.queries {
+		if strings.HasPrefix(query.SQL, `UPDATE "users"`) {
+			update = query
+		}
+	}
+	assert.Contains(t, update.Args, "Stored Name")
+	assert.Contains(t, update.Args, picture)
+	assert.NotContains(t, update.Args, "")
+}
+
+func TestOAuthRefreshTokenIsStoredOnIdentity(t *testing.T) {
+	ring, err := utils.NewKeyRing(map[string][]byte{"a": oldEncryptionKey}, "a")
+	assert.NoError(t, err)
+	utils.SetEncryptionKeyRing(ring)
+
+	userID := uuid.NewString()
+	encryptedArg := func(query fakeQuery) string {
+		for _, arg := range query.Args {
+			if value, ok := arg.(string); ok && strings.HasPrefix(value, "enc:a:") {
+				plaintext, err := ring.Decrypt(value)
+				assert.NoError(t, err)
+				return plaintext
+			}
+		}
+		return ""
+	}
+
+	// A login with a linked identity replaces the identity's token.
+	store := &identityStore{userID: userID, name: "Jane", identities: []identityRow{{userID, "google", "g-1"}}}
+	db, fake := newFakeDB(t, store.handle)
+	appState := &state.AppState{DB: db, Config: &config.Config{}}
+
+	_, err = utils.ResolveOAuthUser(appState, "google", dtos.OAuthDto{ID: "g-1", Email: "user@example.com"}, "google-refresh")
+	assert.NoError(t, err)
+	var stored string
+	for _, query := range fake.queries {
+		if strings.HasPrefix(query.SQL, `UPDATE "user_identities" SET "refresh_token"=$1 WHERE id = $2`) {
+			stored = encryptedArg(query)
+		}
+		if strings.HasPrefix(query.SQL, `UPDATE "users"`) {
+			assert.Empty(t, encryptedArg(query), "the user row holds no provider token")
+		}
+	}
+	assert.Equal(t, "google-refresh", stored)
+
+	// Linking another provider keeps its token on the new identity.
+	store = &identityStore{userID: userID, identities: []identityRow{{userID, "google", "g-1"}}}
+	db, fake = newFakeDB(t, store.handle)
+	appState = &state.AppState{DB: db, Config: &config.Config{}}
+
+	err = utils.LinkOAuthIdentity(appState, uuid.MustParse(userID), "github", dtos.OAuthDto{ID: "gh-1"}, "github-refresh")
+	assert.NoError(t, err)
+	stored = ""
+	for _, query := range fake.queries {
+		if strings.HasPrefix(query.SQL, `INSERT INTO "user_identities"`) {
+			stored = encryptedArg(query)
+		}
+	}
+	assert.Equal(t, "github-refresh", stored)
+}
@@ -0,0 +1,96 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/starks97/alcohol-tracker-api/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluateLimits(t *testing.T) {
+	daily, weekly, freeDays := 2.0, 10.0, 4
+	limits := services.Limits{
+		DailyStandardDrinks:    &daily,
+		WeeklyStandardDrinks:   &weekly,
+		AlcoholFreeDaysPerWeek: &freeDays,
+	}
+
+	// Thursday evening; the week started on Monday 2024-03-04.
+	now := time.Date(2024, 3, 7, 20, 0, 0, 0, time.UTC)
+	drinks := []services.LimitDrink{
+		{StandardDrinks: 3, ConsumedAt: time.Date(2024, 3, 1, 21, 0, 0, 0, time.UTC)}, // Over the limit, last week.
+		{StandardDrinks: 1.5, ConsumedAt: time.Date(2024, 3, 5, 19, 0, 0, 0, time.UTC)},
+		{StandardDrinks: 1, ConsumedAt: time.Date(2024, 3, 7, 18, 0, 0, 0, time.UTC)},
+		{StandardDrinks: 0.5, ConsumedAt: time.Date(2024, 3, 7, 19, 0, 0, 0, time.UTC)},
+		{StandardDrinks: 5, ConsumedAt: time.Date(2024, 3, 7, 22, 0, 0, 0, time.UTC)}, // Not consumed yet.
+	}
+
+	status := services.EvaluateLimits(limits, drinks, now, time.UTC)
+
+	assert.Equal(t, 1.5, status.Daily.Consumed)
+	assert.Equal(t, 0.5, status.Daily.Remaining)
+	assert.False(t, status.Daily.Exceeded)
+
+	assert.Equal(t, 3.0, status.Weekly.Consumed)
+	assert.Equal(t, 7.0, status.Weekly.Remaining)
+
+	assert.Equal(t, 2, status.AlcoholFreeDays.DrinkingDays)
+	assert.Equal(t, 1, status.AlcoholFreeDays.RemainingDrinkingDays)
+	assert.False(t, status.AlcoholFreeDays.Exceeded)
+
+	// 2024-03-02 through 2024-03-07 stayed within the daily limit.
+	assert.Equal(t, 6, status.StreakDays)
+}
+
+func TestEvaluateLimitsExceeded(t *testing.T) {
+	daily := 1.0
+	now := time.Date(2024, 3, 7, 20, 0, 0, 0, time.UTC)
+	drinks := []services.LimitDrink{
+		{StandardDrinks: 2, ConsumedAt: time.Date(2024, 3, 7, 18, 0, 0, 0, time.UTC)},
+	}
+
+	status := services.EvaluateLimits(services.Limits{DailyStandardDrinks: &daily}, drinks, now, time.UTC)
+
+	assert.True(t, status.Daily.Exceeded)
+	assert.Equal(t, 0.0, status.Daily.Remaining)
+	assert.Equal(t, 0, status.StreakDays)
+	assert.Nil(t, status.Weekly)
+	assert.Nil(t, status.AlcoholFreeDays)
+}
+
+func TestEvaluateLimitsStreakWithoutDailyLimit(t *testing.T) {
+	// Thursday evening; the week started on Monday 2024-03-04.
+	now := time.Date(2024, 3, 7, 20, 0, 0, 0, time.UTC)
+	at := func(month time.Month, day int) time.Time { return time.Date(2024, month, day, 20, 0, 0, 0, time.UTC) }
+
+	// Last week went over 10 drinks on Friday, which also breaks its weekend.
+	weekly := 10.0
+	drinks := []services.LimitDrink{
+		{StandardDrinks: 12, ConsumedAt: at(time.March, 1)},
+		{StandardDrinks: 4, ConsumedAt: at(time.March, 5)},
+	}
+	status := services.EvaluateLimits(services.Limits{WeeklyStandardDrinks: &weekly}, drinks, now, time.UTC)
+	assert.Equal(t, 4, status.StreakDays)
+
+	// Last week had a third drinking day on Thursday, one more than 5 alcohol-free days allow.
+	freeDays := 5
+	drinks = []services.LimitDrink{
+		{StandardDrinks: 1, ConsumedAt: at(time.February, 27)},
+		{StandardDrinks: 1, ConsumedAt: at(time.February, 28)},
+		{StandardDrinks: 1, ConsumedAt: at(time.February, 29)},
+		{StandardDrinks: 1, ConsumedAt: at(time.March, 4)},
+	}
+	status = services.EvaluateLimits(services.Limits{AlcoholFreeDaysPerWeek: &freeDays}, drinks, now, time.UTC)
+	assert.Equal(t, 4, status.StreakDays)
+
+	// Without any limit there is nothing to keep.
+	status = services.EvaluateLimits(services.Limits{}, drinks, now, time.UTC)
+	assert.Equal(t, 0, status.StreakDays)
+}
+
+func TestStartOfWeek(t *testing.T) {
+	sunday := time.Date(2024, 3, 10, 23, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC), services.StartOfWeek(sunday, time.UTC))
+}
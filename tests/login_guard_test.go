@@ -0,0 +1,39 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/starks97/alcohol-tracker-api/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoginPolicyLockAfter(t *testing.T) {
+	policy := utils.LoginPolicy{FreeAttempts: 5, BaseLock: 30 * time.Second, MaxLock: 15 * time.Minute, Window: time.Hour}
+
+	// The free attempts never lock.
+	for failures := int64(0); failures <= 5; failures++ {
+		assert.Zero(t, policy.LockAfter(failures), failures)
+	}
+
+	// Each further failure doubles the lock, up to the maximum.
+	assert.Equal(t, 30*time.Second, policy.LockAfter(6))
+	assert.Equal(t, time.Minute, policy.LockAfter(7))
+	assert.Equal(t, 2*time.Minute, policy.LockAfter(8))
+	assert.Equal(t, 8*time.Minute, policy.LockAfter(10))
+	assert.Equal(t, 15*time.Minute, policy.LockAfter(11))
+	assert.Equal(t, 15*time.Minute, policy.LockAfter(1_000_000))
+}
+
+func TestLoginPoliciesTolerateMoreFailuresPerIP(t *testing.T) {
+	failures := utils.EmailLoginPolicy.FreeAttempts + 1
+	assert.Positive(t, utils.EmailLoginPolicy.LockAfter(failures))
+	assert.Zero(t, utils.IPLoginPolicy.LockAfter(failures))
+	assert.Positive(t, utils.IPLoginPolicy.LockAfter(utils.IPLoginPolicy.FreeAttempts+1))
+}
+
+func TestRetryAfterSeconds(t *testing.T) {
+	assert.Equal(t, "30", utils.RetryAfterSeconds(30*time.Second))
+	assert.Equal(t, "30", utils.RetryAfterSeconds(29*time.Second+time.Millisecond))
+	assert.Equal(t, "1", utils.RetryAfterSeconds(time.Millisecond))
+}
@@ -0,0 +1,51 @@
+package tests
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/starks97/alcohol-tracker-api/config"
+	"github.com/starks97/alcohol-tracker-api/internal/mailer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogMailerRecordsAndWritesMessages(t *testing.T) {
+	dir := t.TempDir()
+	logMailer := mailer.NewLogMailer(dir)
+
+	err := logMailer.Send(context.Background(), mailer.Message{
+		To:      "jane@example.com",
+		Subject: "Verify your email address",
+		Body:    "Open the link:\nhttps://example.com/verify-email?token=abc",
+	})
+	assert.NoError(t, err)
+
+	sent := logMailer.Sent()
+	assert.Len(t, sent, 1)
+	assert.Equal(t, "jane@example.com", sent[0].To)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+
+	content, err := os.ReadFile(files[0])
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "To: jane@example.com\r\n")
+	assert.Contains(t, string(content), "Subject: Verify your email address\r\n")
+	assert.Contains(t, string(content), "\r\nhttps://example.com/verify-email?token=abc")
+}
+
+func TestNewMailerSelectsDriver(t *testing.T) {
+	logMailer, err := mailer.NewMailer(&config.Config{MailDriver: mailer.DriverLog})
+	assert.NoError(t, err)
+	assert.IsType(t, &mailer.LogMailer{}, logMailer)
+
+	smtpMailer, err := mailer.NewMailer(&config.Config{MailDriver: mailer.DriverSMTP, SmtpHost: "localhost", SmtpPort: 25})
+	assert.NoError(t, err)
+	assert.IsType(t, &mailer.SMTPMailer{}, smtpMailer)
+
+	_, err = mailer.NewMailer(&config.Config{MailDriver: "pigeon"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,111 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang.org/x/oauth2"
+
+	"github.com/starks97/alcohol-tracker-api/config"
+	"github.com/starks97/alcohol-tracker-api/internal/dtos"
+	"github.com/starks97/alcohol-tracker-api/internal/state"
+	"github.com/starks97/alcohol-tracker-api/internal/strategies"
+	"github.com/stretchr/testify/assert"
+)
+
+// newStubProvider serves documents by path, only to requests carrying the access token.
+func newStubProvider(t *testing.T, documents map[string]interface{}) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer access-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		document, ok := documents[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(document)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestProviderUserInfoIsNormalized(t *testing.T) {
+	token := &oauth2.Token{AccessToken: "access-token", TokenType: "Bearer"}
+
+	cases := []struct {
+		name      string
+		documents map[string]interface{}
+		strategy  func(appState *state.AppState, apiURL string) strategies.AuthStrategy
+		expected  dtos.OAuthDto
+	}{
+		{
+			name: "github uses the primary email of /user/emails",
+			documents: map[string]interface{}{
+				"/user": map[string]interface{}{"id": 42, "login": "jdoe", "name": nil, "email": nil, "avatar_url": "https://avatars.example.com/42"},
+				"/user/emails": []map[string]interface{}{
+					{"email": "old@example.com", "primary": false, "verified": true},
+					{"email": "jane@example.com", "primary": true, "verified": true},
+				},
+			},
+			strategy: func(appState *state.AppState, apiURL string) strategies.AuthStrategy {
+				return &strategies.GitHubStrategy{AppState: appState, APIURL: apiURL}
+			},
+			expected: dtos.OAuthDto{ID: "42", Email: "jane@example.com", VerifiedEmail: true, Name: "jdoe", Picture: "https://avatars.example.com/42"},
+		},
+		{
+			name: "google",
+			documents: map[string]interface{}{
+				"/oauth2/v2/userinfo": map[string]interface{}{"id": "1234", "email": "jane@example.com", "verified_email": true, "name": "Jane Doe", "given_name": "Jane", "family_name": "Doe"},
+			},
+			strategy: func(appState *state.AppState, apiURL string) strategies.AuthStrategy {
+				return &strategies.GoogleStrategy{AppState: appState, APIURL: apiURL}
+			},
+			expected: dtos.OAuthDto{ID: "1234", Email: "jane@example.com", VerifiedEmail: true, Name: "Jane Doe", GivenName: "Jane", FamilyName: "Doe"},
+		},
+		{
+			name: "discord falls back to the username",
+			documents: map[string]interface{}{
+				"/users/@me": map[string]interface{}{"id": "80351110224678912", "username": "jdoe", "global_name": nil, "email": "jane@example.com", "verified": false, "avatar": "abc"},
+			},
+			strategy: func(appState *state.AppState, apiURL string) strategies.AuthStrategy {
+				return &strategies.DiscordStrategy{AppState: appState, APIURL: apiURL}
+			},
+			expected: dtos.OAuthDto{ID: "80351110224678912", Email: "jane@example.com", Name: "jdoe", Picture: "https://cdn.discordapp.com/avatars/80351110224678912/abc.png"},
+		},
+		{
+			name: "microsoft never reports a verified email",
+			documents: map[string]interface{}{
+				"/v1.0/me": map[string]interface{}{"id": "guid", "displayName": "Jane Doe", "givenName": "Jane", "surname": "Doe", "mail": nil, "userPrincipalName": "jane@example.com"},
+			},
+			strategy: func(appState *state.AppState, apiURL string) strategies.AuthStrategy {
+				return &strategies.MicrosoftStrategy{AppState: appState, APIURL: apiURL}
+			},
+			expected: dtos.OAuthDto{ID: "guid", Email: "jane@example.com", Name: "Jane Doe", GivenName: "Jane", FamilyName: "Doe"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := newStubProvider(t, tc.documents)
+			appState := &state.AppState{Config: &config.Config{}, HttpClient: server.Client()}
+
+			user, err := tc.strategy(appState, server.URL).GetUserInfo(context.Background(), token)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, user)
+		})
+	}
+}
+
+func TestProviderUserInfoFailsOnErrorStatus(t *testing.T) {
+	server := newStubProvider(t, map[string]interface{}{})
+	appState := &state.AppState{Config: &config.Config{}, HttpClient: server.Client()}
+	strategy := &strategies.GitHubStrategy{AppState: appState, APIURL: server.URL}
+
+	_, err := strategy.GetUserInfo(context.Background(), &oauth2.Token{AccessToken: "access-token", TokenType: "Bearer"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,164 @@
+package tests
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/oauth2"
+
+	"github.com/starks97/alcohol-tracker-api/config"
+	"github.com/starks97/alcohol-tracker-api/internal/dtos"
+	"github.com/starks97/alcohol-tracker-api/internal/state"
+	"github.com/starks97/alcohol-tracker-api/internal/strategies"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubIssuer is an OpenID Provider serving discovery, keys and a token endpoint that
+// returns idToken.
+type stubIssuer struct {
+	server  *httptest.Server
+	key     *rsa.PrivateKey
+	idToken func(issuer string) jwt.MapClaims
+	signer  *rsa.PrivateKey
+
+	codeVerifier string // The PKCE verifier received by the token endpoint.
+}
+
+func newStubIssuer(t *testing.T) *stubIssuer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	stub := &stubIssuer{key: key, signer: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 stub.server.URL,
+			"authorization_endpoint": stub.server.URL + "/authorize",
+			"token_endpoint":         stub.server.URL + "/token",
+			"jwks_uri":               stub.server.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "test-key",
+				"alg": "RS256",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		stub.codeVerifier = r.FormValue("code_verifier")
+
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, stub.idToken(stub.server.URL))
+		token.Header["kid"] = "test-key"
+		signed, err := token.SignedString(stub.signer)
+		assert.NoError(t, err)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "access",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+			"id_token":     signed,
+		})
+	})
+	stub.server = httptest.NewServer(mux)
+	t.Cleanup(stub.server.Close)
+	return stub
+}
+
+func validIDToken(issuer string) jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":            issuer,
+		"sub":            "user-1",
+		"aud":            "client-id",
+		"iat":            now.Unix(),
+		"exp":            now.Add(5 * time.Minute).Unix(),
+		"nonce":          "expected-nonce",
+		"email":          "jane@example.com",
+		"email_verified": "true",
+		"name":           "Jane Doe",
+	}
+}
+
+func loginWithStubIssuer(t *testing.T, stub *stubIssuer) (dtos.OAuthDto, error) {
+	appState := &state.AppState{HttpClient: stub.server.Client()}
+	strategy, err := strategies.NewOIDCStrategy(context.Background(), appState, config.OIDCProviderConfig{
+		Name:     "stub",
+		Issuer:   stub.server.URL,
+		ClientID: "client-id",
+		Scopes:   []string{"openid", "email"},
+	})
+	assert.NoError(t, err)
+
+	strategy.UseNonce("expected-nonce")
+	verifier := oauth2.GenerateVerifier()
+	authURL, err := url.Parse(strategy.GenerateAuthURL("state", oauth2.S256ChallengeOption(verifier)))
+	assert.NoError(t, err)
+	assert.Equal(t, "expected-nonce", authURL.Query().Get("nonce"))
+	assert.Equal(t, "S256", authURL.Query().Get("code_challenge_method"))
+
+	token, err := strategy.ExchangeCode(context.Background(), "code", oauth2.VerifierOption(verifier))
+	assert.NoError(t, err)
+
+	// The provider accepts the code only if the verifier hashes to the challenge.
+	challenge := sha256.Sum256([]byte(stub.codeVerifier))
+	assert.Equal(t, base64.RawURLEncoding.EncodeToString(challenge[:]), authURL.Query().Get("code_challenge"))
+
+	return strategy.GetUserInfo(context.Background(), token)
+}
+
+func TestOIDCStrategyVerifiesIDToken(t *testing.T) {
+	stub := newStubIssuer(t)
+	stub.idToken = validIDToken
+
+	user, err := loginWithStubIssuer(t, stub)
+	assert.NoError(t, err)
+	assert.Equal(t, "user-1", user.ID)
+	assert.Equal(t, "jane@example.com", user.Email)
+	assert.True(t, user.VerifiedEmail)
+	assert.Equal(t, "Jane Doe", user.Name)
+}
+
+func TestOIDCStrategyRejectsInvalidIDTokens(t *testing.T) {
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	cases := map[string]func(claims jwt.MapClaims, stub *stubIssuer){
+		"wrong nonce":    func(claims jwt.MapClaims, _ *stubIssuer) { claims["nonce"] = "other-nonce" },
+		"wrong audience": func(claims jwt.MapClaims, _ *stubIssuer) { claims["aud"] = "other-client" },
+		"wrong issuer":   func(claims jwt.MapClaims, _ *stubIssuer) { claims["iss"] = "https://attacker.example.com" },
+		"expired":        func(claims jwt.MapClaims, _ *stubIssuer) { claims["exp"] = time.Now().Add(-time.Hour).Unix() },
+		"wrong key":      func(_ jwt.MapClaims, stub *stubIssuer) { stub.signer = otherKey },
+	}
+
+	for name, tamper := range cases {
+		t.Run(name, func(t *testing.T) {
+			stub := newStubIssuer(t)
+			stub.idToken = func(issuer string) jwt.MapClaims {
+				claims := validIDToken(issuer)
+				tamper(claims, stub)
+				return claims
+			}
+
+			_, err := loginWithStubIssuer(t, stub)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/starks97/alcohol-tracker-api/config"
+	"github.com/starks97/alcohol-tracker-api/internal/entities"
+	"github.com/starks97/alcohol-tracker-api/internal/state"
+	"github.com/starks97/alcohol-tracker-api/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPasswordFingerprintChangesWithPassword(t *testing.T) {
+	first, second := "hash-one", "hash-two"
+
+	noPassword := utils.PasswordFingerprint(&entities.User{})
+	assert.NotEqual(t, noPassword, utils.PasswordFingerprint(&entities.User{Password: &first}))
+	assert.NotEqual(t, utils.PasswordFingerprint(&entities.User{Password: &first}), utils.PasswordFingerprint(&entities.User{Password: &second}))
+	assert.Equal(t, utils.PasswordFingerprint(&entities.User{Password: &first}), utils.PasswordFingerprint(&entities.User{Password: &first}))
+}
+
+func TestParsePasswordReset(t *testing.T) {
+	userID := uuid.New()
+
+	parsedID, fingerprint, ok := utils.ParsePasswordReset(userID.String() + " abcdef")
+	assert.True(t, ok)
+	assert.Equal(t, userID, parsedID)
+	assert.Equal(t, "abcdef", fingerprint)
+
+	_, _, ok = utils.ParsePasswordReset("not-a-uuid abcdef")
+	assert.False(t, ok)
+	_, _, ok = utils.ParsePasswordReset(userID.String())
+	assert.False(t, ok)
+}
+
+func TestClientURLUsesFirstOrigin(t *testing.T) {
+	appState := &state.AppState{Config: &config.Config{ClientOrigin: "https://app.example.com/, http://localhost:3000"}}
+
+	assert.Equal(t, "https://app.example.com/reset-password?token=a.b%2Bc", utils.ClientURL(appState, "/reset-password", "a.b+c"))
+}
@@ -0,0 +1,43 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/starks97/alcohol-tracker-api/internal/exceptions"
+	"github.com/starks97/alcohol-tracker-api/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseListQuery(t *testing.T) {
+	parse := func(target string) (utils.ListQuery, error) {
+		var query utils.ListQuery
+		var err error
+		app := fiber.New()
+		app.Get("/", func(c *fiber.Ctx) error {
+			query, err = utils.ParseListQuery(c, 20, 100)
+			return nil
+		})
+		_, testErr := app.Test(httptest.NewRequest(http.MethodGet, target, nil))
+		assert.NoError(t, testErr)
+		return query, err
+	}
+
+	query, err := parse("/")
+	assert.NoError(t, err)
+	assert.Equal(t, utils.ListQuery{Limit: 20}, query)
+
+	query, err = parse("/?from=2024-06-01T00:00:00Z&to=2024-06-30T23:59:59Z&limit=500&offset=40")
+	assert.NoError(t, err)
+	from := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 6, 30, 23, 59, 59, 0, time.UTC)
+	assert.Equal(t, utils.ListQuery{From: &from, To: &to, Limit: 100, Offset: 40}, query)
+
+	for _, target := range []string{"/?from=yesterday", "/?to=2024-06-01", "/?limit=0", "/?limit=ten", "/?offset=-1"} {
+		_, err := parse(target)
+		assert.Equal(t, exceptions.ErrInvalidQuery, err, target)
+	}
+}
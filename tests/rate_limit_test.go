@@ -0,0 +1,76 @@
+package tests
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/starks97/alcohol-tracker-api/config"
+	"github.com/starks97/alcohol-tracker-api/internal/middleware"
+	"github.com/starks97/alcohol-tracker-api/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRateLimitPolicy(t *testing.T) {
+	policy, err := config.ParseRateLimitPolicy("20/1m:ip+route")
+	assert.NoError(t, err)
+	assert.Equal(t, 20, policy.Limit)
+	assert.Equal(t, time.Minute, policy.Window)
+	assert.Equal(t, []string{config.RateLimitByIP, config.RateLimitByRoute}, policy.KeyBy)
+
+	policy, err = config.ParseRateLimitPolicy("5/30s")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{config.RateLimitByIP}, policy.KeyBy)
+
+	for _, spec := range []string{"20", "0/1m", "20/1ms", "20/1m:device", "x/1m"} {
+		_, err := config.ParseRateLimitPolicy(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestSlidingWindowResult(t *testing.T) {
+	policy := config.RateLimitPolicy{Limit: 10, Window: time.Minute}
+
+	// Half way through the window, half of the previous window's 8 requests still count.
+	allowed := utils.SlidingWindowResult(policy, 30*time.Second, 3, 8, true)
+	assert.True(t, allowed.Allowed)
+	assert.Equal(t, 3, allowed.Remaining)
+	assert.Equal(t, 30*time.Second, allowed.Reset)
+	assert.Zero(t, allowed.RetryAfter)
+
+	// 6 current + 16 * 0.5 previous is over the limit; requests fit again once the
+	// previous window's weight drops below 4/16, 15 seconds later.
+	rejected := utils.SlidingWindowResult(policy, 30*time.Second, 6, 16, false)
+	assert.False(t, rejected.Allowed)
+	assert.Equal(t, 0, rejected.Remaining)
+	assert.Equal(t, 15*time.Second, rejected.RetryAfter)
+
+	// The current window alone is full: wait into the next one.
+	full := utils.SlidingWindowResult(policy, 45*time.Second, 10, 0, false)
+	assert.Equal(t, 15*time.Second, full.RetryAfter)
+}
+
+func TestRateLimitRouteMatchesRouting(t *testing.T) {
+	routeOf := func(app *fiber.App, path string) string {
+		res, err := app.Test(httptest.NewRequest(http.MethodPost, path, nil))
+		assert.NoError(t, err)
+		body, err := io.ReadAll(res.Body)
+		assert.NoError(t, err)
+		return string(body)
+	}
+	route := func(c *fiber.Ctx) error { return c.SendString(middleware.RateLimitRoute(c)) }
+
+	// Every spelling the router sends to the login handler counts as the same route.
+	app := fiber.New()
+	app.Post("/auth/login", route)
+	for _, path := range []string{"/auth/login", "/auth/LOGIN", "/auth/login/", "/Auth/Login//"} {
+		assert.Equal(t, "/auth/login", routeOf(app, path), path)
+	}
+
+	strict := fiber.New(fiber.Config{CaseSensitive: true, StrictRouting: true})
+	strict.Post("/auth/login/", route)
+	assert.Equal(t, "/auth/login/", routeOf(strict, "/auth/login/"))
+}
@@ -0,0 +1,118 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/starks97/alcohol-tracker-api/config"
+	"github.com/starks97/alcohol-tracker-api/internal/middleware"
+	"github.com/starks97/alcohol-tracker-api/internal/responses"
+	"github.com/starks97/alcohol-tracker-api/internal/services"
+	"github.com/starks97/alcohol-tracker-api/internal/state"
+	"github.com/starks97/alcohol-tracker-api/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireScope(t *testing.T) {
+	app := fiber.New()
+	grantedScopes := func(c *fiber.Ctx) error {
+		if c.Get("X-Test-Anonymous") == "" {
+			c.Locals("mdlData", &responses.JwtMiddlewareResponse{Scopes: []string{"drinks", "profile"}})
+		}
+		return c.Next()
+	}
+	ok := func(c *fiber.Ctx) error { return c.SendStatus(http.StatusOK) }
+
+	app.Get("/drinks", grantedScopes, middleware.RequireScope("drinks"), ok)
+	app.Get("/both", grantedScopes, middleware.RequireScope("drinks", "profile"), ok)
+	app.Get("/stats", grantedScopes, middleware.RequireScope("drinks", "stats"), ok)
+
+	for path, status := range map[string]int{
+		"/drinks": http.StatusOK,
+		"/both":   http.StatusOK,
+		"/stats":  http.StatusForbidden,
+	} {
+		res, err := app.Test(httptest.NewRequest(http.MethodGet, path, nil))
+		assert.NoError(t, err)
+		assert.Equal(t, status, res.StatusCode, path)
+	}
+
+	// Without an authenticated request there are no scopes to check.
+	req := httptest.NewRequest(http.MethodGet, "/drinks", nil)
+	req.Header.Set("X-Test-Anonymous", "1")
+	res, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+}
+
+func TestJWTAuthMiddlewareGrantsTokenScopes(t *testing.T) {
+	ring, err := utils.NewKeyRing(map[string][]byte{"test": bytes.Repeat([]byte{3}, 32)}, "test")
+	assert.NoError(t, err)
+	utils.SetEncryptionKeyRing(ring)
+	encryptedKey, err := ring.Encrypt(testTokenPrivateBase64)
+	assert.NoError(t, err)
+	signingKey, err := services.ParseSigningKey(testTokenPrivateBase64)
+	assert.NoError(t, err)
+
+	userID := uuid.New()
+	db, _ := newFakeDB(t, func(query fakeQuery) fakeResult {
+		switch {
+		case strings.Contains(query.SQL, `FROM "signing_keys"`):
+			return fakeResult{
+				Columns: []string{"id", "purpose", "private_key", "activates_at"},
+				Rows:    [][]interface{}{{signingKey.ID, query.Args[0], encryptedKey, time.Unix(0, 0)}},
+			}
+		case strings.Contains(query.SQL, `FROM "users"`):
+			return fakeResult{
+				Columns: []string{"id", "email", "name", "email_verified"},
+				Rows:    [][]interface{}{{userID.String(), "user@example.com", "User", true}},
+			}
+		}
+		return fakeResult{}
+	})
+
+	appState := &state.AppState{
+		DB:    db,
+		Redis: newFakeRedis(map[string]string{}),
+		Config: &config.Config{
+			AccessTokenPrivateKey:  testTokenPrivateBase64,
+			RefreshTokenPrivateKey: testTokenPrivateBase64,
+			TokenIssuer:            "alcohol-tracker-api",
+			TokenAudience:          "alcohol-tracker-app",
+			TokenScopes:            config.DefaultTokenScopes,
+		},
+	}
+
+	// An access token granting only the drinks scope, stored like at login.
+	accessToken, err := utils.SignJwtToken(appState, services.TokenTypeAccess, userID, 30, []string{"drinks"})
+	assert.NoError(t, err)
+	assert.NoError(t, appState.Redis.Set(context.Background(), accessToken.TokenUUID.String(), userID.String(), time.Minute).Err())
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("appState", appState)
+		c.Locals("ctx", context.Background())
+		return c.Next()
+	})
+	ok := func(c *fiber.Ctx) error { return c.SendStatus(http.StatusOK) }
+	app.Get("/drinks", middleware.JWTAuthMiddleware(), middleware.RequireScope("drinks"), ok)
+	app.Get("/stats", middleware.JWTAuthMiddleware(), middleware.RequireScope("stats"), ok)
+
+	for path, status := range map[string]int{
+		"/drinks": http.StatusOK,
+		"/stats":  http.StatusForbidden,
+	} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer "+*accessToken.Token)
+		res, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, status, res.StatusCode, path)
+	}
+}
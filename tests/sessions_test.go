@@ -0,0 +1,199 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/starks97/alcohol-tracker-api/config"
+	"github.com/starks97/alcohol-tracker-api/internal/entities"
+	"github.com/starks97/alcohol-tracker-api/internal/exceptions"
+	"github.com/starks97/alcohol-tracker-api/internal/handlers/sessions"
+	"github.com/starks97/alcohol-tracker-api/internal/responses"
+	"github.com/starks97/alcohol-tracker-api/internal/services"
+	"github.com/starks97/alcohol-tracker-api/internal/state"
+	"github.com/starks97/alcohol-tracker-api/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitAtGaps(t *testing.T) {
+	start := time.Date(2024, 6, 1, 20, 0, 0, 0, time.UTC)
+	at := func(hours float64) time.Time { return start.Add(time.Duration(hours * float64(time.Hour))) }
+
+	assert.Nil(t, services.SplitAtGaps(nil, 3*time.Hour))
+	assert.Equal(t, []int{0}, services.SplitAtGaps([]time.Time{at(0), at(1), at(2.5)}, 3*time.Hour))
+	// A gap of exactly the configured length already separates two sessions.
+	assert.Equal(t, []int{0, 2, 3}, services.SplitAtGaps([]time.Time{at(0), at(1), at(4), at(8)}, 3*time.Hour))
+}
+
+// sessionStore answers the drinking session queries of a fakeDB from memory.
+type sessionStore struct {
+	manual   map[string]bool                   // Sessions by ID, and whether they are manual.
+	drinks   map[string]string                 // Session IDs by drink ID.
+	consumed map[string]time.Time              // Consumption times by drink ID.
+	updates  map[string]map[string]interface{} // The last columns written to each session.
+}
+
+func (s *sessionStore) handle(query fakeQuery) fakeResult {
+	switch sql := query.SQL; {
+	case strings.HasPrefix(sql, `SELECT * FROM "drinking_sessions" WHERE id = $1`):
+		manual, ok := s.manual[query.Args[0].(string)]
+		if !ok {
+			return fakeResult{Columns: []string{"id"}}
+		}
+		return fakeResult{Columns: []string{"id", "manual"}, Rows: [][]interface{}{{query.Args[0], manual}}}
+	case strings.HasPrefix(sql, `SELECT * FROM "drink_entries" WHERE user_id = $1 AND session_id = $2`):
+		result := fakeResult{Columns: []string{"id", "session_id", "consumed_at", "volume_ml", "abv"}}
+		for drinkID, sessionID := range s.drinks {
+			if sessionID == query.Args[1] {
+				result.Rows = append(result.Rows, []interface{}{drinkID, sessionID, s.consumed[drinkID], 500.0, 5.0})
+			}
+		}
+		sort.Slice(result.Rows, func(i, j int) bool { return result.Rows[i][2].(time.Time).Before(result.Rows[j][2].(time.Time)) })
+		return result
+	case strings.HasPrefix(sql, `INSERT INTO "drinking_sessions"`):
+		id := uuid.NewString()
+		s.manual[id] = false
+		return fakeResult{Columns: []string{"id"}, Rows: [][]interface{}{{id}}}
+	case strings.HasPrefix(sql, `UPDATE "drink_entries" SET "session_id"=$1`):
+		s.drinks[query.Args[len(query.Args)-1].(string)] = query.Args[0].(string)
+		return fakeResult{RowsAffected: 1}
+	case strings.HasPrefix(sql, `UPDATE "drinking_sessions" SET `):
+		// Zip the SET columns with their arguments; the session ID follows them.
+		set := strings.Split(sql[len(`UPDATE "drinking_sessions" SET `):strings.Index(sql, " WHERE ")], ",")
+		columns := map[string]interface{}{}
+		for i, assignment := range set {
+			columns[strings.Trim(strings.Split(assignment, "=")[0], `"`)] = query.Args[i]
+		}
+		s.updates[query.Args[len(set)].(string)] = columns
+		return fakeResult{RowsAffected: 1}
+	}
+	return fakeResult{RowsAffected: 1}
+}
+
+func TestRefreshSessionSplitsAtGaps(t *testing.T) {
+	start := time.Date(2024, 6, 1, 20, 0, 0, 0, time.UTC)
+	sessionID := uuid.NewString()
+	store := &sessionStore{
+		manual:   map[string]bool{sessionID: false},
+		drinks:   map[string]string{},
+		consumed: map[string]time.Time{},
+		updates:  map[string]map[string]interface{}{},
+	}
+	// The drink that bridged 1h and 5h was moved away, leaving a four hour gap.
+	for _, hours := range []int{0, 1, 5, 6} {
+		drinkID := uuid.NewString()
+		store.drinks[drinkID] = sessionID
+		store.consumed[drinkID] = start.Add(time.Duration(hours) * time.Hour)
+	}
+
+	db, _ := newFakeDB(t, store.handle)
+	appState := &state.AppState{DB: db, Config: &config.Config{SessionGap: 3 * time.Hour}}
+
+	err := utils.RefreshSession(appState, db, &entities.User{ID: uuid.New()}, uuid.MustParse(sessionID))
+	assert.NoError(t, err)
+
+	assert.Len(t, store.manual, 2, "the later drinks start a session of their own")
+	for id := range store.manual {
+		update := store.updates[id]
+		assert.Equal(t, int64(2), update["drink_count"], id)
+		if id == sessionID {
+			assert.Equal(t, start, update["started_at"])
+			assert.Equal(t, start.Add(time.Hour), update["ended_at"])
+		} else {
+			assert.Equal(t, start.Add(5*time.Hour), update["started_at"])
+			assert.Equal(t, start.Add(6*time.Hour), update["ended_at"])
+		}
+	}
+}
+
+func TestStartSessionLosingARaceIsAConflict(t *testing.T) {
+	// The running session check finds nothing, but a concurrent start inserts first and
+	// this insert hits the unique index on running manual sessions.
+	db, _ := newFakeDB(t, func(query fakeQuery) fakeResult {
+		switch {
+		case strings.HasPrefix(query.SQL, `SELECT * FROM "drinking_sessions"`):
+			return fakeResult{Columns: []string{"id"}}
+		case strings.HasPrefix(query.SQL, `INSERT INTO "drinking_sessions"`):
+			return fakeResult{Err: &pgconn.PgError{Code: "23505", ConstraintName: "idx_drinking_sessions_running"}}
+		}
+		return fakeResult{}
+	})
+	appState := &state.AppState{DB: db, Config: &config.Config{}, Validator: exceptions.Init()}
+
+	app := fiber.New()
+	app.Post("/sessions/start", func(c *fiber.Ctx) error {
+		c.Locals("appState", appState)
+		c.Locals("mdlData", &responses.JwtMiddlewareResponse{User: entities.User{ID: uuid.New()}})
+		return c.Next()
+	}, sessions.StartSessionHandler)
+
+	res, err := app.Test(httptest.NewRequest(http.MethodPost, "/sessions/start", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusConflict, res.StatusCode)
+
+	var body exceptions.ErrorResponse
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&body))
+	assert.Equal(t, exceptions.ErrSessionRunning.Error(), body.Message)
+}
+
+func TestUpdateSessionSetsOnlyTheLocation(t *testing.T) {
+	userID, sessionID := uuid.New(), uuid.New()
+	db, fake := newFakeDB(t, func(query fakeQuery) fakeResult {
+		switch {
+		case strings.HasPrefix(query.SQL, `UPDATE "drinking_sessions"`):
+			if query.Args[len(query.Args)-2] != sessionID.String() {
+				return fakeResult{}
+			}
+			return fakeResult{RowsAffected: 1}
+		case strings.HasPrefix(query.SQL, `SELECT * FROM "drinking_sessions"`):
+			return fakeResult{
+				Columns: []string{"id", "user_id", "location", "started_at"},
+				Rows:    [][]interface{}{{sessionID.String(), userID.String(), "Joe's birthday", time.Now()}},
+			}
+		}
+		return fakeResult{}
+	})
+	appState := &state.AppState{DB: db, Config: &config.Config{SessionGap: 3 * time.Hour}, Validator: exceptions.Init()}
+
+	app := fiber.New()
+	app.Patch("/sessions/:id", func(c *fiber.Ctx) error {
+		c.Locals("appState", appState)
+		c.Locals("mdlData", &responses.JwtMiddlewareResponse{User: entities.User{ID: userID}})
+		return c.Next()
+	}, sessions.UpdateSessionHandler)
+
+	patch := func(id uuid.UUID) *http.Response {
+		req := httptest.NewRequest(http.MethodPatch, "/sessions/"+id.String(), strings.NewReader(`{"location": "Joe's birthday"}`))
+		req.Header.Set("Content-Type", "application/json")
+		res, err := app.Test(req)
+		assert.NoError(t, err)
+		return res
+	}
+
+	res := patch(sessionID)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	var body struct {
+		Data responses.SessionResponse `json:"data"`
+	}
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&body))
+	if assert.NotNil(t, body.Data.Location) {
+		assert.Equal(t, "Joe's birthday", *body.Data.Location)
+	}
+
+	queries := fake.Queries()
+	if assert.NotEmpty(t, queries) {
+		assert.Contains(t, queries[0], `SET "location"=$1`)
+		assert.NotContains(t, queries[0], "total_", "drink totals are left alone")
+	}
+
+	// Another user's session, or one that does not exist, is not found.
+	assert.Equal(t, http.StatusNotFound, patch(uuid.New()).StatusCode)
+}
@@ -0,0 +1,56 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/starks97/alcohol-tracker-api/internal/repositories"
+	"github.com/starks97/alcohol-tracker-api/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBucketStartRespectsTimeZone(t *testing.T) {
+	madrid, err := time.LoadLocation("Europe/Madrid")
+	assert.NoError(t, err)
+
+	// 23:30 UTC on 31 January is already 1 February in Madrid.
+	instant := time.Date(2024, 1, 31, 23, 30, 0, 0, time.UTC)
+
+	assert.Equal(t, time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC), services.BucketStart(instant, services.StatsBucketDay, time.UTC))
+	assert.Equal(t, time.Date(2024, 2, 1, 0, 0, 0, 0, madrid), services.BucketStart(instant, services.StatsBucketDay, madrid))
+	assert.Equal(t, time.Date(2024, 1, 29, 0, 0, 0, 0, madrid), services.BucketStart(instant, services.StatsBucketWeek, madrid))
+	assert.Equal(t, time.Date(2024, 2, 1, 0, 0, 0, 0, madrid), services.BucketStart(instant, services.StatsBucketMonth, madrid))
+}
+
+func TestNextBucketStartAcrossDST(t *testing.T) {
+	madrid, err := time.LoadLocation("Europe/Madrid")
+	assert.NoError(t, err)
+
+	// Clocks go forward on 31 March 2024, so that day is only 23 hours long.
+	day := time.Date(2024, 3, 31, 0, 0, 0, 0, madrid)
+
+	assert.Equal(t, time.Date(2024, 4, 1, 0, 0, 0, 0, madrid), services.NextBucketStart(day, services.StatsBucketDay))
+	assert.Equal(t, time.Date(2024, 4, 1, 0, 0, 0, 0, madrid), services.NextBucketStart(time.Date(2024, 3, 1, 0, 0, 0, 0, madrid), services.StatsBucketMonth))
+}
+
+func TestStatsQueriesGroupByBucket(t *testing.T) {
+	db, fake := newFakeDB(t, func(fakeQuery) fakeResult { return fakeResult{} })
+	repo := repositories.NewStatsRepository(db)
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	_, err := repo.ConsumptionByBucket(uuid.New(), from, from.AddDate(0, 1, 0), services.StatsBucketDay, "UTC")
+	assert.NoError(t, err)
+	_, err = repo.SpendingByBucket(uuid.New(), from, from.AddDate(0, 1, 0), services.StatsBucketDay, "UTC")
+	assert.NoError(t, err)
+
+	queries := fake.Queries()
+	assert.Len(t, queries, 2)
+	// A quoted "1" would name a column, which Postgres rejects.
+	for _, query := range queries {
+		assert.NotContains(t, query, `"1"`)
+	}
+	assert.True(t, strings.HasSuffix(queries[0], `GROUP BY "bucket_start" ORDER BY bucket_start`), queries[0])
+	assert.True(t, strings.HasSuffix(queries[1], `GROUP BY 1, 2, 3 ORDER BY 1, 2, 3`), queries[1])
+}
@@ -0,0 +1,241 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/starks97/alcohol-tracker-api/config"
+	"github.com/starks97/alcohol-tracker-api/internal/dtos"
+	"github.com/starks97/alcohol-tracker-api/internal/exceptions"
+	"github.com/starks97/alcohol-tracker-api/internal/services"
+	"github.com/starks97/alcohol-tracker-api/internal/state"
+	"github.com/starks97/alcohol-tracker-api/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeRotateRefresh answers the refresh token rotation script like Redis would run it.
+func fakeRotateRefresh(h *fakeRedisHook, keys []string, args []string) []interface{} {
+	family, ok := h.hashes[keys[0]]
+	if !ok || family["refresh"] == "" {
+		return []interface{}{int64(-1)}
+	}
+	if family["refresh"] != args[0] {
+		return []interface{}{int64(0)}
+	}
+	previousAccess := family["access"]
+	family["refresh"], family["access"] = args[1], args[2]
+	h.values[keys[1]] = args[3]
+	return []interface{}{int64(1), family["user_id"], previousAccess}
+}
+
+// newTokenFamilyState returns an app state whose Redis runs the rotation script.
+func newTokenFamilyState() (*state.AppState, *fakeRedisHook) {
+	hook := &fakeRedisHook{eval: fakeRotateRefresh}
+	return &state.AppState{
+		Redis:  newFakeRedisClient(hook),
+		Config: &config.Config{AccessTokenMaxAge: 15, RefreshTokenMaxAge: 60},
+	}, hook
+}
+
+func TestRotateRefreshToken(t *testing.T) {
+	appState, hook := newTokenFamilyState()
+	store := utils.NewTokenFamilyStore(appState)
+	ctx := context.Background()
+
+	userID, firstRefresh, firstAccess := uuid.New(), uuid.New(), uuid.New()
+	familyID, err := store.Create(ctx, userID, firstRefresh, firstAccess, utils.ClientInfo{UserAgent: "phone"})
+	assert.NoError(t, err)
+
+	nextRefresh, nextAccess := uuid.New(), uuid.New()
+	rotated, err := store.Rotate(ctx, firstRefresh, nextRefresh, nextAccess, utils.ClientInfo{UserAgent: "phone"})
+	assert.NoError(t, err)
+	assert.Equal(t, familyID, rotated.FamilyID)
+	assert.Equal(t, userID, rotated.UserID)
+	assert.Equal(t, firstAccess.String(), rotated.PreviousAccess)
+
+	family := hook.Hash("refresh_family:" + familyID.String())
+	assert.Equal(t, nextRefresh.String(), family["refresh"])
+	assert.Equal(t, nextAccess.String(), family["access"])
+	nextFamilyID, err := store.FamilyOf(ctx, nextRefresh)
+	assert.NoError(t, err)
+	assert.Equal(t, familyID, nextFamilyID)
+}
+
+func TestReusedRefreshTokenRevokesFamily(t *testing.T) {
+	appState, hook := newTokenFamilyState()
+	store := utils.NewTokenFamilyStore(appState)
+	ctx := context.Background()
+
+	userID, firstRefresh := uuid.New(), uuid.New()
+	familyID, err := store.Create(ctx, userID, firstRefresh, uuid.New(), utils.ClientInfo{})
+	assert.NoError(t, err)
+	nextRefresh, nextAccess := uuid.New(), uuid.New()
+	_, err = store.Rotate(ctx, firstRefresh, nextRefresh, nextAccess, utils.ClientInfo{})
+	assert.NoError(t, err)
+	assert.NoError(t, appState.Redis.Set(ctx, nextAccess.String(), userID.String(), time.Minute).Err())
+
+	// The rotated token is presented again, by an attacker or by a second tab refreshing
+	// in parallel: both look the same, and the whole family is revoked.
+	_, err = store.Rotate(ctx, firstRefresh, uuid.New(), uuid.New(), utils.ClientInfo{})
+	assert.ErrorIs(t, err, exceptions.ErrRefreshTokenReused)
+
+	assert.False(t, hook.Exists("refresh_family:"+familyID.String()))
+	assert.False(t, hook.Exists(nextAccess.String()), "the family's access token stops working")
+	assert.Nil(t, hook.Hash("user_sessions:"+userID.String()))
+
+	// The legitimate holder of the current token is signed out too.
+	_, err = store.Rotate(ctx, nextRefresh, uuid.New(), uuid.New(), utils.ClientInfo{})
+	assert.ErrorIs(t, err, exceptions.ErrRedisNotFound)
+}
+
+func TestRotateWithoutFamilyIsNotFound(t *testing.T) {
+	appState, hook := newTokenFamilyState()
+	store := utils.NewTokenFamilyStore(appState)
+	ctx := context.Background()
+
+	_, err := store.Rotate(ctx, uuid.New(), uuid.New(), uuid.New(), utils.ClientInfo{})
+	assert.ErrorIs(t, err, exceptions.ErrRedisNotFound, "unknown refresh token")
+
+	refresh := uuid.New()
+	familyID, err := store.Create(ctx, uuid.New(), refresh, uuid.Nil, utils.ClientInfo{})
+	assert.NoError(t, err)
+	hook.Delete("refresh_family:" + familyID.String())
+
+	_, err = store.Rotate(ctx, refresh, uuid.New(), uuid.New(), utils.ClientInfo{})
+	assert.ErrorIs(t, err, exceptions.ErrRedisNotFound, "expired family")
+}
+
+func TestRotateTokensDeletesPreviousAccessToken(t *testing.T) {
+	ring, err := utils.NewKeyRing(map[string][]byte{"test": bytes.Repeat([]byte{3}, 32)}, "test")
+	assert.NoError(t, err)
+	utils.SetEncryptionKeyRing(ring)
+	encryptedKey, err := ring.Encrypt(testTokenPrivateBase64)
+	assert.NoError(t, err)
+	signingKey, err := services.ParseSigningKey(testTokenPrivateBase64)
+	assert.NoError(t, err)
+
+	db, _ := newFakeDB(t, func(query fakeQuery) fakeResult {
+		if strings.Contains(query.SQL, `FROM "signing_keys"`) {
+			return fakeResult{
+				Columns: []string{"id", "purpose", "private_key", "activates_at"},
+				Rows:    [][]interface{}{{signingKey.ID, query.Args[0], encryptedKey, time.Unix(0, 0)}},
+			}
+		}
+		return fakeResult{}
+	})
+
+	appState, hook := newTokenFamilyState()
+	appState.DB = db
+	appState.Config.AccessTokenPrivateKey = testTokenPrivateBase64
+	appState.Config.RefreshTokenPrivateKey = testTokenPrivateBase64
+	appState.Config.TokenIssuer = "alcohol-tracker-api"
+	appState.Config.TokenAudience = "alcohol-tracker-app"
+	ctx := context.Background()
+
+	userID, refresh, access := uuid.New(), uuid.New(), uuid.New()
+	familyID, err := utils.NewTokenFamilyStore(appState).Create(ctx, userID, refresh, access, utils.ClientInfo{})
+	assert.NoError(t, err)
+	assert.NoError(t, appState.Redis.Set(ctx, access.String(), userID.String(), time.Minute).Err())
+
+	app := fiber.New()
+	app.Post("/refresh", func(c *fiber.Ctx) error {
+		presented := dtos.TokenDetailsDto{TokenUUID: refresh, UserID: userID, Scopes: []string{"drinks"}}
+		if _, err := utils.NewTokenService(appState).RotateTokens(c, ctx, presented); err != nil {
+			return err
+		}
+		return c.SendStatus(http.StatusOK)
+	})
+
+	res, err := app.Test(httptest.NewRequest(http.MethodPost, "/refresh", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	assert.False(t, hook.Exists(access.String()), "the previous access token is deleted")
+	newAccess := hook.Hash("refresh_family:" + familyID.String())["access"]
+	assert.NotEqual(t, access.String(), newAccess)
+	assert.True(t, hook.Exists(newAccess), "the new access token is stored")
+
+	var cookie *http.Cookie
+	for _, c := range res.Cookies() {
+		if c.Name == "refresh_token" {
+			cookie = c
+		}
+	}
+	if assert.NotNil(t, cookie) {
+		assert.NotEmpty(t, cookie.Value)
+	}
+}
+
+func TestListSessionsPrunesExpiredFamilies(t *testing.T) {
+	appState, hook := newTokenFamilyState()
+	store := utils.NewTokenFamilyStore(appState)
+	ctx := context.Background()
+
+	userID := uuid.New()
+	activeID, err := store.Create(ctx, userID, uuid.New(), uuid.New(), utils.ClientInfo{UserAgent: "laptop"})
+	assert.NoError(t, err)
+	expiredID, err := store.Create(ctx, userID, uuid.New(), uuid.New(), utils.ClientInfo{UserAgent: "phone"})
+	assert.NoError(t, err)
+	hook.Delete("refresh_family:" + expiredID.String())
+
+	sessions, err := store.ListSessions(ctx, userID)
+	assert.NoError(t, err)
+	if assert.Len(t, sessions, 1) {
+		assert.Equal(t, activeID, sessions[0].ID)
+		assert.Equal(t, "laptop", sessions[0].UserAgent)
+	}
+
+	index := hook.Hash("user_sessions:" + userID.String())
+	assert.Contains(t, index, activeID.String())
+	assert.NotContains(t, index, expiredID.String(), "the expired session is pruned from the index")
+}
+
+func TestRevokeSessionOfAnotherUserIsRefused(t *testing.T) {
+	appState, hook := newTokenFamilyState()
+	store := utils.NewTokenFamilyStore(appState)
+	ctx := context.Background()
+
+	ownerID := uuid.New()
+	familyID, err := store.Create(ctx, ownerID, uuid.New(), uuid.New(), utils.ClientInfo{})
+	assert.NoError(t, err)
+
+	err = store.RevokeSession(ctx, uuid.New(), familyID)
+	assert.ErrorIs(t, err, exceptions.ErrAuthSessionNotFound)
+	assert.True(t, hook.Exists("refresh_family:"+familyID.String()), "the owner's session is kept")
+
+	assert.NoError(t, store.RevokeSession(ctx, ownerID, familyID))
+	assert.False(t, hook.Exists("refresh_family:"+familyID.String()))
+}
+
+func TestRevokeAllDeletesEveryFamily(t *testing.T) {
+	appState, hook := newTokenFamilyState()
+	store := utils.NewTokenFamilyStore(appState)
+	ctx := context.Background()
+
+	userID := uuid.New()
+	var keys []string
+	for range 2 {
+		refresh, access := uuid.New(), uuid.New()
+		familyID, err := store.Create(ctx, userID, refresh, access, utils.ClientInfo{})
+		assert.NoError(t, err)
+		assert.NoError(t, appState.Redis.Set(ctx, access.String(), userID.String(), time.Minute).Err())
+		keys = append(keys, "refresh_family:"+familyID.String(), "refresh_token:"+refresh.String(), access.String())
+	}
+	otherFamilyID, err := store.Create(ctx, uuid.New(), uuid.New(), uuid.New(), utils.ClientInfo{})
+	assert.NoError(t, err)
+
+	assert.NoError(t, store.RevokeAll(ctx, userID))
+
+	for _, key := range keys {
+		assert.False(t, hook.Exists(key), key)
+	}
+	assert.False(t, hook.Exists("user_sessions:"+userID.String()))
+	assert.True(t, hook.Exists("refresh_family:"+otherFamilyID.String()), "other users stay signed in")
+}
@@ -0,0 +1,113 @@
+package tests
+
+import (
+	"encoding/base32"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/starks97/alcohol-tracker-api/internal/entities"
+	"github.com/starks97/alcohol-tracker-api/internal/repositories"
+	"github.com/starks97/alcohol-tracker-api/internal/services"
+	"github.com/starks97/alcohol-tracker-api/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+// rfc6238Secret is the SHA-1 seed of the RFC 6238 test vectors.
+var rfc6238Secret = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestTotpCodeMatchesRfc6238(t *testing.T) {
+	// The RFC lists 8-digit codes; the last 6 digits are the 6-digit codes.
+	vectors := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+
+	for unix, expected := range vectors {
+		code, err := services.TotpCode(rfc6238Secret, time.Unix(unix, 0))
+		assert.NoError(t, err)
+		assert.Equal(t, expected, code, "time %d", unix)
+	}
+}
+
+func TestVerifyTotpAcceptsClockSkew(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	previous, _ := services.TotpCode(rfc6238Secret, now.Add(-services.TotpPeriod))
+	tooOld, _ := services.TotpCode(rfc6238Secret, now.Add(-3*services.TotpPeriod))
+
+	step, ok := services.VerifyTotp(rfc6238Secret, previous, now)
+	assert.True(t, ok)
+	assert.Equal(t, services.TotpStep(now)-1, step)
+
+	_, ok = services.VerifyTotp(rfc6238Secret, tooOld, now)
+	assert.False(t, ok)
+
+	_, ok = services.VerifyTotp(rfc6238Secret, "12345", now)
+	assert.False(t, ok)
+}
+
+func TestTotpURI(t *testing.T) {
+	uri := services.TotpURI("Alcohol Tracker", "jane@example.com", "JBSWY3DPEHPK3PXP")
+
+	assert.Contains(t, uri, "otpauth://totp/Alcohol%20Tracker:jane@example.com?")
+	assert.Contains(t, uri, "secret=JBSWY3DPEHPK3PXP")
+	assert.Contains(t, uri, "issuer=Alcohol+Tracker")
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := services.GenerateRecoveryCodes()
+	assert.NoError(t, err)
+	assert.Len(t, codes, services.RecoveryCodeCount)
+	assert.Regexp(t, `^[a-z2-9]{5}-[a-z2-9]{5}$`, codes[0])
+	assert.NotEqual(t, codes[0], codes[1])
+
+	// Case, spaces and dashes typed by the user do not change the hash.
+	assert.Equal(t, services.HashRecoveryCode("abcde-fghjk"), services.HashRecoveryCode(" ABCDE FGHJK "))
+	assert.NotEqual(t, services.HashRecoveryCode("abcde-fghjk"), services.HashRecoveryCode("abcde-fghjm"))
+}
+
+func TestTotpSecretIsNeverSerialized(t *testing.T) {
+	user := entities.User{Email: "jane@example.com", TotpSecret: &rfc6238Secret, TwoFactorEnabled: true}
+
+	encoded, err := json.Marshal(user)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(encoded), rfc6238Secret)
+	assert.NotContains(t, string(encoded), "TotpSecret")
+}
+
+func TestTotpSecretIsStoredEncrypted(t *testing.T) {
+	ring, err := utils.NewKeyRing(map[string][]byte{"a": oldEncryptionKey}, "a")
+	assert.NoError(t, err)
+	utils.SetEncryptionKeyRing(ring)
+
+	db, fake := newFakeDB(t, func(fakeQuery) fakeResult { return fakeResult{RowsAffected: 1} })
+	userRepo := repositories.NewUserRepository(db)
+	userID := uuid.New()
+
+	assert.NoError(t, userRepo.UpdateTwoFactor(userID, &rfc6238Secret, true))
+	assert.NoError(t, userRepo.UpdateTwoFactor(userID, nil, false))
+
+	var stored []interface{}
+	for _, query := range fake.queries {
+		assert.True(t, strings.HasPrefix(query.SQL, `UPDATE "users" SET "totp_secret"=$1,"two_factor_enabled"=$2`), query.SQL)
+		stored = append(stored, query.Args[0])
+	}
+	assert.Len(t, stored, 2)
+
+	encrypted, ok := stored[0].(string)
+	assert.True(t, ok)
+	assert.True(t, strings.HasPrefix(encrypted, "enc:a:"))
+	assert.NotContains(t, encrypted, rfc6238Secret)
+	secret, err := ring.Decrypt(encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, rfc6238Secret, secret)
+
+	// Disabling 2FA clears the secret.
+	assert.Nil(t, stored[1])
+}
@@ -0,0 +1,111 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/starks97/alcohol-tracker-api/config"
+	"github.com/starks97/alcohol-tracker-api/internal/exceptions"
+	"github.com/starks97/alcohol-tracker-api/internal/routes"
+	"github.com/starks97/alcohol-tracker-api/internal/services"
+	"github.com/starks97/alcohol-tracker-api/internal/state"
+	"github.com/starks97/alcohol-tracker-api/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnverifiedEmailIsRejectedOnSensitiveRoutes(t *testing.T) {
+	ring, err := utils.NewKeyRing(map[string][]byte{"test": bytes.Repeat([]byte{3}, 32)}, "test")
+	assert.NoError(t, err)
+	utils.SetEncryptionKeyRing(ring)
+	encryptedKey, err := ring.Encrypt(testTokenPrivateBase64)
+	assert.NoError(t, err)
+	signingKey, err := services.ParseSigningKey(testTokenPrivateBase64)
+	assert.NoError(t, err)
+
+	userID := uuid.New()
+	db, fake := newFakeDB(t, func(query fakeQuery) fakeResult {
+		switch {
+		case strings.Contains(query.SQL, `FROM "signing_keys"`):
+			return fakeResult{
+				Columns: []string{"id", "purpose", "private_key", "activates_at"},
+				Rows:    [][]interface{}{{signingKey.ID, query.Args[0], encryptedKey, time.Unix(0, 0)}},
+			}
+		case strings.Contains(query.SQL, `FROM "users"`):
+			return fakeResult{
+				Columns: []string{"id", "email", "name", "email_verified"},
+				Rows:    [][]interface{}{{userID.String(), "someone@example.com", "Someone", false}},
+			}
+		}
+		return fakeResult{}
+	})
+
+	appState := &state.AppState{
+		DB:    db,
+		Redis: newFakeRedis(map[string]string{}),
+		Config: &config.Config{
+			AccessTokenPrivateKey:  testTokenPrivateBase64,
+			RefreshTokenPrivateKey: testTokenPrivateBase64,
+			TokenIssuer:            "alcohol-tracker-api",
+			TokenAudience:          "alcohol-tracker-app",
+			TokenScopes:            config.DefaultTokenScopes,
+		},
+		Validator: exceptions.Init(),
+	}
+
+	accessToken, err := utils.SignJwtToken(appState, services.TokenTypeAccess, userID, 30, config.DefaultTokenScopes)
+	assert.NoError(t, err)
+	assert.NoError(t, appState.Redis.Set(context.Background(), accessToken.TokenUUID.String(), userID.String(), time.Minute).Err())
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("appState", appState)
+		c.Locals("ctx", context.Background())
+		return c.Next()
+	})
+	routes.SetupRoutes(app, appState)
+
+	do := func(method, path, body string) *http.Response {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+*accessToken.Token)
+		req.Header.Set("Content-Type", "application/json")
+		res, err := app.Test(req)
+		assert.NoError(t, err)
+		return res
+	}
+
+	// Reading the profile needs no verified address.
+	assert.Equal(t, http.StatusOK, do(http.MethodGet, "/me", "").StatusCode)
+
+	for _, route := range [][3]string{
+		{http.MethodPost, "/auth/identities/google", `{}`},
+		{http.MethodDelete, "/auth/identities/google", `{}`},
+		{http.MethodPatch, "/me", `{"password": "N3w-Passw0rd!"}`},
+		{http.MethodDelete, "/me", `{}`},
+	} {
+		res := do(route[0], route[1], route[2])
+		assert.Equal(t, http.StatusForbidden, res.StatusCode, route)
+
+		var body exceptions.ErrorResponse
+		assert.NoError(t, json.NewDecoder(res.Body).Decode(&body), route)
+		assert.Equal(t, exceptions.ErrEmailNotVerified.Error(), body.Message, route)
+	}
+
+	for _, query := range fake.Queries() {
+		assert.NotContains(t, query, "UPDATE", "an unverified user must not change anything")
+		assert.NotContains(t, query, "DELETE", "an unverified user must not change anything")
+		assert.NotContains(t, query, "INSERT", "an unverified user must not change anything")
+	}
+
+	// The profile fields that BAC, limits and stats need can be set before verifying.
+	res := do(http.MethodPatch, "/me", `{"weight": 70, "sex": "female", "time_zone": "Europe/Madrid"}`)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Contains(t, strings.Join(fake.Queries(), "\n"), `UPDATE "users"`)
+}